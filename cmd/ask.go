@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/persona"
+)
+
+// NewAskAction answers a single question and prints the reply. The question
+// is taken from the arguments, or from stdin when there are none.
+func NewAskAction(manager func() *llm.Manager, personas func() *persona.Library) func(context.Context, *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		m := manager()
+		if m == nil {
+			return errors.New("no LLM provider configured, set OPENROUTER_API_KEY")
+		}
+
+		question := strings.Join(cmd.Args().Slice(), " ")
+		if question == "" || question == "-" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read question from stdin: %w", err)
+			}
+			question = string(data)
+		}
+		question = strings.TrimSpace(question)
+		if question == "" {
+			return errors.New("no question given")
+		}
+
+		return answer(ctx, cmd, m, personas(), question)
+	}
+}
+
+// answer sends prompt as the persona named by the --persona flag and prints
+// the reply. The --model flag overrides the persona's default model.
+func answer(ctx context.Context, cmd *cli.Command, m *llm.Manager, personas *persona.Library, prompt string) error {
+	name := cmd.String("persona")
+	p, ok := personas.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown persona %q", name)
+	}
+
+	opts := p.Options()
+	if model := cmd.String("model"); model != "" {
+		opts = append(opts, llm.WithModel(model))
+	}
+
+	history := p.Apply([]llm.Message{{Role: "user", Content: prompt}})
+	resp, err := m.Generate(ctx, history, opts...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(resp.Content)
+	return nil
+}
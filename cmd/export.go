@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/darling/mana/pkg/export"
+	"github.com/darling/mana/pkg/store"
+)
+
+// NewExportAction writes a stored conversation to --output, or stdout, in
+// the format given by --format or the output file's extension.
+func NewExportAction(conversations func() *store.Store) func(context.Context, *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		ref := cmd.Args().First()
+		if ref == "" {
+			return errors.New("no conversation given, use its ID or \"latest\"")
+		}
+		c, err := conversations().Find(ref)
+		if errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("no conversation matches %q", ref)
+		}
+		if err != nil {
+			return err
+		}
+
+		output := cmd.String("output")
+		name := cmd.String("format")
+		if name == "" && output != "" {
+			name = filepath.Ext(output)
+		}
+		if name == "" {
+			name = string(export.Markdown)
+		}
+		format, err := export.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			return export.Write(os.Stdout, c, format)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		if err := export.Write(f, c, format); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
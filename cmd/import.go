@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/darling/mana/pkg/importer"
+	"github.com/darling/mana/pkg/store"
+)
+
+// NewImportAction stores the conversations in each file given, detecting
+// its format unless --format names an importer. Conversations imported
+// before are only replaced by newer versions, so importing the same export
+// twice changes nothing.
+func NewImportAction(conversations func() *store.Store) func(context.Context, *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		files := cmd.Args().Slice()
+		if len(files) == 0 {
+			return errors.New("no file given, use - to read stdin")
+		}
+		format := cmd.String("format")
+		if _, ok := importer.Get(format); format != "" && !ok {
+			return fmt.Errorf("unknown import format %q, use one of %s", format, strings.Join(importer.Names(), ", "))
+		}
+
+		for _, file := range files {
+			data, err := readImport(file)
+			if err != nil {
+				return err
+			}
+			convs, name, err := importer.Read(data, format)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			summary, err := importer.Save(conversations(), convs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			fmt.Printf("%s (%s): %d imported, %d updated, %d unchanged\n",
+				file, name, summary.Imported, summary.Updated, summary.Skipped)
+		}
+		return nil
+	}
+}
+
+// readImport reads file, or stdin for "-". A zip archive, such as a ChatGPT
+// data export, is searched for conversations.json.
+func readImport(file string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return data, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	for _, f := range archive.File {
+		if path.Base(f.Name) != "conversations.json" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s has no conversations.json", file)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/persona"
+	"github.com/darling/mana/pkg/templates"
+)
+
+// NewRunAction renders a prompt template with --var values and sends it to
+// the model. Variables declared as stdin inputs are read from piped input.
+func NewRunAction(manager func() *llm.Manager, personas func() *persona.Library, tmpls func() *templates.Library) func(context.Context, *cli.Command) error {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		name := cmd.Args().First()
+		if name == "" {
+			return fmt.Errorf("no template given, available: %s", strings.Join(tmpls().Names(), ", "))
+		}
+		t, ok := tmpls().Get(name)
+		if !ok {
+			return fmt.Errorf("unknown template %q", name)
+		}
+
+		values := make(map[string]string)
+		for _, v := range cmd.StringSlice("var") {
+			key, value, ok := strings.Cut(v, "=")
+			if !ok {
+				return fmt.Errorf("invalid --var %q, expected name=value", v)
+			}
+			values[key] = value
+		}
+
+		var stdin io.Reader
+		if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice == 0 {
+			stdin = os.Stdin
+		}
+
+		resolved, err := t.Resolve(values, stdin)
+		if err != nil {
+			return err
+		}
+		prompt, err := t.Execute(resolved)
+		if err != nil {
+			return err
+		}
+
+		if cmd.Bool("dry-run") {
+			fmt.Println(prompt)
+			return nil
+		}
+
+		m := manager()
+		if m == nil {
+			return errors.New("no LLM provider configured, set OPENROUTER_API_KEY")
+		}
+		return answer(ctx, cmd, m, personas(), prompt)
+	}
+}
@@ -2,28 +2,123 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/urfave/cli/v3"
 
 	"github.com/darling/mana/cmd"
+	"github.com/darling/mana/pkg/config"
+	"github.com/darling/mana/pkg/history"
+	"github.com/darling/mana/pkg/importer"
+	_ "github.com/darling/mana/pkg/importer/chatgpt"
+	_ "github.com/darling/mana/pkg/importer/openai"
+	_ "github.com/darling/mana/pkg/importer/openrouter"
 	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/llm/providers/fake"
 	_ "github.com/darling/mana/pkg/llm/providers/openrouter"
+	"github.com/darling/mana/pkg/panes"
+	"github.com/darling/mana/pkg/persona"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/templates"
 	"github.com/darling/mana/pkg/tui"
+	"github.com/darling/mana/pkg/tui/core"
+	"github.com/darling/mana/pkg/tui/core/theme"
 	"github.com/darling/mana/pkg/version"
 )
 
 func New(buildInfo version.BuildInfo) *cli.Command {
 	var (
 		openRouterAPIKey string
+		dataDir          string
+		demo             bool
+		demoDir          string
 		llmManager       *llm.Manager
+		conversations    *store.Store
+		personas         *persona.Library
+		promptTemplates  *templates.Library
+		settings         config.Config
+		promptHistory    *history.History
+		paneLayout       *panes.Layout
 	)
 
+	// loadLibraries reads the personas and prompt templates. Only the
+	// commands talking to a model load them, so that a broken file does not
+	// stop the others.
+	loadLibraries := func(ctx context.Context, c *cli.Command) (context.Context, error) {
+		lib, err := persona.Load(config.SearchDirs("personas")...)
+		if err != nil {
+			return ctx, err
+		}
+		personas = lib
+
+		tmpls, err := templates.Load(config.SearchDirs("templates")...)
+		if err != nil {
+			return ctx, err
+		}
+		promptTemplates = tmpls
+		return ctx, nil
+	}
+
+	// loadSettings reads the configuration, the prompt history and the pane
+	// layout. Only the TUI uses them, so that a broken file does not stop
+	// the other commands.
+	loadSettings := func() error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		settings = cfg
+
+		historyPath, err := history.DefaultPath()
+		if err != nil {
+			return err
+		}
+		hist, err := history.Load(historyPath, settings.Prompt.HistorySize)
+		if err != nil {
+			return err
+		}
+		promptHistory = hist
+
+		layoutPath, err := panes.DefaultPath()
+		if err != nil {
+			return err
+		}
+		if paneLayout, err = panes.Load(layoutPath); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	return &cli.Command{
 		Name:    "mana",
 		Usage:   "The cutest LLM interface for your terminal",
 		Version: buildInfo.GetVersion(),
 		Action: func(ctx context.Context, c *cli.Command) error {
-			return tui.Run(llmManager)
+			if _, err := loadLibraries(ctx, c); err != nil {
+				return err
+			}
+			if err := loadSettings(); err != nil {
+				return err
+			}
+			keys, err := core.LoadKeyMaps(settings)
+			if err != nil {
+				return fmt.Errorf("failed to load key bindings: %w", err)
+			}
+			if _, err := theme.Load(settings, true); err != nil {
+				return err
+			}
+			return tui.Run(core.Options{
+				Manager:   llmManager,
+				Store:     conversations,
+				Personas:  personas,
+				Templates: promptTemplates,
+				Config:    &settings,
+				History:   promptHistory,
+				Keys:      &keys,
+				Layout:    paneLayout,
+			})
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -34,10 +129,39 @@ func New(buildInfo version.BuildInfo) *cli.Command {
 					cli.EnvVar("OPENROUTER_API_KEY"),
 				),
 			},
+			&cli.BoolFlag{
+				Name:        "demo",
+				Usage:       "Answer from a scripted fake provider, without an API key",
+				Destination: &demo,
+			},
+			&cli.StringFlag{
+				Name:        "data-dir",
+				Usage:       "Directory where conversations are stored",
+				Destination: &dataDir,
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("MANA_DATA_DIR"),
+				),
+			},
 		},
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
-			// Initialize LLM manager if API key is provided
-			if openRouterAPIKey != "" {
+			// The demo answers from a script; otherwise an API key enables
+			// the LLM manager
+			switch {
+			case demo:
+				manager, err := llm.NewManager("fake", llm.Config{Model: fake.DefaultModel})
+				if err != nil {
+					return ctx, err
+				}
+				llmManager = manager
+				if dataDir == "" {
+					// Demo conversations stay out of the real ones
+					dir, err := os.MkdirTemp("", "mana-demo-")
+					if err != nil {
+						return ctx, fmt.Errorf("failed to create demo directory: %w", err)
+					}
+					dataDir, demoDir = dir, dir
+				}
+			case openRouterAPIKey != "":
 				manager, err := llm.NewManager("openrouter", llm.Config{
 					APIKey: openRouterAPIKey,
 					Model:  "qwen/qwen3-coder:nitro",
@@ -48,9 +172,25 @@ func New(buildInfo version.BuildInfo) *cli.Command {
 				llmManager = manager
 			}
 
+			if dataDir == "" {
+				dir, err := store.DefaultDir()
+				if err != nil {
+					return ctx, err
+				}
+				dataDir = dir
+			}
+			st, err := store.Open(dataDir)
+			if err != nil {
+				return ctx, err
+			}
+			conversations = st
+
 			return ctx, nil
 		},
 		After: func(ctx context.Context, c *cli.Command) error {
+			if demoDir != "" {
+				_ = os.RemoveAll(demoDir)
+			}
 			if llmManager != nil {
 				return llmManager.Close()
 			}
@@ -63,6 +203,92 @@ func New(buildInfo version.BuildInfo) *cli.Command {
 				Usage:   "Show the current version",
 				Action:  cmd.NewVersionAction(buildInfo),
 			},
+			{
+				Name:      "ask",
+				Aliases:   []string{"a"},
+				Usage:     "Ask a single question and print the answer",
+				ArgsUsage: "[question]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "persona",
+						Usage: "Persona to answer as",
+						Value: persona.DefaultName,
+					},
+					&cli.StringFlag{
+						Name:  "model",
+						Usage: "Model to use, overriding the persona default",
+					},
+				},
+				Before: loadLibraries,
+				Action: cmd.NewAskAction(
+					func() *llm.Manager { return llmManager },
+					func() *persona.Library { return personas },
+				),
+			},
+			{
+				Name:      "run",
+				Aliases:   []string{"r"},
+				Usage:     "Render a prompt template and send it to the model",
+				ArgsUsage: "<template>",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "var",
+						Usage: "Template variable as name=value, may be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "persona",
+						Usage: "Persona to answer as",
+						Value: persona.DefaultName,
+					},
+					&cli.StringFlag{
+						Name:  "model",
+						Usage: "Model to use, overriding the persona default",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Print the rendered prompt instead of sending it",
+					},
+				},
+				Before: loadLibraries,
+				Action: cmd.NewRunAction(
+					func() *llm.Manager { return llmManager },
+					func() *persona.Library { return personas },
+					func() *templates.Library { return promptTemplates },
+				),
+			},
+			{
+				Name:      "export",
+				Aliases:   []string{"e"},
+				Usage:     "Export a stored conversation as Markdown, HTML or JSON",
+				ArgsUsage: "<conversation>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Output format: md, html, json or jsonl (default: from --output, else md)",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "File to write instead of stdout",
+					},
+				},
+				Action: cmd.NewExportAction(func() *store.Store { return conversations }),
+			},
+			{
+				Name:      "import",
+				Aliases:   []string{"i"},
+				Usage:     "Import conversations exported from mana, ChatGPT, OpenRouter or as OpenAI messages",
+				ArgsUsage: "<file>...",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Importer to use: " + strings.Join(importer.Names(), ", ") + " (default: detected)",
+					},
+				},
+				Action: cmd.NewImportAction(func() *store.Store { return conversations }),
+			},
 		},
 	}
 }
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectDirName is the name of the per-project configuration directory,
+// looked up relative to the working directory.
+const ProjectDirName = ".mana"
+
+// Dir returns the user configuration directory for mana. It honours
+// MANA_CONFIG_DIR and otherwise lives under the OS user config directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("MANA_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "mana"), nil
+}
+
+// SearchDirs returns the directories a named kind of resource (for example
+// "personas") is loaded from, in increasing order of precedence: the user
+// config directory first, then the project directory.
+func SearchDirs(name string) []string {
+	var dirs []string
+	if dir, err := Dir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, name))
+	}
+	return append(dirs, filepath.Join(ProjectDirName, name))
+}
+
+// FileName is the name of the configuration file inside Dir.
+const FileName = "config.json"
+
+// Config is the user configuration. Every field is optional; missing values
+// fall back to Default.
+type Config struct {
+	Prompt  PromptConfig  `json:"prompt"`
+	Titles  TitleConfig   `json:"titles"`
+	Compact CompactConfig `json:"compact"`
+	Keys    KeysConfig    `json:"keys"`
+
+	// Theme names the built-in or user theme the TUI is drawn with. The
+	// default, "auto", picks dark or light after the terminal background.
+	Theme  string                 `json:"theme,omitempty"`
+	Themes map[string]ThemeConfig `json:"themes,omitempty"`
+}
+
+// PromptConfig configures the prompt editor.
+type PromptConfig struct {
+	SubmitKeys  []string `json:"submit_keys,omitempty"`
+	NewlineKeys []string `json:"newline_keys,omitempty"`
+	EditorKeys  []string `json:"editor_keys,omitempty"`
+	HistorySize int      `json:"history_size,omitempty"`
+
+	// Inline docks the prompt at the bottom of the main view instead of
+	// opening it as a dialog. It grows with its content up to InlineMaxLines.
+	Inline         bool `json:"inline,omitempty"`
+	InlineMaxLines int  `json:"inline_max_lines,omitempty"`
+}
+
+// TitleConfig configures naming conversations automatically after their
+// first exchange.
+type TitleConfig struct {
+	// Model writes the titles and tags. A small, cheap model of the
+	// provider is plenty; empty uses the provider's configured model.
+	Model    string `json:"model,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// CompactConfig configures summarizing long conversations automatically.
+type CompactConfig struct {
+	// Threshold is the estimated token count above which a conversation is
+	// compacted after a response. Zero, the default, leaves compacting to
+	// the /compact command; pick a value well below the model's context.
+	Threshold int `json:"threshold,omitempty"`
+}
+
+// KeysConfig configures the key bindings of the TUI.
+type KeysConfig struct {
+	// Preset is the keymap the bindings start from: "default" or "vim".
+	Preset string `json:"preset,omitempty"`
+	// Bindings rebinds single actions, named "scope.action" as in
+	// "main.compact". An empty list unbinds the action.
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// ThemeConfig defines a user theme.
+type ThemeConfig struct {
+	// Base is the theme unset colors come from; "auto" by default.
+	Base string `json:"base,omitempty"`
+	// Markdown is the glamour style messages are rendered with, like
+	// "dracula"; by default that of Base.
+	Markdown string `json:"markdown,omitempty"`
+	// Colors are ANSI color numbers or hex colors by name, like "accent".
+	Colors map[string]string `json:"colors,omitempty"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Prompt: PromptConfig{
+			SubmitKeys:     []string{"enter"},
+			NewlineKeys:    []string{"alt+enter", "ctrl+j"},
+			EditorKeys:     []string{"ctrl+g"},
+			HistorySize:    500,
+			InlineMaxLines: 8,
+		},
+	}
+}
+
+// Load reads the configuration file from Dir. A missing file is not an error.
+func Load() (Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Default(), err
+	}
+	return LoadFile(filepath.Join(dir, FileName))
+}
+
+// LoadFile reads the configuration from path, filling unset values from Default.
+func LoadFile(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var file Config
+	if err := json.Unmarshal(data, &file); err != nil {
+		return cfg, fmt.Errorf("failed to decode config %s: %w", path, err)
+	}
+	cfg.merge(file)
+	return cfg, nil
+}
+
+// merge overrides c with every value set in other.
+func (c *Config) merge(other Config) {
+	if len(other.Prompt.SubmitKeys) > 0 {
+		c.Prompt.SubmitKeys = other.Prompt.SubmitKeys
+	}
+	if len(other.Prompt.NewlineKeys) > 0 {
+		c.Prompt.NewlineKeys = other.Prompt.NewlineKeys
+	}
+	if len(other.Prompt.EditorKeys) > 0 {
+		c.Prompt.EditorKeys = other.Prompt.EditorKeys
+	}
+	if other.Prompt.HistorySize > 0 {
+		c.Prompt.HistorySize = other.Prompt.HistorySize
+	}
+	if other.Prompt.Inline {
+		c.Prompt.Inline = true
+	}
+	if other.Prompt.InlineMaxLines > 0 {
+		c.Prompt.InlineMaxLines = other.Prompt.InlineMaxLines
+	}
+	if other.Titles.Model != "" {
+		c.Titles.Model = other.Titles.Model
+	}
+	if other.Titles.Disabled {
+		c.Titles.Disabled = true
+	}
+	if other.Compact.Threshold > 0 {
+		c.Compact.Threshold = other.Compact.Threshold
+	}
+	if other.Keys.Preset != "" {
+		c.Keys.Preset = other.Keys.Preset
+	}
+	if len(other.Keys.Bindings) > 0 && c.Keys.Bindings == nil {
+		c.Keys.Bindings = make(map[string][]string, len(other.Keys.Bindings))
+	}
+	for name, keys := range other.Keys.Bindings {
+		c.Keys.Bindings[name] = keys
+	}
+	if other.Theme != "" {
+		c.Theme = other.Theme
+	}
+	if len(other.Themes) > 0 && c.Themes == nil {
+		c.Themes = make(map[string]ThemeConfig, len(other.Themes))
+	}
+	for name, t := range other.Themes {
+		c.Themes[name] = t
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := `{"prompt": {"submit_keys": ["ctrl+enter"], "newline_keys": ["enter"], "inline": true}, "titles": {"model": "cheap/model"}, "compact": {"threshold": 16000}, "keys": {"preset": "vim", "bindings": {"main.compact": ["X"], "global.quit": []}}, "theme": "ocean", "themes": {"ocean": {"base": "light", "colors": {"accent": "#268bd2"}}}}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if want := []string{"ctrl+enter"}; !reflect.DeepEqual(cfg.Prompt.SubmitKeys, want) {
+		t.Errorf("SubmitKeys = %v, want %v", cfg.Prompt.SubmitKeys, want)
+	}
+	if want := []string{"enter"}; !reflect.DeepEqual(cfg.Prompt.NewlineKeys, want) {
+		t.Errorf("NewlineKeys = %v, want %v", cfg.Prompt.NewlineKeys, want)
+	}
+	if !cfg.Prompt.Inline {
+		t.Error("Inline = false, want true")
+	}
+	if cfg.Compact.Threshold != 16000 {
+		t.Errorf("Compact.Threshold = %d, want 16000", cfg.Compact.Threshold)
+	}
+	if cfg.Titles.Model != "cheap/model" || cfg.Titles.Disabled {
+		t.Errorf("Titles = %+v, want cheap/model enabled", cfg.Titles)
+	}
+	if cfg.Keys.Preset != "vim" {
+		t.Errorf("Keys.Preset = %q, want vim", cfg.Keys.Preset)
+	}
+	if want := map[string][]string{"main.compact": {"X"}, "global.quit": {}}; !reflect.DeepEqual(cfg.Keys.Bindings, want) {
+		t.Errorf("Keys.Bindings = %v, want %v", cfg.Keys.Bindings, want)
+	}
+	if ocean := cfg.Themes["ocean"]; cfg.Theme != "ocean" || ocean.Base != "light" || ocean.Colors["accent"] != "#268bd2" {
+		t.Errorf("Theme = %q, Themes = %+v, want the ocean theme", cfg.Theme, cfg.Themes)
+	}
+	if cfg.Prompt.HistorySize != Default().Prompt.HistorySize {
+		t.Errorf("HistorySize = %d, want default", cfg.Prompt.HistorySize)
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("LoadFile() = %+v, want defaults", cfg)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("LoadFile() error = nil, want decode error")
+	}
+}
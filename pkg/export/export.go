@@ -0,0 +1,315 @@
+// Package export writes conversations as Markdown, HTML or JSON, and reads
+// the JSON formats back.
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	Markdown Format = "md"
+	HTML     Format = "html"
+	JSON     Format = "json"
+	JSONL    Format = "jsonl"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{Markdown, HTML, JSON, JSONL}
+
+// ParseFormat returns the format named s, accepting "markdown" for "md".
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimPrefix(s, "."))
+	if s == "markdown" {
+		return Markdown, nil
+	}
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q, use md, html, json or jsonl", s)
+}
+
+// SchemaVersion is the version of the JSON export schema. Readers accept
+// documents up to this version.
+const SchemaVersion = 1
+
+// Document is a conversation in the JSON export schema. Unlike Markdown and
+// HTML, which show the current branch only, it keeps every branch.
+type Document struct {
+	Version    int           `json:"version"`
+	ID         string        `json:"id"`
+	Title      string        `json:"title,omitempty"`
+	Persona    string        `json:"persona,omitempty"`
+	System     string        `json:"system,omitempty"`
+	Model      string        `json:"model,omitempty"`
+	Tags       []string      `json:"tags,omitempty"`
+	Pinned     bool          `json:"pinned,omitempty"`
+	ArchivedAt time.Time     `json:"archived_at,omitzero"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	Leaf       string        `json:"leaf,omitempty"`
+	Messages   []llm.Message `json:"messages"`
+	Archived   []llm.Message `json:"archived,omitempty"`
+}
+
+// NewDocument converts a stored conversation to the export schema.
+func NewDocument(c store.Conversation) Document {
+	tree := llm.NewTree(c.Messages, c.Leaf)
+	return Document{
+		Version:    SchemaVersion,
+		ID:         c.ID,
+		Title:      c.Title,
+		Persona:    c.Persona,
+		System:     c.System,
+		Model:      c.Model,
+		Tags:       c.Tags,
+		Pinned:     c.Pinned,
+		ArchivedAt: c.ArchivedAt,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+		Leaf:       tree.Leaf,
+		Messages:   tree.Nodes,
+		Archived:   c.Archived,
+	}
+}
+
+// Conversation converts the document back to a stored conversation.
+func (d Document) Conversation() store.Conversation {
+	return store.Conversation{
+		ID:         d.ID,
+		Title:      d.Title,
+		Persona:    d.Persona,
+		System:     d.System,
+		Model:      d.Model,
+		Tags:       d.Tags,
+		Pinned:     d.Pinned,
+		ArchivedAt: d.ArchivedAt,
+		Messages:   d.Messages,
+		Leaf:       d.Leaf,
+		Archived:   d.Archived,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+// Extension returns the file extension for the format, including the dot.
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Write exports the conversation to w.
+func Write(w io.Writer, c store.Conversation, format Format) error {
+	switch format {
+	case Markdown:
+		return writeMarkdown(w, c)
+	case HTML:
+		return writeHTML(w, c)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(NewDocument(c)); err != nil {
+			return fmt.Errorf("failed to encode conversation: %w", err)
+		}
+		return nil
+	case JSONL:
+		return writeJSONL(w, NewDocument(c))
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+// A JSONL export has one record per line: the conversation without its
+// messages first, then one line per message. Type tells them apart.
+type (
+	conversationRecord struct {
+		Type string `json:"type"` // "conversation"
+		Document
+	}
+	messageRecord struct {
+		Type string `json:"type"` // "message" or "archived"
+		llm.Message
+	}
+)
+
+func writeJSONL(w io.Writer, d Document) error {
+	enc := json.NewEncoder(w)
+	header := d
+	header.Messages, header.Archived = []llm.Message{}, nil
+	if err := enc.Encode(conversationRecord{Type: "conversation", Document: header}); err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	for _, msg := range d.Messages {
+		if err := enc.Encode(messageRecord{Type: "message", Message: msg}); err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+	}
+	for _, msg := range d.Archived {
+		if err := enc.Encode(messageRecord{Type: "archived", Message: msg}); err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Read decodes conversations written in the JSON or JSONL format.
+func Read(r io.Reader) ([]Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("export is empty")
+	}
+
+	// A single document, or a list of them
+	switch trimmed[0] {
+	case '[':
+		var docs []Document
+		if err := json.Unmarshal(trimmed, &docs); err != nil {
+			return nil, fmt.Errorf("failed to decode export: %w", err)
+		}
+		return checkVersions(docs)
+	case '{':
+		// JSONL with more than one line is not valid JSON as a whole
+		if json.Valid(trimmed) {
+			var doc Document
+			if err := json.Unmarshal(trimmed, &doc); err != nil {
+				return nil, fmt.Errorf("failed to decode export: %w", err)
+			}
+			return checkVersions([]Document{doc})
+		}
+	}
+	return readJSONL(trimmed)
+}
+
+func readJSONL(data []byte) ([]Document, error) {
+	var docs []Document
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var kind struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(text, &kind); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d: %w", line, err)
+		}
+		if kind.Type == "conversation" {
+			var doc Document
+			if err := json.Unmarshal(text, &doc); err != nil {
+				return nil, fmt.Errorf("failed to decode line %d: %w", line, err)
+			}
+			docs = append(docs, doc)
+			continue
+		}
+		if len(docs) == 0 {
+			return nil, fmt.Errorf("line %d: message before any conversation", line)
+		}
+		var msg llm.Message
+		if err := json.Unmarshal(text, &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d: %w", line, err)
+		}
+		doc := &docs[len(docs)-1]
+		switch kind.Type {
+		case "message":
+			doc.Messages = append(doc.Messages, msg)
+		case "archived":
+			doc.Archived = append(doc.Archived, msg)
+		default:
+			return nil, fmt.Errorf("line %d: unknown record type %q", line, kind.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	return checkVersions(docs)
+}
+
+func checkVersions(docs []Document) ([]Document, error) {
+	for _, d := range docs {
+		if d.Version < 1 || d.Version > SchemaVersion {
+			return nil, fmt.Errorf("unsupported export version %d", d.Version)
+		}
+	}
+	return docs, nil
+}
+
+// title returns the conversation's title, or a fallback for untitled ones.
+func title(c store.Conversation) string {
+	if c.Title != "" {
+		return c.Title
+	}
+	return "Conversation " + c.CreatedAt.Format("2006-01-02 15:04")
+}
+
+// heading describes a message: its role, model and time.
+func heading(msg llm.Message) string {
+	role := msg.Role
+	switch {
+	case msg.Summarizes > 0:
+		role = "summary"
+	case role == "":
+		role = "assistant"
+	}
+	parts := []string{strings.ToUpper(role[:1]) + role[1:]}
+	if msg.Model != "" {
+		parts = append(parts, msg.Model)
+	}
+	if !msg.CreatedAt.IsZero() {
+		parts = append(parts, msg.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// details describes the conversation below its title.
+func details(c store.Conversation) string {
+	var parts []string
+	if c.Persona != "" {
+		parts = append(parts, "Persona: "+c.Persona)
+	}
+	if c.Model != "" {
+		parts = append(parts, "Model: "+c.Model)
+	}
+	if len(c.Tags) > 0 {
+		parts = append(parts, "Tags: "+strings.Join(c.Tags, ", "))
+	}
+	parts = append(parts, "Created: "+c.CreatedAt.Format("2006-01-02 15:04"))
+	if !c.UpdatedAt.Equal(c.CreatedAt) {
+		parts = append(parts, "Updated: "+c.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// branch returns the messages of the branch shown in the conversation.
+func branch(c store.Conversation) []llm.Message {
+	return llm.NewTree(c.Messages, c.Leaf).Path()
+}
+
+func writeMarkdown(w io.Writer, c store.Conversation) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n_%s_\n", title(c), details(c))
+	for _, msg := range branch(c) {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", heading(msg), strings.TrimSpace(msg.Content))
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+func testConversation() store.Conversation {
+	at := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	var tree llm.Tree
+	q := tree.Add(llm.Message{Role: "user", Content: "Show me a loop", CreatedAt: at})
+	tree.Add(llm.Message{Role: "assistant", Content: "old answer", CreatedAt: at})
+	tree.SetLeaf(q.ID)
+	tree.Add(llm.Message{Role: "assistant", Model: "test/model", Content: "Here:\n\n```go\nfor i := 0; i < 3; i++ {}\n```\n", CreatedAt: at.Add(time.Minute)})
+
+	return store.Conversation{
+		ID:        "c1",
+		Title:     "Loops <3",
+		Persona:   "coder",
+		Messages:  tree.Nodes,
+		Leaf:      tree.Leaf,
+		CreatedAt: at,
+		UpdatedAt: at.Add(time.Minute),
+	}
+}
+
+func TestWrite_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testConversation(), Markdown); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := "# Loops <3\n\n_Persona: coder · Created: 2025-03-01 09:30 · Updated: 2025-03-01 09:31_\n" +
+		"\n## User · 2025-03-01 09:30\n\nShow me a loop\n" +
+		"\n## Assistant · test/model · 2025-03-01 09:31\n\nHere:\n\n```go\nfor i := 0; i < 3; i++ {}\n```\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWrite_HTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testConversation(), HTML); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>Loops &lt;3</title>",
+		"Assistant · test/model · 2025-03-01 09:31",
+		`<span style="`, // highlighted inline, no external stylesheet
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML export does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "old answer") {
+		t.Error("HTML export contains a branch that is not shown")
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	for _, format := range []Format{JSON, JSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			c := testConversation()
+			var buf bytes.Buffer
+			if err := Write(&buf, c, format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			docs, err := Read(&buf)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(docs) != 1 {
+				t.Fatalf("Read() = %d documents, want 1", len(docs))
+			}
+			got := docs[0].Conversation()
+			if !reflect.DeepEqual(got, c) {
+				t.Errorf("round trip =\n%+v\nwant\n%+v", got, c)
+			}
+		})
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", "  "},
+		{"future version", `{"version": 99, "id": "x", "messages": []}`},
+		{"message first", `{"type": "message", "id": "m"}` + "\n" + `{"type": "conversation", "version": 1}`},
+		{"not json", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(strings.NewReader(tt.input)); err == nil {
+				t.Error("Read() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"md": Markdown, "markdown": Markdown, "HTML": HTML, ".jsonl": JSONL} {
+		if got, err := ParseFormat(input); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("ParseFormat(pdf) error = nil, want error")
+	}
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/darling/mana/pkg/store"
+)
+
+// codeStyle is the chroma style code blocks are highlighted with.
+const codeStyle = "github"
+
+// htmlMessage is a message prepared for the HTML template.
+type htmlMessage struct {
+	Role    string
+	Heading string
+	Body    template.HTML
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; color: #1f2328; max-width: 52rem; margin: 2rem auto; padding: 0 1rem; }
+header p { color: #656d76; }
+section { border: 1px solid #d0d7de; border-radius: 6px; margin: 1rem 0; padding: 0 1rem; }
+section.user { background: #f6f8fa; }
+section h2 { font-size: 0.9rem; color: #656d76; margin: 0.75rem 0 0; }
+pre { padding: 0.75rem; border-radius: 6px; overflow-x: auto; background: #f6f8fa; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0.25rem 0.5rem; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{.Details}}</p>
+</header>
+{{range .Messages}}<section class="{{.Role}}">
+<h2>{{.Heading}}</h2>
+{{.Body}}
+</section>
+{{end}}</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, c store.Conversation) error {
+	md := newMarkdown()
+	var messages []htmlMessage
+	for _, msg := range branch(c) {
+		var body bytes.Buffer
+		if err := md.Convert([]byte(msg.Content), &body); err != nil {
+			return fmt.Errorf("failed to render message: %w", err)
+		}
+		messages = append(messages, htmlMessage{
+			Role:    msg.Role,
+			Heading: heading(msg),
+			// goldmark escapes text and omits raw HTML, so the output is safe
+			Body: template.HTML(body.String()),
+		})
+	}
+
+	err := pageTemplate.Execute(w, struct {
+		Title    string
+		Details  string
+		Messages []htmlMessage
+	}{title(c), details(c), messages})
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// newMarkdown returns a converter that highlights fenced code with inline
+// styles, so the page needs no external stylesheet.
+func newMarkdown() goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(codeRenderer{}, 100)),
+		),
+	)
+}
+
+// codeRenderer renders fenced code blocks with chroma.
+type codeRenderer struct{}
+
+func (codeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderCode)
+}
+
+func renderCode(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+	var code strings.Builder
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		code.Write(line.Value(source))
+	}
+
+	var lexer chroma.Lexer
+	if lang := n.Language(source); lang != nil {
+		lexer = lexers.Get(string(lang))
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(code.String())
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	formatter := chromahtml.New(chromahtml.WithClasses(false))
+	if err := formatter.Format(w, styles.Get(codeStyle), iterator); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/darling/mana/pkg/config"
+)
+
+// FileName is the name of the history file inside the config directory.
+const FileName = "history.json"
+
+// History keeps previously submitted prompts and the unsent draft.
+type History struct {
+	path string
+	max  int
+
+	mu      sync.Mutex
+	prompts []string
+	draft   string
+}
+
+type file struct {
+	Prompts []string `json:"prompts"`
+	Draft   string   `json:"draft,omitempty"`
+}
+
+// DefaultPath returns the history file location in the config directory.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the history at path, keeping at most max prompts. A missing
+// or undecodable file yields an empty history, which replaces it on the
+// next Save. An empty path keeps history in memory only.
+func Load(path string, max int) (*History, error) {
+	h := &History{path: path, max: max}
+	if path == "" {
+		return h, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		// Losing the recalled prompts is better than not starting
+		return h, nil
+	}
+	h.prompts = f.Prompts
+	h.draft = f.Draft
+	h.trim()
+	return h, nil
+}
+
+// Prompts returns the stored prompts, oldest first.
+func (h *History) Prompts() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.prompts...)
+}
+
+// Add records a submitted prompt and clears the draft. Repeating the most
+// recent prompt does not add a duplicate entry.
+func (h *History) Add(prompt string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draft = ""
+	if prompt == "" || len(h.prompts) > 0 && h.prompts[len(h.prompts)-1] == prompt {
+		return
+	}
+	h.prompts = append(h.prompts, prompt)
+	h.trim()
+}
+
+// Draft returns the prompt that was being written when the editor was closed.
+func (h *History) Draft() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.draft
+}
+
+// SetDraft remembers an unsent prompt.
+func (h *History) SetDraft(draft string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draft = draft
+}
+
+// Save writes the history to disk.
+func (h *History) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(file{Prompts: h.prompts, Draft: h.draft})
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	// Write to a temporary file first so a crash never leaves a truncated history.
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+func (h *History) trim() {
+	if h.max > 0 && len(h.prompts) > h.max {
+		h.prompts = append([]string(nil), h.prompts[len(h.prompts)-h.max:]...)
+	}
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistory_AddSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	h, err := Load(path, 2)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	h.SetDraft("half written")
+	h.Add("one")
+	h.Add("two")
+	h.Add("two")
+	h.Add("three")
+	h.SetDraft("next")
+
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path, 2)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := loaded.Prompts(), []string{"two", "three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Prompts() = %v, want %v", got, want)
+	}
+	if got := loaded.Draft(); got != "next" {
+		t.Errorf("Draft() = %q, want %q", got, "next")
+	}
+}
+
+func TestHistory_AddClearsDraft(t *testing.T) {
+	h, _ := Load("", 10)
+	h.SetDraft("draft")
+	h.Add("sent")
+	if h.Draft() != "" {
+		t.Errorf("Draft() = %q after Add, want empty", h.Draft())
+	}
+}
+
+func TestHistory_LoadUndecodable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := Load(path, 10)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want an empty history", err)
+	}
+	if len(h.Prompts()) != 0 || h.Draft() != "" {
+		t.Errorf("Load() = %v %q, want an empty history", h.Prompts(), h.Draft())
+	}
+
+	h.Add("one")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Save() left the temporary file behind: %v", err)
+	}
+	loaded, err := Load(path, 10)
+	if err != nil || !reflect.DeepEqual(loaded.Prompts(), []string{"one"}) {
+		t.Errorf("Load() after Save = %v, %v; want [one]", loaded.Prompts(), err)
+	}
+}
@@ -0,0 +1,202 @@
+// Package chatgpt imports the conversations.json file from a ChatGPT data
+// export.
+package chatgpt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darling/mana/pkg/importer"
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+func init() {
+	importer.Register("chatgpt", Importer{})
+}
+
+// conversation is one entry of conversations.json. Every message is a node
+// of mapping; edited prompts and regenerated answers are sibling nodes, and
+// current_node is the leaf of the branch shown.
+type conversation struct {
+	ID             string          `json:"id"`
+	ConversationID string          `json:"conversation_id"`
+	Title          string          `json:"title"`
+	CreateTime     float64         `json:"create_time"`
+	UpdateTime     float64         `json:"update_time"`
+	CurrentNode    string          `json:"current_node"`
+	Mapping        map[string]node `json:"mapping"`
+}
+
+type node struct {
+	ID       string   `json:"id"`
+	Message  *message `json:"message"`
+	Parent   string   `json:"parent"`
+	Children []string `json:"children"`
+}
+
+type message struct {
+	ID     string `json:"id"`
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+		Text        string            `json:"text"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug        string `json:"model_slug"`
+		IsVisuallyHidden bool   `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+// Importer reads ChatGPT exports.
+type Importer struct{}
+
+func (Importer) Detect(data []byte) bool {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return false
+	}
+	var probe []struct {
+		Mapping     json.RawMessage `json:"mapping"`
+		CurrentNode string          `json:"current_node"`
+	}
+	return json.Unmarshal(data, &probe) == nil && len(probe) > 0 && probe[0].Mapping != nil
+}
+
+func (Importer) Import(data []byte) ([]store.Conversation, error) {
+	var convs []conversation
+	if err := json.Unmarshal(data, &convs); err != nil {
+		return nil, fmt.Errorf("failed to decode conversations: %w", err)
+	}
+	out := make([]store.Conversation, 0, len(convs))
+	for _, c := range convs {
+		out = append(out, convert(c))
+	}
+	return out, nil
+}
+
+func convert(c conversation) store.Conversation {
+	key := c.ConversationID
+	if key == "" {
+		key = c.ID
+	}
+	if key == "" {
+		// Old exports have no ID; the title and start time identify them
+		key = fmt.Sprintf("%s@%f", c.Title, c.CreateTime)
+	}
+	conv := store.Conversation{
+		ID:        importer.StableID("chatgpt", key),
+		Title:     c.Title,
+		CreatedAt: timestamp(c.CreateTime),
+		UpdatedAt: timestamp(c.UpdateTime),
+	}
+
+	// Walk the tree from its roots, dropping system, tool and hidden nodes.
+	// Their children hang off the nearest message that was kept.
+	var roots []string
+	for id, n := range c.Mapping {
+		if _, ok := c.Mapping[n.Parent]; n.Parent == "" || !ok {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+	kept := make(map[string]string) // node ID to the ID of the kept message standing in for it
+	var walk func(id, parent string)
+	walk = func(id, parent string) {
+		n := c.Mapping[id]
+		if msg, ok := convertMessage(n, parent); ok {
+			conv.Messages = append(conv.Messages, msg)
+			parent = msg.ID
+		}
+		kept[id] = parent
+		for _, child := range n.Children {
+			if _, ok := c.Mapping[child]; ok {
+				walk(child, parent)
+			}
+		}
+	}
+	for _, id := range roots {
+		walk(id, "")
+	}
+
+	conv.Leaf = kept[c.CurrentNode]
+	if conv.Leaf == "" && len(conv.Messages) > 0 {
+		conv.Leaf = conv.Messages[len(conv.Messages)-1].ID
+	}
+	if conv.CreatedAt.IsZero() && len(conv.Messages) > 0 {
+		conv.CreatedAt = conv.Messages[0].CreatedAt
+	}
+	if conv.UpdatedAt.IsZero() {
+		conv.UpdatedAt = conv.CreatedAt
+	}
+	return conv
+}
+
+// convertMessage returns the node as a mana message, or false when it is not
+// part of the visible conversation.
+func convertMessage(n node, parent string) (llm.Message, bool) {
+	m := n.Message
+	if m == nil || m.Metadata.IsVisuallyHidden {
+		return llm.Message{}, false
+	}
+	role := m.Author.Role
+	if role != "user" && role != "assistant" {
+		return llm.Message{}, false
+	}
+	content := strings.TrimSpace(text(m))
+	if content == "" {
+		return llm.Message{}, false
+	}
+	id := m.ID
+	if id == "" {
+		id = n.ID
+	}
+	msg := llm.Message{
+		ID:        id,
+		Role:      role,
+		Content:   content,
+		ParentID:  parent,
+		CreatedAt: timestamp(m.CreateTime),
+	}
+	if role == "assistant" {
+		msg.Provider = "chatgpt"
+		msg.Model = m.Metadata.ModelSlug
+	}
+	return msg, true
+}
+
+// text joins the text parts of a message. Images and other attachments are
+// objects among the parts and are left out.
+func text(m *message) string {
+	switch m.Content.ContentType {
+	case "text", "multimodal_text":
+		var parts []string
+		for _, raw := range m.Content.Parts {
+			var s string
+			if json.Unmarshal(raw, &s) == nil && s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	case "code":
+		return "```\n" + m.Content.Text + "\n```"
+	}
+	return ""
+}
+
+// timestamp converts seconds since the epoch, with a fraction, to a time.
+func timestamp(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC().Truncate(time.Millisecond)
+}
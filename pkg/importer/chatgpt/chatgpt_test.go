@@ -0,0 +1,76 @@
+package chatgpt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/importer"
+)
+
+const export = `[{
+  "title": "Go generics",
+  "create_time": 1700000000.5,
+  "update_time": 1700000300.25,
+  "conversation_id": "c-1",
+  "current_node": "a2",
+  "mapping": {
+    "root": {"id": "root", "message": null, "parent": null, "children": ["sys"]},
+    "sys": {"id": "sys", "message": {"id": "sys", "author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}, "metadata": {"is_visually_hidden_from_conversation": true}}, "parent": "root", "children": ["u1"]},
+    "u1": {"id": "u1", "message": {"id": "u1", "author": {"role": "user"}, "create_time": 1700000010, "content": {"content_type": "text", "parts": ["What are generics?"]}, "metadata": {}}, "parent": "sys", "children": ["a1", "a2"]},
+    "a1": {"id": "a1", "message": {"id": "a1", "author": {"role": "assistant"}, "create_time": 1700000020, "content": {"content_type": "text", "parts": ["First answer"]}, "metadata": {"model_slug": "gpt-4"}}, "parent": "u1", "children": []},
+    "a2": {"id": "a2", "message": {"id": "a2", "author": {"role": "assistant"}, "create_time": 1700000030, "content": {"content_type": "multimodal_text", "parts": [{"asset_pointer": "file-1"}, "Second answer"]}, "metadata": {"model_slug": "gpt-4o"}}, "parent": "u1", "children": []}
+  }
+}]`
+
+func TestImport(t *testing.T) {
+	i := Importer{}
+	if !i.Detect([]byte(export)) {
+		t.Fatal("Detect() = false for a ChatGPT export")
+	}
+	if i.Detect([]byte(`[{"role": "user", "content": "hi"}]`)) {
+		t.Error("Detect() = true for a message list")
+	}
+
+	convs, err := i.Import([]byte(export))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(convs) != 1 {
+		t.Fatalf("Import() returned %d conversations, want 1", len(convs))
+	}
+	c := convs[0]
+	if c.ID != importer.StableID("chatgpt", "c-1") {
+		t.Errorf("ID = %q, want the stable ID of c-1", c.ID)
+	}
+	if c.Title != "Go generics" {
+		t.Errorf("Title = %q", c.Title)
+	}
+	if want := time.Unix(1700000000, 5e8).UTC(); !c.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", c.CreatedAt, want)
+	}
+	if c.Leaf != "a2" {
+		t.Errorf("Leaf = %q, want a2", c.Leaf)
+	}
+
+	tests := []struct {
+		id, role, parent, content, model string
+	}{
+		{"u1", "user", "", "What are generics?", ""},
+		{"a1", "assistant", "u1", "First answer", "gpt-4"},
+		{"a2", "assistant", "u1", "Second answer", "gpt-4o"},
+	}
+	if len(c.Messages) != len(tests) {
+		t.Fatalf("got %d messages, want %d: %+v", len(c.Messages), len(tests), c.Messages)
+	}
+	for n, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got := c.Messages[n]
+			if got.ID != tt.id || got.Role != tt.role || got.ParentID != tt.parent || got.Content != tt.content || got.Model != tt.model {
+				t.Errorf("message = %+v, want %+v", got, tt)
+			}
+			if got.CreatedAt.IsZero() {
+				t.Error("CreatedAt is zero")
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package importer reads conversations exported by other tools. Importers
+// register themselves by name; see the subpackages.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/darling/mana/pkg/store"
+)
+
+// Importer converts another tool's export into conversations.
+type Importer interface {
+	// Detect reports whether data looks like this importer's format.
+	Detect(data []byte) bool
+	// Import decodes every conversation in data. Conversations must get the
+	// same ID every time the same export is imported; see StableID.
+	Import(data []byte) ([]store.Conversation, error)
+}
+
+var (
+	registry   = make(map[string]Importer)
+	registryMu sync.RWMutex
+)
+
+// Register makes an importer available by name. It panics if the name is
+// already taken.
+func Register(name string, i Importer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("importer %q already registered", name))
+	}
+	registry[name] = i
+}
+
+// Names returns the registered importers in alphabetical order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the importer registered under name.
+func Get(name string) (Importer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	i, ok := registry[name]
+	return i, ok
+}
+
+// Detect returns the name of the first importer, alphabetically, that
+// recognizes data.
+func Detect(data []byte) (string, error) {
+	for _, name := range Names() {
+		if i, _ := Get(name); i.Detect(data) {
+			return name, nil
+		}
+	}
+	return "", errors.New("unrecognized export format")
+}
+
+// Read decodes data with the named importer, or detects the format when
+// name is empty. It returns the name of the importer used.
+func Read(data []byte, name string) ([]store.Conversation, string, error) {
+	if name == "" {
+		detected, err := Detect(data)
+		if err != nil {
+			return nil, "", err
+		}
+		name = detected
+	}
+	i, ok := Get(name)
+	if !ok {
+		return nil, "", fmt.Errorf("unknown import format %q", name)
+	}
+	conversations, err := i.Import(data)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to import %s export: %w", name, err)
+	}
+	return conversations, name, nil
+}
+
+// namespace scopes the IDs derived by StableID.
+var namespace = uuid.MustParse("6f1c5b0e-3a47-4a8e-9d2b-6c1e0f9a7d31")
+
+// StableID derives a conversation ID from the source it was imported from
+// and that source's own identifier, so importing again finds the same
+// conversation.
+func StableID(source, key string) string {
+	return uuid.NewSHA1(namespace, []byte(source+":"+key)).String()
+}
+
+// Summary counts what Save did with each conversation.
+type Summary struct {
+	Imported int // new conversations
+	Updated  int // replaced with a newer version from the export
+	Skipped  int // already stored and not older than the export
+}
+
+// Save stores imported conversations. A conversation that was imported
+// before is only replaced when the export has a newer version of it, so
+// running an import again does not duplicate or undo anything. A zero
+// UpdatedAt means the export does not tell, and never replaces.
+func Save(st *store.Store, conversations []store.Conversation) (Summary, error) {
+	var summary Summary
+	for _, c := range conversations {
+		existing, err := st.Load(c.ID)
+		switch {
+		case err == nil && !c.UpdatedAt.After(existing.UpdatedAt):
+			summary.Skipped++
+			continue
+		case err == nil:
+			summary.Updated++
+		case errors.Is(err, store.ErrNotFound):
+			summary.Imported++
+		default:
+			return summary, err
+		}
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = time.Now()
+		}
+		if err := st.Save(c); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
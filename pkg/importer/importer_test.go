@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/export"
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+func TestSave(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := store.Conversation{
+		ID:        StableID("test", "1"),
+		Title:     "first",
+		Messages:  []llm.Message{{ID: "m1", Role: "user", Content: "hi"}},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	unknown := store.Conversation{
+		ID:       StableID("test", "2"),
+		Messages: []llm.Message{{ID: "m1", Role: "user", Content: "no times"}},
+	}
+
+	newer := c
+	newer.Title = "renamed"
+	newer.UpdatedAt = created.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		convs []store.Conversation
+		want  Summary
+		title string
+	}{
+		{"first import", []store.Conversation{c, unknown}, Summary{Imported: 2}, "first"},
+		{"same again", []store.Conversation{c, unknown}, Summary{Skipped: 2}, "first"},
+		{"newer version", []store.Conversation{newer}, Summary{Updated: 1}, "renamed"},
+		{"older version", []store.Conversation{c}, Summary{Skipped: 1}, "renamed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Save(st, tt.convs)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Save() = %+v, want %+v", got, tt.want)
+			}
+			stored, err := st.Load(c.ID)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if stored.Title != tt.title {
+				t.Errorf("stored title = %q, want %q", stored.Title, tt.title)
+			}
+		})
+	}
+
+	list, _ := st.List()
+	if len(list) != 2 {
+		t.Errorf("store holds %d conversations, want 2", len(list))
+	}
+	stored, _ := st.Load(unknown.ID)
+	if stored.CreatedAt.IsZero() {
+		t.Error("conversation without times was stored with a zero CreatedAt")
+	}
+}
+
+func TestManaRoundTrip(t *testing.T) {
+	c := store.NewConversation()
+	c.Title = "exported"
+	c.Messages = []llm.Message{{ID: "m1", Role: "user", Content: "hi"}}
+
+	for _, format := range []export.Format{export.JSON, export.JSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := export.Write(&buf, c, format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			convs, name, err := Read(buf.Bytes(), "")
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if name != "mana" {
+				t.Errorf("detected %q, want mana", name)
+			}
+			if len(convs) != 1 || convs[0].ID != c.ID || convs[0].Title != c.Title {
+				t.Errorf("Read() = %+v, want the exported conversation", convs)
+			}
+		})
+	}
+
+	c.ID = "../../escaped"
+	var buf bytes.Buffer
+	if err := export.Write(&buf, c, export.JSON); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	convs, _, err := Read(buf.Bytes(), "")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(convs) != 1 || !store.ValidID(convs[0].ID) {
+		t.Errorf("Read() kept the ID %q, want a fresh one", convs[0].ID)
+	}
+
+	if _, _, err := Read([]byte(`{"hello": "world"}`), ""); err == nil {
+		t.Error("Read() of an unknown format succeeded")
+	}
+	if _, _, err := Read([]byte(`[]`), "nope"); err == nil {
+		t.Error("Read() with an unknown importer succeeded")
+	}
+}
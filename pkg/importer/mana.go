@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/darling/mana/pkg/export"
+	"github.com/darling/mana/pkg/store"
+)
+
+func init() {
+	Register("mana", manaImporter{})
+}
+
+// manaImporter reads mana's own JSON and JSONL exports. Conversations keep
+// their IDs, so importing into the store they came from updates them; one
+// whose ID could not name a file gets a fresh ID.
+type manaImporter struct{}
+
+func (manaImporter) Detect(data []byte) bool {
+	// The first document, or the first line of JSONL, carries the version
+	data = bytes.TrimLeft(data, " \t\r\n[")
+	if line, _, ok := bytes.Cut(data, []byte("\n")); ok && json.Valid(line) {
+		data = line
+	}
+	var probe struct {
+		Version  int             `json:"version"`
+		ID       string          `json:"id"`
+		Messages json.RawMessage `json:"messages"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	return dec.Decode(&probe) == nil && probe.Version > 0 && probe.ID != "" && probe.Messages != nil
+}
+
+func (manaImporter) Import(data []byte) ([]store.Conversation, error) {
+	docs, err := export.Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	conversations := make([]store.Conversation, len(docs))
+	for i, d := range docs {
+		conversations[i] = d.Conversation()
+		if !store.ValidID(conversations[i].ID) {
+			conversations[i].ID = uuid.NewString()
+		}
+	}
+	return conversations, nil
+}
@@ -0,0 +1,173 @@
+// Package openai imports conversations in the OpenAI chat "messages"
+// format: a list of {"role", "content"} objects, an object holding such a
+// list under "messages", or JSONL with one such object per line as used for
+// fine-tuning data.
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darling/mana/pkg/importer"
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+func init() {
+	importer.Register("openai", Importer{})
+}
+
+type chat struct {
+	Title    string    `json:"title"`
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+}
+
+type message struct {
+	Role    string  `json:"role"`
+	Content content `json:"content"`
+}
+
+// content decodes a string or a list of content parts, keeping the text.
+type content string
+
+func (c *content) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*c = content(s)
+		return nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	*c = content(strings.Join(texts, "\n\n"))
+	return nil
+}
+
+// Importer reads OpenAI message lists.
+type Importer struct{}
+
+func (Importer) Detect(data []byte) bool {
+	_, err := decode(data)
+	return err == nil
+}
+
+func (Importer) Import(data []byte) ([]store.Conversation, error) {
+	chats, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []store.Conversation
+	for _, c := range chats {
+		if conv, ok := convert(c); ok {
+			out = append(out, conv)
+		}
+	}
+	return out, nil
+}
+
+// decode reads every chat in data, failing unless each has at least one
+// message with a role.
+func decode(data []byte) ([]chat, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("no messages")
+	}
+
+	var chats []chat
+	switch {
+	case trimmed[0] == '[':
+		var msgs []message
+		if err := json.Unmarshal(trimmed, &msgs); err == nil {
+			chats = []chat{{Messages: msgs}}
+		} else if err := json.Unmarshal(trimmed, &chats); err != nil {
+			return nil, fmt.Errorf("failed to decode messages: %w", err)
+		}
+	case json.Valid(trimmed):
+		var c chat
+		if err := json.Unmarshal(trimmed, &c); err != nil {
+			return nil, fmt.Errorf("failed to decode messages: %w", err)
+		}
+		chats = []chat{c}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		scanner.Buffer(make([]byte, 0, 64<<10), 64<<20)
+		for line := 1; scanner.Scan(); line++ {
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+			var c chat
+			if err := json.Unmarshal(text, &c); err != nil {
+				return nil, fmt.Errorf("failed to decode line %d: %w", line, err)
+			}
+			chats = append(chats, c)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read messages: %w", err)
+		}
+	}
+
+	for i, c := range chats {
+		if len(c.Messages) == 0 || c.Messages[0].Role == "" {
+			return nil, fmt.Errorf("conversation %d has no messages", i+1)
+		}
+	}
+	return chats, nil
+}
+
+// convert maps a chat to a conversation. The format has no IDs or
+// timestamps, so the conversation is identified by a hash of its messages
+// and its times are left for the store to fill in.
+func convert(c chat) (store.Conversation, bool) {
+	conv := store.Conversation{Title: c.Title, Model: c.Model}
+	hash := sha256.New()
+	for _, m := range c.Messages {
+		text := strings.TrimSpace(string(m.Content))
+		fmt.Fprintf(hash, "%s\x00%s\x00", m.Role, text)
+		switch m.Role {
+		case "system", "developer":
+			if conv.System == "" {
+				conv.System = text
+			}
+		case "user", "assistant":
+			if text == "" {
+				continue
+			}
+			msg := llm.Message{Role: m.Role, Content: text}
+			if m.Role == "assistant" {
+				msg.Model = c.Model
+			}
+			conv.Messages = append(conv.Messages, msg)
+		}
+	}
+	if len(conv.Messages) == 0 {
+		return store.Conversation{}, false
+	}
+	conv.ID = importer.StableID("openai", hex.EncodeToString(hash.Sum(nil)))
+	// Message IDs must be stable too, or re-importing would change them
+	for i := range conv.Messages {
+		conv.Messages[i].ID = importer.StableID(conv.ID, fmt.Sprint(i))
+		if i > 0 {
+			conv.Messages[i].ParentID = conv.Messages[i-1].ID
+		}
+	}
+	conv.Leaf = conv.Messages[len(conv.Messages)-1].ID
+	return conv, true
+}
@@ -0,0 +1,66 @@
+package openai
+
+import (
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		convs  int
+		system string
+		msgs   int
+	}{
+		{
+			name:  "message list",
+			data:  `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}, {"role": "assistant", "content": [{"type": "text", "text": "Hello"}]}]`,
+			convs: 1, system: "Be brief.", msgs: 2,
+		},
+		{
+			name:  "messages object",
+			data:  `{"model": "gpt-4o", "messages": [{"role": "user", "content": "Hi"}]}`,
+			convs: 1, msgs: 1,
+		},
+		{
+			name:  "jsonl",
+			data:  "{\"messages\": [{\"role\": \"user\", \"content\": \"A\"}]}\n{\"messages\": [{\"role\": \"user\", \"content\": \"B\"}]}\n",
+			convs: 2, msgs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{}
+			if !i.Detect([]byte(tt.data)) {
+				t.Fatal("Detect() = false")
+			}
+			convs, err := i.Import([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if len(convs) != tt.convs {
+				t.Fatalf("Import() returned %d conversations, want %d", len(convs), tt.convs)
+			}
+			c := convs[0]
+			if c.System != tt.system || len(c.Messages) != tt.msgs {
+				t.Errorf("System, messages = %q, %d, want %q, %d", c.System, len(c.Messages), tt.system, tt.msgs)
+			}
+			if c.Leaf != c.Messages[len(c.Messages)-1].ID {
+				t.Errorf("Leaf = %q, want the last message", c.Leaf)
+			}
+
+			again, _ := i.Import([]byte(tt.data))
+			if again[0].ID != c.ID || again[0].Messages[0].ID != c.Messages[0].ID {
+				t.Error("importing twice gave different IDs")
+			}
+		})
+	}
+}
+
+func TestDetectRejects(t *testing.T) {
+	for _, data := range []string{``, `{"title": "x"}`, `[{"mapping": {}}]`, `not json`} {
+		if (Importer{}).Detect([]byte(data)) {
+			t.Errorf("Detect(%q) = true", data)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package openrouter imports chats exported from the OpenRouter chatroom.
+//
+// A chatroom export is a JSON object with a "version" starting with "orpg",
+// the models taking part as "characters", and the messages keyed by ID:
+//
+//	{
+//	  "version": "orpg.3.0",
+//	  "title": "…",
+//	  "characters": {"char-1": {"model": "openai/gpt-4o"}},
+//	  "messages": {
+//	    "msg-1": {"characterId": "USER", "content": "…", "createdAt": "…"},
+//	    "msg-2": {"characterId": "char-1", "content": "…", "parentMessageId": "msg-1"}
+//	  }
+//	}
+//
+// Messages without a parent are chained in the order they were created.
+package openrouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darling/mana/pkg/importer"
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+func init() {
+	importer.Register("openrouter", Importer{})
+}
+
+// userCharacter is the character ID of messages the user wrote.
+const userCharacter = "USER"
+
+type chat struct {
+	Version    string               `json:"version"`
+	Title      string               `json:"title"`
+	Characters map[string]character `json:"characters"`
+	Messages   messages             `json:"messages"`
+}
+
+type character struct {
+	Model string `json:"model"`
+}
+
+type message struct {
+	ID              string    `json:"id"`
+	CharacterID     string    `json:"characterId"`
+	Content         string    `json:"content"`
+	CreatedAt       timestamp `json:"createdAt"`
+	UpdatedAt       timestamp `json:"updatedAt"`
+	ParentMessageID string    `json:"parentMessageId"`
+}
+
+// messages decodes either an object keyed by message ID or a plain list.
+type messages []message
+
+func (m *messages) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]message)(m))
+	}
+	var byID map[string]message
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return err
+	}
+	for id, msg := range byID {
+		if msg.ID == "" {
+			msg.ID = id
+		}
+		*m = append(*m, msg)
+	}
+	return nil
+}
+
+// timestamp decodes an RFC 3339 string or milliseconds since the epoch.
+type timestamp struct{ time.Time }
+
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	var ms float64
+	if json.Unmarshal(data, &ms) == nil {
+		if ms > 0 {
+			t.Time = time.UnixMilli(int64(ms)).UTC()
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil || s == "" {
+		return err
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// Importer reads OpenRouter chatroom exports.
+type Importer struct{}
+
+func (Importer) Detect(data []byte) bool {
+	var probe struct {
+		Version  string          `json:"version"`
+		Messages json.RawMessage `json:"messages"`
+	}
+	return json.Unmarshal(data, &probe) == nil && strings.HasPrefix(probe.Version, "orpg") && probe.Messages != nil
+}
+
+func (Importer) Import(data []byte) ([]store.Conversation, error) {
+	var c chat
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to decode chat: %w", err)
+	}
+	if len(c.Messages) == 0 {
+		return nil, nil
+	}
+	return []store.Conversation{convert(c)}, nil
+}
+
+func convert(c chat) store.Conversation {
+	msgs := append(messages(nil), c.Messages...)
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if !msgs[i].CreatedAt.Equal(msgs[j].CreatedAt.Time) {
+			return msgs[i].CreatedAt.Before(msgs[j].CreatedAt.Time)
+		}
+		return msgs[i].ID < msgs[j].ID
+	})
+
+	// The first message stays the same as the chat grows, so it identifies
+	// the chat across exports
+	conv := store.Conversation{
+		ID:        importer.StableID("openrouter", msgs[0].ID),
+		Title:     c.Title,
+		CreatedAt: msgs[0].CreatedAt.Time,
+	}
+	known := make(map[string]bool, len(msgs))
+	for _, m := range msgs {
+		known[m.ID] = true
+	}
+	prev := ""
+	for _, m := range msgs {
+		msg := llm.Message{
+			ID:        m.ID,
+			Role:      "assistant",
+			Content:   m.Content,
+			ParentID:  m.ParentMessageID,
+			CreatedAt: m.CreatedAt.Time,
+		}
+		if !known[msg.ParentID] {
+			msg.ParentID = prev
+		}
+		if m.CharacterID == userCharacter {
+			msg.Role = "user"
+		} else {
+			msg.Provider = "openrouter"
+			msg.Model = c.Characters[m.CharacterID].Model
+		}
+		conv.Messages = append(conv.Messages, msg)
+		prev = msg.ID
+
+		updated := m.UpdatedAt.Time
+		if updated.IsZero() {
+			updated = m.CreatedAt.Time
+		}
+		if updated.After(conv.UpdatedAt) {
+			conv.UpdatedAt = updated
+		}
+	}
+	conv.Leaf = prev
+	return conv
+}
@@ -0,0 +1,66 @@
+package openrouter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/importer"
+)
+
+const export = `{
+  "version": "orpg.3.0",
+  "title": "Haiku",
+  "characters": {"char-1": {"model": "anthropic/claude-3.5-sonnet"}},
+  "messages": {
+    "m2": {"characterId": "char-1", "content": "Autumn moonlight", "createdAt": "2024-05-01T10:00:05Z", "parentMessageId": "m1"},
+    "m1": {"characterId": "USER", "content": "Write a haiku", "createdAt": "2024-05-01T10:00:00Z"},
+    "m3": {"characterId": "USER", "content": "Another", "createdAt": 1714557610000}
+  }
+}`
+
+func TestImport(t *testing.T) {
+	i := Importer{}
+	if !i.Detect([]byte(export)) {
+		t.Fatal("Detect() = false for a chatroom export")
+	}
+	if i.Detect([]byte(`{"messages": []}`)) {
+		t.Error("Detect() = true without an orpg version")
+	}
+
+	convs, err := i.Import([]byte(export))
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(convs) != 1 {
+		t.Fatalf("Import() returned %d conversations, want 1", len(convs))
+	}
+	c := convs[0]
+	if c.ID != importer.StableID("openrouter", "m1") {
+		t.Errorf("ID = %q, want the stable ID of the first message", c.ID)
+	}
+	if c.Title != "Haiku" || c.Leaf != "m3" {
+		t.Errorf("Title, Leaf = %q, %q", c.Title, c.Leaf)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 10, 0, time.UTC); !c.UpdatedAt.Equal(want) {
+		t.Errorf("UpdatedAt = %v, want %v", c.UpdatedAt, want)
+	}
+
+	tests := []struct {
+		id, role, parent, model string
+	}{
+		{"m1", "user", "", ""},
+		{"m2", "assistant", "m1", "anthropic/claude-3.5-sonnet"},
+		{"m3", "user", "m2", ""},
+	}
+	if len(c.Messages) != len(tests) {
+		t.Fatalf("got %d messages, want %d", len(c.Messages), len(tests))
+	}
+	for n, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got := c.Messages[n]
+			if got.ID != tt.id || got.Role != tt.role || got.ParentID != tt.parent || got.Model != tt.model {
+				t.Errorf("message = %+v, want %+v", got, tt)
+			}
+		})
+	}
+}
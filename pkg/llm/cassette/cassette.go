@@ -0,0 +1,288 @@
+// Package cassette records the HTTP traffic of providers into cassette
+// files and replays it in tests, offline and without API keys.
+//
+// Cassettes live in testdata/cassettes of the package under test. Tests
+// replay them by default; run them with -record and real API keys to
+// capture fresh traffic:
+//
+//	OPENROUTER_API_KEY=... go test ./pkg/llm/providers/openrouter -record
+//
+// Headers carrying credentials are scrubbed before a cassette is written.
+// When replaying, a request must match a recorded one by method, URL and
+// body, or the test fails.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var record = flag.Bool("record", false, "record cassettes from real HTTP traffic")
+
+// ScrubbedHeaders are left out of recorded requests and responses.
+var ScrubbedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"Api-Key",
+}
+
+// ErrNoMatch is returned for a request the cassette has no response to.
+var ErrNoMatch = errors.New("request does not match the cassette")
+
+// Cassette is the recorded traffic of a test.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording into or replaying from a
+// cassette.
+type Recorder struct {
+	t         testing.TB
+	path      string
+	recording bool
+	// Transport makes the real requests while recording
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	played   []bool
+}
+
+// New returns a recorder for testdata/cassettes/name.json. With -record it
+// records real traffic and writes the cassette when the test ends;
+// otherwise it replays the cassette, which must exist.
+func New(t testing.TB, name string) *Recorder {
+	t.Helper()
+	r := &Recorder{
+		t:         t,
+		path:      filepath.Join("testdata", "cassettes", name+".json"),
+		recording: *record,
+		Transport: http.DefaultTransport,
+	}
+	if r.recording {
+		t.Cleanup(r.save)
+		return r
+	}
+
+	c, err := Load(r.path)
+	if err != nil {
+		t.Fatalf("%v (run with -record to create it)", err)
+	}
+	r.cassette = c
+	r.played = make([]bool, len(c.Interactions))
+	t.Cleanup(r.checkPlayed)
+	return r
+}
+
+// Load reads the cassette at path.
+func Load(path string) (Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Cassette{}, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cassette{}, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Recording reports whether the recorder makes real requests, so that a
+// test can skip when it has no API key to make them with.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// Client returns an HTTP client going through the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	got := Request{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: scrub(req.Header),
+		Body:    string(body),
+	}
+	if r.recording {
+		return r.roundTripRecording(req, got)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if !r.played[i] && in.Request.matches(got) {
+			r.played[i] = true
+			return in.Response.toHTTP(req), nil
+		}
+	}
+	r.t.Errorf("%s: no recorded response to %s %s\n body: %s\n%s", r.path, got.Method, got.URL, got.Body, r.unplayed())
+	return nil, fmt.Errorf("%w: %s %s", ErrNoMatch, got.Method, got.URL)
+}
+
+func (r *Recorder) roundTripRecording(req *http.Request, got Request) (*http.Response, error) {
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	in := Interaction{
+		Request: got,
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: scrub(resp.Header),
+			Body:    string(body),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return in.Response.toHTTP(req), nil
+}
+
+// save writes the recorded cassette, unless the test failed or skipped
+// and would leave an incomplete one.
+func (r *Recorder) save() {
+	if r.t.Failed() || r.t.Skipped() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		r.t.Errorf("failed to encode cassette: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		r.t.Errorf("failed to create cassette directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		r.t.Errorf("failed to write cassette: %v", err)
+	}
+}
+
+// checkPlayed fails the test when recorded requests were never made, as
+// the code no longer makes them.
+func (r *Recorder) checkPlayed() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if unplayed := r.unplayed(); unplayed != "" {
+		r.t.Errorf("%s: recorded requests were not made\n%s", r.path, unplayed)
+	}
+}
+
+// unplayed lists the requests not replayed yet.
+func (r *Recorder) unplayed() string {
+	var b bytes.Buffer
+	for i, in := range r.cassette.Interactions {
+		if !r.played[i] {
+			fmt.Fprintf(&b, " recorded: %s %s\n body: %s\n", in.Request.Method, in.Request.URL, in.Request.Body)
+		}
+	}
+	return b.String()
+}
+
+// matches reports whether got is the recorded request, comparing JSON
+// bodies by value.
+func (want Request) matches(got Request) bool {
+	if want.Method != got.Method || want.URL != got.URL {
+		return false
+	}
+	return normalize(want.Body) == normalize(got.Body)
+}
+
+// normalize compacts a JSON body so that formatting does not matter.
+func normalize(body string) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(body)); err != nil {
+		return body
+	}
+	return b.String()
+}
+
+func (resp Response) toHTTP(req *http.Request) *http.Response {
+	headers := resp.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.Status, http.StatusText(resp.Status)),
+		StatusCode:    resp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewBufferString(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// readBody reads the request's body and puts it back for the transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrub copies headers without those carrying credentials.
+func scrub(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := headers.Clone()
+	for _, name := range ScrubbedHeaders {
+		h.Del(name)
+	}
+	return h
+}
@@ -0,0 +1,173 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTB collects what a recorder reports instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func (tb *fakeTB) Fatalf(format string, args ...any) {
+	tb.Errorf(format, args...)
+}
+
+func (tb *fakeTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+// finish runs the cleanups, like the end of a test.
+func (tb *fakeTB) finish() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func setRecord(t *testing.T, on bool) {
+	old := *record
+	*record = on
+	t.Cleanup(func() { *record = old })
+}
+
+func post(t *testing.T, client *http.Client, url, body string) (string, error) {
+	t.Helper()
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("%d %s", resp.StatusCode, got), nil
+}
+
+// recordEcho records one request to a server echoing bodies and returns
+// its URL, no longer served.
+func recordEcho(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	setRecord(t, true)
+	tb := &fakeTB{TB: t}
+	rec := New(tb, "echo")
+	if !rec.Recording() {
+		t.Fatal("Recording() = false with -record")
+	}
+	got, err := post(t, rec.Client(), server.URL+"/echo", `{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `201 {"a": 1}`; got != want {
+		t.Errorf("recorded response = %q, want %q", got, want)
+	}
+	tb.finish()
+	if tb.errors != nil {
+		t.Fatalf("recording reported %q", tb.errors)
+	}
+	setRecord(t, false)
+	return server.URL
+}
+
+func TestRecorder_Record(t *testing.T) {
+	t.Chdir(t.TempDir())
+	recordEcho(t)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "cassettes", "echo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("the cassette keeps credentials:\n%s", data)
+	}
+	c, err := Load(filepath.Join("testdata", "cassettes", "echo.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 1 || c.Interactions[0].Request.Headers.Get("Content-Type") != "application/json" {
+		t.Errorf("cassette = %+v, want the request with its other headers", c)
+	}
+}
+
+func TestRecorder_Replay(t *testing.T) {
+	t.Chdir(t.TempDir())
+	url := recordEcho(t)
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		requests   int
+		want       string
+		wantErrors int
+	}{
+		{name: "same request", url: url + "/echo", body: `{"a": 1}`, requests: 1, want: `201 {"a": 1}`},
+		{name: "reformatted body", url: url + "/echo", body: `{"a":1}`, requests: 1, want: `201 {"a": 1}`},
+		// The unmatched request and the unplayed recording are both flagged
+		{name: "changed body", url: url + "/echo", body: `{"a": 2}`, requests: 1, wantErrors: 2},
+		{name: "changed URL", url: url + "/other", body: `{"a": 1}`, requests: 1, wantErrors: 2},
+		{name: "played twice", url: url + "/echo", body: `{"a": 1}`, requests: 2, want: `201 {"a": 1}`, wantErrors: 1},
+		{name: "not made", requests: 0, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := &fakeTB{TB: t}
+			rec := New(tb, "echo")
+			for i := range tt.requests {
+				got, err := post(t, rec.Client(), tt.url, tt.body)
+				if i == 0 && tt.want != "" {
+					if err != nil || got != tt.want {
+						t.Errorf("response = %q, %v, want %q", got, err, tt.want)
+					}
+				} else if !errors.Is(err, ErrNoMatch) {
+					t.Errorf("error = %v, want ErrNoMatch", err)
+				}
+			}
+			tb.finish()
+			if len(tb.errors) != tt.wantErrors {
+				t.Errorf("reported %q, want %d errors", tb.errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestRecorder_Missing(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tb := &fakeTB{TB: t}
+	New(tb, "missing")
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "-record") {
+		t.Errorf("reported %q, want a hint to record the cassette", tb.errors)
+	}
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const summarizePrompt = `You are compacting a long conversation so it fits in a smaller context window.
+Summarize everything above in a concise but complete way. Keep decisions, facts, file names,
+code snippets and open questions that later turns may depend on. Write the summary in the
+second person, as notes to the assistant continuing the conversation. Reply with the summary only.`
+
+// EstimateTokens roughly estimates the number of tokens the history occupies.
+// It assumes around four characters per token, which is close enough to decide
+// when to compact without depending on a provider-specific tokenizer.
+func EstimateTokens(history []Message) int {
+	chars := 0
+	for _, msg := range history {
+		chars += len(msg.Role) + len(msg.Content)
+	}
+	return chars / 4
+}
+
+// Summarize asks the model to condense history into a single pinned message
+// that can stand in for it in later requests.
+func (m *Manager) Summarize(ctx context.Context, history []Message, opts ...GenerateOption) (Message, error) {
+	if len(history) == 0 {
+		return Message{}, errors.New("nothing to summarize")
+	}
+
+	request := append(append([]Message(nil), history...), Message{Role: "user", Content: summarizePrompt})
+	resp, err := m.Generate(ctx, request, opts...)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+
+	content := strings.TrimSpace(resp.Content)
+	if content == "" {
+		return Message{}, errors.New("model returned an empty summary")
+	}
+
+	summarized := 0
+	for _, msg := range history {
+		if msg.Summarizes > 0 {
+			summarized += msg.Summarizes
+		} else if !msg.Pinned {
+			summarized++
+		}
+	}
+
+	return Message{
+		ID:         resp.ID,
+		Provider:   resp.Provider,
+		Role:       "system",
+		Content:    content,
+		Pinned:     true,
+		Summarizes: summarized,
+	}, nil
+}
+
+// Compact replaces the messages before index with summary. Pinned messages in
+// that range that are not themselves summaries stay at the top of the history.
+// It returns the new history and the original turns that were removed, which
+// callers should archive rather than discard.
+func Compact(history []Message, before int, summary Message) (kept, archived []Message) {
+	if before <= 0 {
+		return append([]Message(nil), history...), nil
+	}
+	if before > len(history) {
+		before = len(history)
+	}
+
+	for _, msg := range history[:before] {
+		switch {
+		case msg.Summarizes > 0:
+			// Earlier summaries are folded into the new one and were never original turns.
+		case msg.Pinned:
+			kept = append(kept, msg)
+		default:
+			archived = append(archived, msg)
+		}
+	}
+
+	kept = append(kept, summary)
+	kept = append(kept, history[before:]...)
+	return kept, archived
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+type stubProvider struct {
+	reply   Message
+	history []Message
+}
+
+func (p *stubProvider) Generate(ctx context.Context, history []Message, opts ...GenerateOption) (Message, error) {
+	p.history = history
+	return p.reply, nil
+}
+
+func (p *stubProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *stubProvider) Close() error { return nil }
+
+func TestEstimateTokens(t *testing.T) {
+	history := []Message{
+		{Role: "user", Content: "12345678"},     // 4 + 8
+		{Role: "assistant", Content: "1234567"}, // 9 + 7
+	}
+	if got, want := EstimateTokens(history), 7; got != want {
+		t.Errorf("EstimateTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestManager_Summarize(t *testing.T) {
+	provider := &stubProvider{reply: Message{ID: "gen-1", Provider: "stub", Role: "assistant", Content: "  the gist  "}}
+	m := &Manager{provider: provider}
+
+	history := []Message{
+		{Role: "system", Content: "persona", Pinned: true},
+		{Role: "system", Content: "old summary", Pinned: true, Summarizes: 4},
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+	}
+
+	summary, err := m.Summarize(context.Background(), history)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary.Content != "the gist" {
+		t.Errorf("Content = %q, want %q", summary.Content, "the gist")
+	}
+	if summary.Role != "system" || !summary.Pinned {
+		t.Errorf("summary = %+v, want pinned system message", summary)
+	}
+	if summary.Summarizes != 6 {
+		t.Errorf("Summarizes = %d, want 6", summary.Summarizes)
+	}
+	if n := len(provider.history); n != len(history)+1 {
+		t.Errorf("request length = %d, want %d", n, len(history)+1)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	history := []Message{
+		{Role: "system", Content: "persona", Pinned: true},
+		{Role: "system", Content: "old summary", Pinned: true, Summarizes: 4},
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "two"},
+		{Role: "user", Content: "three"},
+		{Role: "assistant", Content: "four"},
+	}
+	summary := Message{Role: "system", Content: "new summary", Pinned: true, Summarizes: 6}
+
+	kept, archived := Compact(history, 4, summary)
+
+	wantKept := []string{"persona", "new summary", "three", "four"}
+	if len(kept) != len(wantKept) {
+		t.Fatalf("kept length = %d, want %d", len(kept), len(wantKept))
+	}
+	for i, content := range wantKept {
+		if kept[i].Content != content {
+			t.Errorf("kept[%d] = %q, want %q", i, kept[i].Content, content)
+		}
+	}
+
+	wantArchived := []string{"one", "two"}
+	if len(archived) != len(wantArchived) {
+		t.Fatalf("archived length = %d, want %d", len(archived), len(wantArchived))
+	}
+	for i, content := range wantArchived {
+		if archived[i].Content != content {
+			t.Errorf("archived[%d] = %q, want %q", i, archived[i].Content, content)
+		}
+	}
+}
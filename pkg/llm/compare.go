@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is one model's answer in a comparison.
+type Result struct {
+	Index   int // position of Model in the models passed to Compare
+	Model   string
+	Message Message
+	Latency time.Duration
+	Err     error
+}
+
+// Compare sends the same history to every model concurrently. Results are
+// delivered on the returned channel as they arrive, which is closed once
+// all models have answered or failed.
+func (m *Manager) Compare(ctx context.Context, history []Message, models []string, opts ...GenerateOption) <-chan Result {
+	results := make(chan Result, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			msg, err := m.Generate(ctx, history, append(opts[:len(opts):len(opts)], WithModel(model))...)
+			if err == nil && msg.Model == "" {
+				msg.Model = model
+			}
+			results <- Result{Index: i, Model: model, Message: msg, Latency: time.Since(start), Err: err}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
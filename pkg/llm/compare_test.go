@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// modelProvider answers with the model it was asked for, failing for "bad".
+type modelProvider struct{}
+
+func (modelProvider) Generate(ctx context.Context, history []Message, opts ...GenerateOption) (Message, error) {
+	o := ApplyOptions(opts...)
+	if o.Model == "bad" {
+		return Message{}, errors.New("unavailable")
+	}
+	return Message{Role: "assistant", Content: "from " + o.Model}, nil
+}
+
+func (modelProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (modelProvider) Close() error { return nil }
+
+func TestManager_Compare(t *testing.T) {
+	m := &Manager{provider: modelProvider{}}
+	models := []string{"a", "bad", "c"}
+
+	var results []Result
+	for r := range m.Compare(context.Background(), []Message{{Role: "user", Content: "hi"}}, models, WithTemperature(0.5)) {
+		results = append(results, r)
+	}
+	if len(results) != len(models) {
+		t.Fatalf("got %d results, want %d", len(results), len(models))
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Index < results[j].Index })
+
+	for i, r := range results {
+		if r.Model != models[i] {
+			t.Errorf("results[%d].Model = %q, want %q", i, r.Model, models[i])
+		}
+	}
+	if results[1].Err == nil {
+		t.Error("results[1].Err = nil, want error")
+	}
+	if got := results[2].Message; got.Content != "from c" || got.Model != "c" {
+		t.Errorf("results[2].Message = %+v, want answer from c", got)
+	}
+}
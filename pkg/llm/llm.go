@@ -3,7 +3,9 @@ package llm
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
+	"time"
 )
 
 type Message struct {
@@ -11,12 +13,38 @@ type Message struct {
 	Provider string `json:"provider"`
 	Role     string `json:"role"`
 	Content  string `json:"content"`
+
+	// ParentID is the message this one replies to. Messages sharing a
+	// parent are alternative branches; see Tree.
+	ParentID string `json:"parent_id,omitempty"`
+
+	// CreatedAt is when the message was added to the conversation. It is
+	// zero for messages stored before timestamps were recorded.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+
+	// Model is the model that wrote an assistant message, when known.
+	Model string `json:"model,omitempty"`
+	// Usage reports what generating an assistant message cost, when the
+	// provider tells.
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Pinned messages are kept at the top of the history when it is compacted.
+	Pinned bool `json:"pinned,omitempty"`
+	// Summarizes is the number of earlier messages this message stands in for.
+	Summarizes int `json:"summarizes,omitempty"`
+}
+
+// Usage is the token count and price of a single request.
+type Usage struct {
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost,omitempty"` // in USD, zero when unknown
 }
 
 // Provider defines the interface for a Language Model (LLM) provider.
 type Provider interface {
 	// Generate generates a response from the LLM based on the provided messages.
-	Generate(ctx context.Context, history []Message) (Message, error)
+	Generate(ctx context.Context, history []Message, opts ...GenerateOption) (Message, error)
 
 	// ListModels lists the available models for the LLM provider.
 	ListModels(ctx context.Context) ([]string, error)
@@ -28,10 +56,17 @@ type Provider interface {
 type Config struct {
 	APIKey string
 	Model  string
+
+	// BaseURL replaces the provider's API endpoint, for proxies and tests
+	BaseURL string
+	// HTTPClient makes the provider's requests instead of a default client,
+	// like one replaying a cassette in tests
+	HTTPClient *http.Client
 }
 
 type Manager struct {
 	provider Provider
+	config   Config
 }
 
 var (
@@ -53,11 +88,16 @@ func NewManager(providerType string, config Config) (*Manager, error) {
 		return nil, err
 	}
 
-	return &Manager{provider: provider}, nil
+	return &Manager{provider: provider, config: config}, nil
+}
+
+func (m *Manager) Generate(ctx context.Context, history []Message, opts ...GenerateOption) (Message, error) {
+	return m.provider.Generate(ctx, history, opts...)
 }
 
-func (m *Manager) Generate(ctx context.Context, history []Message) (Message, error) {
-	return m.provider.Generate(ctx, history)
+// Model returns the model used when a request does not override it.
+func (m *Manager) Model() string {
+	return m.config.Model
 }
 
 func (m *Manager) ListModels(ctx context.Context) ([]string, error) {
@@ -0,0 +1,46 @@
+package llm
+
+// GenerateOptions holds per-request overrides of the provider configuration.
+type GenerateOptions struct {
+	Model       string
+	Temperature *float64
+	MaxTokens   int
+
+	// OnChunk receives the response in pieces as they arrive, in order and
+	// before Generate returns, from providers that stream. Others only
+	// return the whole message.
+	OnChunk func(chunk string)
+}
+
+// GenerateOption configures a single Generate call.
+type GenerateOption func(*GenerateOptions)
+
+// WithModel overrides the model used for a request.
+func WithModel(model string) GenerateOption {
+	return func(o *GenerateOptions) { o.Model = model }
+}
+
+// WithTemperature sets the sampling temperature for a request.
+func WithTemperature(temperature float64) GenerateOption {
+	return func(o *GenerateOptions) { o.Temperature = &temperature }
+}
+
+// WithMaxTokens limits the length of the generated response.
+func WithMaxTokens(maxTokens int) GenerateOption {
+	return func(o *GenerateOptions) { o.MaxTokens = maxTokens }
+}
+
+// WithOnChunk streams the response to fn as it arrives, where the provider
+// supports it.
+func WithOnChunk(fn func(chunk string)) GenerateOption {
+	return func(o *GenerateOptions) { o.OnChunk = fn }
+}
+
+// ApplyOptions resolves opts into a GenerateOptions value.
+func ApplyOptions(opts ...GenerateOption) GenerateOptions {
+	var o GenerateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,156 @@
+// Package fake is an LLM provider answering from a script, without
+// network or cost. It echoes messages, simulates latency and streaming,
+// and fails on demand, for tests and the demo mode.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+// FixtureEnv names the variable with the path of the script the registered
+// provider answers from. Without it the provider uses the demo script.
+const FixtureEnv = "MANA_FAKE_FIXTURE"
+
+const (
+	// DefaultModel is used when neither the config nor a request names one
+	DefaultModel = "fake/demo"
+	// EchoModel echoes every message, whatever the script
+	EchoModel = "fake/echo"
+)
+
+func init() {
+	llm.Register("fake", New)
+}
+
+// Provider answers from a Script.
+type Provider struct {
+	script Script
+	model  string
+
+	mu    sync.Mutex
+	count int // requests answered, for message IDs
+}
+
+// New creates a provider answering from the script in $MANA_FAKE_FIXTURE,
+// or the demo script.
+func New(cfg llm.Config) (llm.Provider, error) {
+	script := DemoScript()
+	if path := os.Getenv(FixtureEnv); path != "" {
+		var err error
+		if script, err = LoadScript(path); err != nil {
+			return nil, err
+		}
+	}
+	return NewProvider(script, cfg.Model), nil
+}
+
+// NewProvider creates a provider answering from script with model by
+// default.
+func NewProvider(script Script, model string) *Provider {
+	if model == "" {
+		model = DefaultModel
+	}
+	return &Provider{script: script, model: model}
+}
+
+func (p *Provider) Generate(ctx context.Context, history []llm.Message, opts ...llm.GenerateOption) (llm.Message, error) {
+	options := llm.ApplyOptions(opts...)
+	model := p.model
+	if options.Model != "" {
+		model = options.Model
+	}
+
+	var last string
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			last = history[i].Content
+			break
+		}
+	}
+	response := p.script.match(last)
+	if model == EchoModel {
+		response = Response{Echo: true}
+	}
+
+	latency := p.script.Latency
+	if response.Latency != nil {
+		latency = *response.Latency
+	}
+	if err := sleep(ctx, time.Duration(latency)); err != nil {
+		return llm.Message{}, err
+	}
+	if e := response.Error; e != nil {
+		return llm.Message{}, fmt.Errorf("API error (code %d): %s", e.Status, e.Message)
+	}
+
+	var content strings.Builder
+	for i, chunk := range response.chunks(last) {
+		if response.TruncateAfter > 0 && i == response.TruncateAfter {
+			return llm.Message{}, fmt.Errorf("failed to read stream after %d chunks: %w", i, io.ErrUnexpectedEOF)
+		}
+		if i > 0 {
+			if err := sleep(ctx, time.Duration(response.ChunkDelay)); err != nil {
+				return llm.Message{}, err
+			}
+		}
+		if options.OnChunk != nil {
+			options.OnChunk(chunk)
+		}
+		content.WriteString(chunk)
+	}
+
+	p.mu.Lock()
+	p.count++
+	id := fmt.Sprintf("fake-%d", p.count)
+	p.mu.Unlock()
+
+	prompt := 0
+	for _, m := range history {
+		prompt += len(strings.Fields(m.Content))
+	}
+	return llm.Message{
+		ID:       id,
+		Provider: "fake",
+		Model:    model,
+		Role:     "assistant",
+		Content:  content.String(),
+		Usage: &llm.Usage{
+			PromptTokens:     prompt,
+			CompletionTokens: len(strings.Fields(content.String())),
+		},
+	}, nil
+}
+
+// sleep waits for d unless ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
+	if len(p.script.Models) == 0 {
+		return []string{DefaultModel, EchoModel}, nil
+	}
+	return append([]string(nil), p.script.Models...), nil
+}
+
+func (p *Provider) Close() error {
+	return nil
+}
@@ -0,0 +1,168 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+func loadTestScript(t *testing.T) Script {
+	t.Helper()
+	script, err := LoadScript("testdata/script.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestProvider_Generate(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		model      string
+		want       string
+		wantChunks []string
+		wantErr    string
+	}{
+		{name: "scripted", message: "Hello!", want: "Hi there!"},
+		{name: "echo", message: "anything else", want: "anything else"},
+		{name: "echo model", message: "hello", model: EchoModel, want: "hello"},
+		{name: "streamed", message: "stream please", want: "one two three", wantChunks: []string{"one ", "two ", "three"}},
+		{name: "rate limited", message: "busy?", wantErr: "API error (code 429): rate limited"},
+		{name: "truncated", message: "cut", wantChunks: []string{"one ", "two "}, wantErr: "failed to read stream after 2 chunks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(loadTestScript(t), "")
+			var chunks []string
+			opts := []llm.GenerateOption{llm.WithOnChunk(func(c string) { chunks = append(chunks, c) })}
+			if tt.model != "" {
+				opts = append(opts, llm.WithModel(tt.model))
+			}
+
+			msg, err := p.Generate(context.Background(), []llm.Message{{Role: "user", Content: tt.message}}, opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Generate() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if msg.Content != tt.want {
+				t.Errorf("Content = %q, want %q", msg.Content, tt.want)
+			}
+			if tt.wantChunks != nil && !reflect.DeepEqual(chunks, tt.wantChunks) {
+				t.Errorf("chunks = %q, want %q", chunks, tt.wantChunks)
+			}
+		})
+	}
+}
+
+func TestProvider_GenerateTruncated(t *testing.T) {
+	p := NewProvider(loadTestScript(t), "")
+	_, err := p.Generate(context.Background(), []llm.Message{{Role: "user", Content: "cut"}})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want an unexpected EOF", err)
+	}
+}
+
+func TestProvider_GenerateCancelled(t *testing.T) {
+	p := NewProvider(loadTestScript(t), "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.Generate(ctx, []llm.Message{{Role: "user", Content: "slow"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want the deadline to cut the latency short", err)
+	}
+}
+
+func TestProvider_Message(t *testing.T) {
+	p := NewProvider(Script{}, "fake/test")
+	history := []llm.Message{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "two words"},
+	}
+	first, _ := p.Generate(context.Background(), history)
+	second, _ := p.Generate(context.Background(), history)
+
+	if first.ID == second.ID {
+		t.Errorf("both answers have ID %q", first.ID)
+	}
+	if first.Role != "assistant" || first.Provider != "fake" || first.Model != "fake/test" {
+		t.Errorf("message = %+v, want an assistant message of fake/test", first)
+	}
+	if u := first.Usage; u == nil || u.PromptTokens != 4 || u.CompletionTokens != 2 {
+		t.Errorf("usage = %+v, want 4 prompt and 2 completion tokens", u)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(FixtureEnv, "testdata/script.json")
+	p, err := New(llm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, _ := p.ListModels(context.Background())
+	if want := []string{"fake/test"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("models = %v, want the fixture's %v", models, want)
+	}
+
+	t.Setenv(FixtureEnv, "testdata/missing.json")
+	if _, err := New(llm.Config{}); err == nil {
+		t.Error("New() with a missing fixture succeeded")
+	}
+}
+
+func TestScript_Match(t *testing.T) {
+	script := Script{Responses: []Response{
+		{Match: "go", Content: "gopher"},
+		{Match: "name the conversation", Content: "title"},
+	}}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Show me some Go!", "gopher"},
+		{"go", "gopher"},
+		{"a good algorithm", ""},
+		{"goroutines or go routines", "gopher"},
+		{"Please name the conversation above", "title"},
+		{"rename the conversations", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := script.match(tt.text); got.Content != tt.want {
+				t.Errorf("match(%q) = %q, want %q", tt.text, got.Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestDemoScript(t *testing.T) {
+	if script := DemoScript(); len(script.Responses) == 0 {
+		t.Error("the demo script has no responses")
+	}
+
+	// Demo conversations get titles and summaries, not echoed prompts
+	m, err := llm.NewManager("fake", llm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := []llm.Message{{Role: "user", Content: "hello"}, {Role: "assistant", Content: "Hello!"}}
+	title, tags, err := m.Title(context.Background(), history)
+	if err != nil || title != "Trying out the demo" || !reflect.DeepEqual(tags, []string{"demo"}) {
+		t.Errorf("Title() = %q, %v, %v, want the scripted title", title, tags, err)
+	}
+	summary, err := m.Summarize(context.Background(), history)
+	if err != nil || strings.Contains(summary.Content, "compacting") {
+		t.Errorf("Summarize() = %q, %v, want the scripted summary", summary.Content, err)
+	}
+}
@@ -0,0 +1,154 @@
+package fake
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed demo.json
+var demoScript []byte
+
+// Script is what a fake provider answers, read from a JSON fixture:
+//
+//	{
+//	  "models": ["fake/echo", "fake/demo"],
+//	  "latency": "300ms",
+//	  "responses": [
+//	    {"match": "hello", "chunks": ["Hi ", "there!"], "chunk_delay": "50ms"},
+//	    {"match": "busy", "error": {"status": 429, "message": "rate limited"}}
+//	  ]
+//	}
+//
+// A message is answered by the first response whose Match it contains as
+// whole words, ignoring case: "go" matches "in Go?" but not "good". One
+// without Match answers everything. Messages no
+// response matches are echoed.
+type Script struct {
+	Models    []string   `json:"models,omitempty"`
+	Latency   Duration   `json:"latency,omitempty"`
+	Responses []Response `json:"responses,omitempty"`
+}
+
+// Response is a scripted answer.
+type Response struct {
+	Match string `json:"match,omitempty"`
+
+	// Content is the answer, streamed as Chunks when those are given
+	Content string   `json:"content,omitempty"`
+	Chunks  []string `json:"chunks,omitempty"`
+	// Echo answers with the message itself
+	Echo bool `json:"echo,omitempty"`
+
+	// Latency replaces the script's delay before the first chunk, and
+	// ChunkDelay is the delay between chunks
+	Latency    *Duration `json:"latency,omitempty"`
+	ChunkDelay Duration  `json:"chunk_delay,omitempty"`
+
+	// Error fails the request with an API error, like a 429
+	Error *Error `json:"error,omitempty"`
+	// TruncateAfter cuts the stream off after that many chunks
+	TruncateAfter int `json:"truncate_after,omitempty"`
+}
+
+// Error is an API error to answer with.
+type Error struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Duration is a time.Duration written as a string like "250ms" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"250ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DemoScript returns the script of the demo mode.
+func DemoScript() Script {
+	var s Script
+	if err := json.Unmarshal(demoScript, &s); err != nil {
+		panic(fmt.Sprintf("invalid demo script: %v", err))
+	}
+	return s
+}
+
+// LoadScript reads a script from the JSON file at path.
+func LoadScript(path string) (Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Script{}, fmt.Errorf("failed to read fake script: %w", err)
+	}
+	var s Script
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Script{}, fmt.Errorf("failed to parse fake script %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// match returns the response to text.
+func (s Script) match(text string) Response {
+	lower := strings.ToLower(text)
+	for _, r := range s.Responses {
+		if containsWords(lower, strings.ToLower(r.Match)) {
+			return r
+		}
+	}
+	return Response{Echo: true}
+}
+
+// containsWords reports whether words occurs in text neither preceded nor
+// followed by a letter or digit.
+func containsWords(text, words string) bool {
+	if words == "" {
+		return true
+	}
+	for start := 0; ; {
+		i := strings.Index(text[start:], words)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(words)
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		start = i + size
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// chunks returns the pieces the response is streamed in.
+func (r Response) chunks(text string) []string {
+	switch {
+	case r.Echo:
+		return []string{text}
+	case len(r.Chunks) > 0:
+		return r.Chunks
+	}
+	return []string{r.Content}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/darling/mana/pkg/llm"
 )
@@ -15,9 +16,14 @@ func init() {
 	llm.Register("openrouter", New)
 }
 
+// DefaultBaseURL is the OpenRouter API endpoint.
+const DefaultBaseURL = "https://openrouter.ai/api/v1"
+
 type Provider struct {
-	key   string
-	model string
+	key     string
+	model   string
+	baseURL string
+	client  *http.Client
 }
 
 type ChatMessage struct {
@@ -26,8 +32,16 @@ type ChatMessage struct {
 }
 
 type ChatCompletionRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Usage       *UsageRequest `json:"usage,omitempty"`
+}
+
+// UsageRequest asks OpenRouter to include token counts and cost in the response
+type UsageRequest struct {
+	Include bool `json:"include"`
 }
 
 type ChatCompletionChoice struct {
@@ -49,9 +63,10 @@ type ChatCompletionResponse struct {
 }
 
 type ResponseUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -64,13 +79,29 @@ func New(cfg llm.Config) (llm.Provider, error) {
 	if cfg.APIKey == "" {
 		return nil, errors.New("API key is required")
 	}
+	baseURL := DefaultBaseURL
+	if cfg.BaseURL != "" {
+		baseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
 	return &Provider{
-		key:   cfg.APIKey,
-		model: cfg.Model,
+		key:     cfg.APIKey,
+		model:   cfg.Model,
+		baseURL: baseURL,
+		client:  client,
 	}, nil
 }
 
-func (p *Provider) Generate(ctx context.Context, history []llm.Message) (llm.Message, error) {
+func (p *Provider) Generate(ctx context.Context, history []llm.Message, opts ...llm.GenerateOption) (llm.Message, error) {
+	options := llm.ApplyOptions(opts...)
+	model := p.model
+	if options.Model != "" {
+		model = options.Model
+	}
+
 	// Convert llm.Message to ChatMessage format
 	messages := make([]ChatMessage, len(history))
 	for i, msg := range history {
@@ -82,8 +113,11 @@ func (p *Provider) Generate(ctx context.Context, history []llm.Message) (llm.Mes
 
 	// Create request payload
 	request := ChatCompletionRequest{
-		Model:    p.model,
-		Messages: messages,
+		Model:       model,
+		Messages:    messages,
+		Temperature: options.Temperature,
+		MaxTokens:   options.MaxTokens,
+		Usage:       &UsageRequest{Include: true},
 	}
 
 	reqBody, err := json.Marshal(request)
@@ -92,7 +126,7 @@ func (p *Provider) Generate(ctx context.Context, history []llm.Message) (llm.Mes
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
 	if err != nil {
 		return llm.Message{}, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -104,8 +138,7 @@ func (p *Provider) Generate(ctx context.Context, history []llm.Message) (llm.Mes
 	req.Header.Set("X-Title", "Mana CLI")
 
 	// Make request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return llm.Message{}, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -139,12 +172,21 @@ func (p *Provider) Generate(ctx context.Context, history []llm.Message) (llm.Mes
 	}
 
 	// Convert response to llm.Message
-	return llm.Message{
+	msg := llm.Message{
 		ID:       chatResp.ID,
 		Provider: "openrouter",
+		Model:    chatResp.Model,
 		Role:     choice.Message.Role,
 		Content:  choice.Message.Content,
-	}, nil
+	}
+	if u := chatResp.Usage; u != nil {
+		msg.Usage = &llm.Usage{
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			Cost:             u.Cost,
+		}
+	}
+	return msg, nil
 }
 
 type modelsResponse struct {
@@ -155,7 +197,7 @@ type modelsResponse struct {
 }
 
 func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://openrouter.ai/api/v1/models", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/models", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -163,8 +205,7 @@ func (p *Provider) ListModels(ctx context.Context) ([]string, error) {
 	req.Header.Set("Authorization", "Bearer "+p.key)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const titlePrompt = `Name the conversation above. Reply with JSON only, in the form
+{"title": "...", "tags": ["..."]}. The title has at most six words and no trailing
+punctuation. Tags are up to three lowercase single words for its topics; leave the
+list empty if none fit.`
+
+// maxTitleLength caps the title in runes, in case the model rambles.
+const maxTitleLength = 80
+
+// maxTags is the most tags Title returns.
+const maxTags = 3
+
+// Title asks the model for a short title and topic tags for history.
+func (m *Manager) Title(ctx context.Context, history []Message, opts ...GenerateOption) (string, []string, error) {
+	if len(history) == 0 {
+		return "", nil, errors.New("nothing to title")
+	}
+
+	request := append(append([]Message(nil), history...), Message{Role: "user", Content: titlePrompt})
+	resp, err := m.Generate(ctx, request, opts...)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to title conversation: %w", err)
+	}
+	title, tags := parseTitle(resp.Content)
+	if title == "" {
+		return "", nil, errors.New("model returned an empty title")
+	}
+	return title, tags, nil
+}
+
+// parseTitle reads the title and tags from a reply. Models do not always
+// stick to JSON, so a reply without it is taken as a bare title.
+func parseTitle(reply string) (string, []string) {
+	var parsed struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags"`
+	}
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start || json.Unmarshal([]byte(reply[start:end+1]), &parsed) != nil {
+		parsed.Title, _, _ = strings.Cut(strings.TrimSpace(reply), "\n")
+		parsed.Tags = nil
+	}
+
+	title := strings.Join(strings.Fields(parsed.Title), " ")
+	title = strings.Trim(title, `"'`+"`")
+	title = strings.TrimRight(title, ".")
+	if r := []rune(title); len(r) > maxTitleLength {
+		title = strings.TrimSpace(string(r[:maxTitleLength-1])) + "…"
+	}
+	return title, NormalizeTags(parsed.Tags, maxTags)
+}
+
+// NormalizeTags lowercases tags, joins words with dashes and drops
+// duplicates and empty tags, keeping at most limit of them when limit > 0.
+func NormalizeTags(tags []string, limit int) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(strings.TrimLeft(tag, "#")), "-"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out
+}
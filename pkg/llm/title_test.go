@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		title string
+		tags  []string
+	}{
+		{"json", `{"title": "Go generics", "tags": ["go", "Generics"]}`, "Go generics", []string{"go", "generics"}},
+		{"fenced json", "```json\n{\"title\": \"Fixing CI.\", \"tags\": []}\n```", "Fixing CI", nil},
+		{"bare title", "\"Weekend plans\"\nbecause you asked", "Weekend plans", nil},
+		{"too many tags", `{"title": "x", "tags": ["a", "#b", "a", "c d", "e"]}`, "x", []string{"a", "b", "c-d"}},
+		{"empty", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, tags := parseTitle(tt.reply)
+			if title != tt.title || !reflect.DeepEqual(tags, tt.tags) {
+				t.Errorf("parseTitle() = %q, %v, want %q, %v", title, tags, tt.title, tt.tags)
+			}
+		})
+	}
+}
+
+func TestManager_Title(t *testing.T) {
+	provider := &stubProvider{reply: Message{Content: `{"title": "Haiku about autumn", "tags": ["poetry"]}`}}
+	m := &Manager{provider: provider}
+
+	title, tags, err := m.Title(context.Background(), []Message{{Role: "user", Content: "write a haiku"}})
+	if err != nil {
+		t.Fatalf("Title() error = %v", err)
+	}
+	if title != "Haiku about autumn" || !reflect.DeepEqual(tags, []string{"poetry"}) {
+		t.Errorf("Title() = %q, %v", title, tags)
+	}
+	if n := len(provider.history); n != 2 {
+		t.Errorf("request length = %d, want 2", n)
+	}
+
+	if _, _, err := m.Title(context.Background(), nil); err == nil {
+		t.Error("Title() of an empty history succeeded")
+	}
+}
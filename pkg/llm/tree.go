@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownParent is returned when adding a reply to a message the tree
+// does not have.
+var ErrUnknownParent = errors.New("unknown parent message")
+
+// Tree holds every message of a conversation, including alternative
+// continuations, linked through ParentID. Messages sharing a parent are
+// branches of each other. Leaf is the last message of the branch shown.
+type Tree struct {
+	Nodes []Message
+	Leaf  string
+}
+
+// NewTree builds a tree from stored messages. Without a leaf the messages
+// are treated as a single linear branch, which is how conversations were
+// stored before branching.
+func NewTree(nodes []Message, leaf string) Tree {
+	t := Tree{Nodes: append([]Message(nil), nodes...), Leaf: leaf}
+	seen := make(map[string]bool, len(t.Nodes))
+	for i := range t.Nodes {
+		if t.Nodes[i].ID == "" || seen[t.Nodes[i].ID] {
+			t.Nodes[i].ID = uuid.NewString()
+		}
+		seen[t.Nodes[i].ID] = true
+		if leaf == "" && i > 0 {
+			t.Nodes[i].ParentID = t.Nodes[i-1].ID
+		}
+	}
+	if leaf == "" && len(t.Nodes) > 0 {
+		t.Leaf = t.Nodes[len(t.Nodes)-1].ID
+	}
+	return t
+}
+
+// Clone returns a copy that does not share nodes with t.
+func (t Tree) Clone() Tree {
+	return Tree{Nodes: append([]Message(nil), t.Nodes...), Leaf: t.Leaf}
+}
+
+// Get returns the message with the given ID.
+func (t Tree) Get(id string) (Message, bool) {
+	for _, msg := range t.Nodes {
+		if msg.ID == id {
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// Path returns the messages from the root to the leaf.
+func (t Tree) Path() []Message {
+	var path []Message
+	for id := t.Leaf; id != ""; {
+		msg, ok := t.Get(id)
+		if !ok {
+			break
+		}
+		path = append(path, msg)
+		id = msg.ParentID
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Children returns the direct replies to parentID in the order they were
+// added. An empty parentID returns the roots.
+func (t Tree) Children(parentID string) []Message {
+	var children []Message
+	for _, msg := range t.Nodes {
+		if msg.ParentID == parentID {
+			children = append(children, msg)
+		}
+	}
+	return children
+}
+
+// Siblings returns the branches the message with id is one of, including
+// itself, and its position among them.
+func (t Tree) Siblings(id string) ([]Message, int) {
+	msg, ok := t.Get(id)
+	if !ok {
+		return nil, -1
+	}
+	siblings := t.Children(msg.ParentID)
+	for i, s := range siblings {
+		if s.ID == id {
+			return siblings, i
+		}
+	}
+	return siblings, -1
+}
+
+// Add appends msg below the leaf and makes it the new leaf.
+func (t *Tree) Add(msg Message) Message {
+	return t.add(t.Leaf, msg)
+}
+
+// AddTo appends msg as a reply to parentID and makes it the new leaf; an
+// empty parentID starts a new root. Adding to a message that already has
+// replies starts a new branch. Messages without a timestamp are stamped
+// with the current time.
+func (t *Tree) AddTo(parentID string, msg Message) (Message, error) {
+	if _, ok := t.Get(parentID); parentID != "" && !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownParent, parentID)
+	}
+	return t.add(parentID, msg), nil
+}
+
+func (t *Tree) add(parentID string, msg Message) Message {
+	if _, exists := t.Get(msg.ID); msg.ID == "" || exists {
+		msg.ID = uuid.NewString()
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	msg.ParentID = parentID
+	t.Nodes = append(t.Nodes, msg)
+	t.Leaf = msg.ID
+	return msg
+}
+
+// SetLeaf makes id the end of the branch shown, dropping any messages after
+// it from the path without removing them from the tree.
+func (t *Tree) SetLeaf(id string) {
+	t.Leaf = id
+}
+
+// SwitchTo shows the branch through id, following the most recent reply
+// at every fork below it.
+func (t *Tree) SwitchTo(id string) {
+	for {
+		children := t.Children(id)
+		if len(children) == 0 {
+			break
+		}
+		id = children[len(children)-1].ID
+	}
+	t.Leaf = id
+}
+
+// Compact replaces the messages of the path before index with summary, as
+// the function Compact does for a history, keeping the rest of the tree:
+// replies to kept messages stay where they are, and the alternatives to the
+// first kept message move below the summary with it. It returns the
+// summarized turns and, separately, the messages of branches that forked
+// from them, which have nothing left to continue from.
+func (t *Tree) Compact(before int, summary Message) (archived, dropped []Message) {
+	path := t.Path()
+	if before <= 0 || len(path) == 0 {
+		return nil, nil
+	}
+	if before > len(path) {
+		before = len(path)
+	}
+	if _, exists := t.Get(summary.ID); summary.ID == "" || exists {
+		summary.ID = uuid.NewString()
+	}
+	boundary := path[before-1].ID
+	kept, archived := Compact(path, before, summary)
+
+	// The pinned messages and the summary become the new root chain.
+	head := kept[:len(kept)-(len(path)-before)]
+	nodes := make([]Message, 0, len(t.Nodes))
+	for i, msg := range head {
+		msg.ParentID = ""
+		if i > 0 {
+			msg.ParentID = head[i-1].ID
+		}
+		nodes = append(nodes, msg)
+	}
+
+	summarized := make(map[string]bool, before)
+	for _, msg := range path[:before] {
+		summarized[msg.ID] = true
+	}
+	keep := make(map[string]bool, len(t.Nodes))
+	for _, msg := range t.Nodes {
+		switch {
+		case summarized[msg.ID]:
+			continue
+		case msg.ParentID == boundary:
+			msg.ParentID = summary.ID
+		case !keep[msg.ParentID]:
+			if msg.Summarizes == 0 {
+				dropped = append(dropped, msg)
+			}
+			continue
+		}
+		keep[msg.ID] = true
+		nodes = append(nodes, msg)
+	}
+
+	t.Nodes = nodes
+	if before == len(path) {
+		t.Leaf = summary.ID
+	}
+	return archived, dropped
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func contents(msgs []Message) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.Content
+	}
+	return out
+}
+
+func TestNewTree_Linear(t *testing.T) {
+	tree := NewTree([]Message{
+		{Role: "user", Content: "a"},
+		{ID: "gen-1", Role: "assistant", Content: "b"},
+		{Role: "user", Content: "c"},
+	}, "")
+
+	if got, want := contents(tree.Path()), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	if tree.Nodes[1].ID != "gen-1" {
+		t.Errorf("existing ID replaced: %q", tree.Nodes[1].ID)
+	}
+	if tree.Nodes[0].ID == "" || tree.Nodes[2].ParentID != "gen-1" {
+		t.Errorf("nodes not linked: %+v", tree.Nodes)
+	}
+}
+
+func TestTree_Branches(t *testing.T) {
+	var tree Tree
+	q := tree.Add(Message{Role: "user", Content: "q"})
+	first := tree.Add(Message{Role: "assistant", Content: "first"})
+
+	// Regenerate: a second reply to the same question
+	tree.SetLeaf(q.ID)
+	second := tree.Add(Message{Role: "assistant", Content: "second"})
+
+	if got, want := contents(tree.Path()), []string{"q", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	siblings, index := tree.Siblings(second.ID)
+	if len(siblings) != 2 || index != 1 {
+		t.Errorf("Siblings() = %d, %d, want 2, 1", len(siblings), index)
+	}
+
+	// Continue on the first branch, then switch back to it from the top
+	tree.SwitchTo(first.ID)
+	tree.Add(Message{Role: "user", Content: "more"})
+	tree.SwitchTo(second.ID)
+	if got, want := contents(tree.Path()), []string{"q", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() after switch = %v, want %v", got, want)
+	}
+	tree.SwitchTo(q.ID)
+	if got, want := contents(tree.Path()), []string{"q", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SwitchTo(root) path = %v, want latest branch %v", got, want)
+	}
+	tree.SwitchTo(first.ID)
+	if got, want := contents(tree.Path()), []string{"q", "first", "more"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+
+	// Editing the first message makes a second root
+	if _, err := tree.AddTo("", Message{Role: "user", Content: "q2"}); err != nil {
+		t.Fatalf("AddTo(\"\") error = %v", err)
+	}
+	if roots := tree.Children(""); len(roots) != 2 {
+		t.Errorf("Children(\"\") = %d roots, want 2", len(roots))
+	}
+
+	leaf := tree.Leaf
+	if _, err := tree.AddTo("missing", Message{Role: "assistant", Content: "lost"}); !errors.Is(err, ErrUnknownParent) {
+		t.Errorf("AddTo(missing) error = %v, want ErrUnknownParent", err)
+	}
+	if tree.Leaf != leaf {
+		t.Error("AddTo(missing) moved the leaf")
+	}
+}
+
+func TestTree_AddDuplicateID(t *testing.T) {
+	var tree Tree
+	a := tree.Add(Message{ID: "same", Content: "a"})
+	b := tree.Add(Message{ID: "same", Content: "b"})
+	if a.ID == b.ID {
+		t.Errorf("duplicate ID %q was kept", b.ID)
+	}
+}
+
+func TestTree_Compact(t *testing.T) {
+	var tree Tree
+	q1 := tree.Add(Message{Role: "user", Content: "q1"})
+	tree.Add(Message{Role: "assistant", Content: "old a1"})
+	tree.SetLeaf(q1.ID)
+	a1 := tree.Add(Message{Role: "assistant", Content: "a1"})
+	tree.Add(Message{Role: "user", Content: "other q2"})
+	tree.SetLeaf(a1.ID)
+	q2 := tree.Add(Message{Role: "user", Content: "q2"})
+	tree.Add(Message{Role: "assistant", Content: "old a2"})
+	tree.SetLeaf(q2.ID)
+	tree.Add(Message{Role: "assistant", Content: "a2"})
+
+	summary := Message{Role: "system", Content: "summary", Pinned: true, Summarizes: 2}
+	archived, dropped := tree.Compact(2, summary)
+
+	if got, want := contents(tree.Path()), []string{"summary", "q2", "a2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	if got, want := contents(archived), []string{"q1", "a1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("archived = %v, want %v", got, want)
+	}
+	if got, want := contents(dropped), []string{"old a1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dropped = %v, want %v", got, want)
+	}
+	siblings, _ := tree.Siblings(q2.ID)
+	if got, want := contents(siblings), []string{"other q2", "q2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("siblings of q2 = %v, want %v", got, want)
+	}
+	replies := tree.Children(q2.ID)
+	if got, want := contents(replies), []string{"old a2", "a2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replies to q2 = %v, want %v", got, want)
+	}
+}
+
+func TestTree_CompactAll(t *testing.T) {
+	var tree Tree
+	tree.Add(Message{Role: "user", Content: "q"})
+	tree.Add(Message{Role: "assistant", Content: "a"})
+
+	archived, dropped := tree.Compact(2, Message{Role: "system", Content: "summary", Pinned: true})
+
+	if got, want := contents(tree.Path()), []string{"summary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %v, want %v", got, want)
+	}
+	if len(archived) != 2 || len(dropped) != 0 {
+		t.Errorf("archived %d and dropped %d messages, want 2 and 0", len(archived), len(dropped))
+	}
+}
@@ -0,0 +1,102 @@
+// Package markdown has small helpers for working with the markdown in
+// model responses without a full parser.
+package markdown
+
+import "strings"
+
+// CodeBlock is a fenced code block inside a markdown document.
+type CodeBlock struct {
+	Lang string
+	Code string
+	// Start and End are the byte offsets of the block in the source,
+	// from the opening fence to the end of the closing fence line.
+	Start, End int
+}
+
+// CodeBlocks returns the fenced code blocks in src in order. Both backtick
+// and tilde fences are recognized. An unterminated block runs to the end
+// of the document, as in CommonMark.
+func CodeBlocks(src string) []CodeBlock {
+	var blocks []CodeBlock
+	var (
+		open  bool
+		fence string
+		block CodeBlock
+		code  strings.Builder
+	)
+
+	offset := 0
+	for offset < len(src) {
+		end := strings.IndexByte(src[offset:], '\n')
+		next := len(src)
+		if end >= 0 {
+			next = offset + end + 1
+		}
+		line := strings.TrimRight(src[offset:next], "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		switch {
+		case !open && indent < 4:
+			if f := fenceOf(trimmed); f != "" {
+				open = true
+				fence = f
+				block = CodeBlock{Lang: langOf(trimmed[len(f):]), Start: offset}
+				code.Reset()
+			}
+		case open && indent < 4 && isClosing(trimmed, fence):
+			open = false
+			block.Code = code.String()
+			block.End = next
+			blocks = append(blocks, block)
+		case open:
+			code.WriteString(line)
+			code.WriteByte('\n')
+		}
+		offset = next
+	}
+
+	if open {
+		block.Code = code.String()
+		block.End = len(src)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// fenceOf returns the opening fence at the start of line, or "".
+func fenceOf(line string) string {
+	for _, c := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == c {
+			n++
+		}
+		if n < 3 {
+			continue
+		}
+		// Backtick fences cannot have backticks in their info string
+		if c == '`' && strings.ContainsRune(line[n:], '`') {
+			return ""
+		}
+		return line[:n]
+	}
+	return ""
+}
+
+// isClosing reports whether line closes a block opened with fence.
+func isClosing(line, fence string) bool {
+	n := 0
+	for n < len(line) && line[n] == fence[0] {
+		n++
+	}
+	return n >= len(fence) && strings.TrimSpace(line[n:]) == ""
+}
+
+// langOf returns the language from a fence info string.
+func langOf(info string) string {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
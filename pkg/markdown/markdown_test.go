@@ -0,0 +1,60 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []CodeBlock
+	}{
+		{
+			name: "none",
+			src:  "just text\n",
+			want: nil,
+		},
+		{
+			name: "single with language",
+			src:  "before\n```go\nfmt.Println()\n```\nafter\n",
+			want: []CodeBlock{{Lang: "go", Code: "fmt.Println()\n", Start: 7, End: 31}},
+		},
+		{
+			name: "tilde fence keeps backticks",
+			src:  "~~~\n```\n~~~",
+			want: []CodeBlock{{Code: "```\n", Start: 0, End: 11}},
+		},
+		{
+			name: "longer closing fence",
+			src:  "```sh\nls\n`````\n",
+			want: []CodeBlock{{Lang: "sh", Code: "ls\n", Start: 0, End: 15}},
+		},
+		{
+			name: "unterminated",
+			src:  "```\na\nb",
+			want: []CodeBlock{{Code: "a\nb\n", Start: 0, End: 7}},
+		},
+		{
+			name: "two blocks",
+			src:  "```a\n1\n```\n\n```b\n2\n```\n",
+			want: []CodeBlock{
+				{Lang: "a", Code: "1\n", Start: 0, End: 11},
+				{Lang: "b", Code: "2\n", Start: 12, End: 23},
+			},
+		},
+		{
+			name: "inline backticks are not a fence",
+			src:  "``` not `a` fence\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeBlocks(tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CodeBlocks() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Package panes persists how the panes of the TUI are arranged between runs.
+package panes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/darling/mana/pkg/config"
+)
+
+// FileName is the name of the layout file inside the config directory.
+const FileName = "layout.json"
+
+// Names of the panes that can be maximized.
+const (
+	Sidebar = "sidebar"
+	Main    = "main"
+)
+
+// Layout is the arrangement of the panes. The zero value is the default
+// arrangement: a sidebar a quarter of the window wide, panes of equal height.
+type Layout struct {
+	path string
+
+	// SidebarWidth is the width of the sidebar in columns; zero means a
+	// quarter of the window.
+	SidebarWidth int `json:"sidebar_width,omitempty"`
+	// SidebarCollapsed hides the sidebar.
+	SidebarCollapsed bool `json:"sidebar_collapsed,omitempty"`
+	// Maximized names the pane taking the whole window, if any.
+	Maximized string `json:"maximized,omitempty"`
+	// SidebarWeights are the relative heights of the panes in the sidebar,
+	// from the top. Missing weights count as 1.
+	SidebarWeights []int `json:"sidebar_weights,omitempty"`
+}
+
+// DefaultPath returns the layout file location in the config directory.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Load reads the layout at path. A missing or undecodable file yields the
+// default layout, which replaces it on the next Save. An empty path keeps
+// the layout in memory only.
+func Load(path string) (*Layout, error) {
+	l := &Layout{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return &Layout{path: path}, nil
+	}
+	return l, nil
+}
+
+// Weight returns the weight of the sidebar pane at index.
+func (l Layout) Weight(index int) int {
+	if index < len(l.SidebarWeights) && l.SidebarWeights[index] > 0 {
+		return l.SidebarWeights[index]
+	}
+	return 1
+}
+
+// Save writes the layout to disk.
+func (l Layout) Save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to encode layout: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create layout directory: %w", err)
+	}
+	// Write to a temporary file first so a crash never leaves a truncated layout.
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write layout: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("failed to write layout: %w", err)
+	}
+	return nil
+}
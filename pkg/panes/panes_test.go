@@ -0,0 +1,64 @@
+package panes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLayout_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(*l, Layout{path: path}) {
+		t.Errorf("Load() of a missing file = %+v, want the default layout", *l)
+	}
+	l.SidebarWidth = 30
+	l.Maximized = Main
+	l.SidebarWeights = []int{3, 1}
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, l) {
+		t.Errorf("Load() = %+v, want %+v", loaded, l)
+	}
+}
+
+func TestLayout_LoadUndecodable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(`{"sidebar_width": "wide"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want the default layout", err)
+	}
+	if !reflect.DeepEqual(*l, Layout{path: path}) {
+		t.Errorf("Load() = %+v, want the default layout", *l)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Save() left the temporary file behind: %v", err)
+	}
+}
+
+func TestLayout_Weight(t *testing.T) {
+	l := Layout{SidebarWeights: []int{3, 0}}
+	for i, want := range []int{3, 1, 1} {
+		if got := l.Weight(i); got != want {
+			t.Errorf("Weight(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
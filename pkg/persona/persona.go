@@ -0,0 +1,124 @@
+package persona
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+// DefaultName is the persona used when none is selected.
+const DefaultName = "default"
+
+// Persona is a named system prompt with default model parameters.
+type Persona struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	System      string   `json:"system,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// Options returns the generation options implied by the persona.
+func (p Persona) Options() []llm.GenerateOption {
+	var opts []llm.GenerateOption
+	if p.Model != "" {
+		opts = append(opts, llm.WithModel(p.Model))
+	}
+	if p.Temperature != nil {
+		opts = append(opts, llm.WithTemperature(*p.Temperature))
+	}
+	if p.MaxTokens > 0 {
+		opts = append(opts, llm.WithMaxTokens(p.MaxTokens))
+	}
+	return opts
+}
+
+// Apply prepends the persona's system prompt to history.
+func (p Persona) Apply(history []llm.Message) []llm.Message {
+	if p.System == "" {
+		return history
+	}
+	return append([]llm.Message{{Role: "system", Content: p.System, Pinned: true}}, history...)
+}
+
+// Library is a set of personas keyed by name.
+type Library struct {
+	personas map[string]Persona
+}
+
+// NewLibrary returns a library holding the default persona and the given ones.
+func NewLibrary(personas ...Persona) *Library {
+	l := &Library{personas: map[string]Persona{
+		DefaultName: {Name: DefaultName, Description: "No system prompt"},
+	}}
+	for _, p := range personas {
+		l.personas[p.Name] = p
+	}
+	return l
+}
+
+// Load reads every *.json persona file in dirs. Directories that do not exist
+// are skipped, and personas in later directories override earlier ones.
+func Load(dirs ...string) (*Library, error) {
+	l := NewLibrary()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read persona directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			p, err := loadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			l.personas[p.Name] = p
+		}
+	}
+	return l, nil
+}
+
+func loadFile(path string) (Persona, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Persona{}, fmt.Errorf("failed to read persona: %w", err)
+	}
+	var p Persona
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Persona{}, fmt.Errorf("failed to decode persona %s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// Get returns the persona with the given name.
+func (l *Library) Get(name string) (Persona, bool) {
+	p, ok := l.personas[name]
+	return p, ok
+}
+
+// Names returns all persona names, with the default persona first.
+func (l *Library) Names() []string {
+	names := make([]string, 0, len(l.personas))
+	for name := range l.personas {
+		if name != DefaultName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultName}, names...)
+}
@@ -0,0 +1,67 @@
+package persona
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	user := filepath.Join(root, "user")
+	project := filepath.Join(root, "project")
+
+	writeFile(t, user, "reviewer.json", `{"system": "Review code.", "model": "a/b", "temperature": 0.2}`)
+	writeFile(t, user, "notes.txt", `ignored`)
+	writeFile(t, project, "reviewer.json", `{"system": "Review Go code."}`)
+	writeFile(t, project, "tutor.json", `{"name": "teacher", "system": "Teach."}`)
+
+	lib, err := Load(user, project, filepath.Join(root, "missing"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got, want := lib.Names(), []string{DefaultName, "reviewer", "teacher"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	reviewer, ok := lib.Get("reviewer")
+	if !ok {
+		t.Fatal("Get(reviewer) not found")
+	}
+	if reviewer.System != "Review Go code." || reviewer.Model != "" {
+		t.Errorf("reviewer = %+v, want project override", reviewer)
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "broken.json", `{`)
+
+	if _, err := Load(dir); err == nil {
+		t.Error("Load() error = nil, want decode error")
+	}
+}
+
+func TestPersona_Apply(t *testing.T) {
+	p := Persona{Name: "x", System: "Be brief."}
+	history := p.Apply(nil)
+	if len(history) != 1 || history[0].Role != "system" || history[0].Content != "Be brief." {
+		t.Errorf("Apply() = %+v, want system prompt", history)
+	}
+
+	if got := (Persona{Name: DefaultName}).Apply(nil); len(got) != 0 {
+		t.Errorf("Apply() without system prompt = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,337 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+// indexFile holds the search index inside the store directory. It has no
+// .json extension so List does not mistake it for a conversation.
+const indexFile = "search.idx"
+
+// indexVersion is bumped whenever tokenization changes, which forces the
+// index to be rebuilt.
+const indexVersion = 2
+
+// conversationHits is the most hits a single conversation contributes to a
+// search, so that one long conversation does not crowd out the others.
+const conversationHits = 5
+
+// snippetContext is how many runes of context a snippet shows around a match.
+const snippetContext = 40
+
+// Hit is a stored message matching a search. Message is empty when only the
+// conversation's title matched.
+type Hit struct {
+	Conversation Conversation
+	Message      llm.Message
+	Snippet      string
+}
+
+// indexEntry is what the index knows about a single conversation. A
+// conversation that cannot be read is indexed without terms until its file
+// changes.
+type indexEntry struct {
+	ModTime   time.Time `json:"mod_time"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Terms     []string  `json:"terms"`
+}
+
+// newIndexEntry indexes c, whose file was last modified at modTime.
+func newIndexEntry(c Conversation, modTime time.Time) indexEntry {
+	return indexEntry{ModTime: modTime, UpdatedAt: c.UpdatedAt, Terms: conversationTerms(c)}
+}
+
+// index maps terms to the conversations containing them. Only the entries
+// are persisted; postings are rebuilt when the index is loaded.
+type index struct {
+	Version  int                   `json:"version"`
+	Entries  map[string]indexEntry `json:"entries"`
+	postings map[string]map[string]bool
+	// terms are the keys of postings in order, for finding those with a
+	// prefix; nil after terms were added or removed until the next search.
+	terms []string
+	dirty bool
+}
+
+func newIndex() *index {
+	return &index{Version: indexVersion, Entries: make(map[string]indexEntry), postings: make(map[string]map[string]bool)}
+}
+
+func (ix *index) add(id string, entry indexEntry) {
+	ix.remove(id)
+	ix.Entries[id] = entry
+	for _, term := range entry.Terms {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]bool)
+			ix.terms = nil
+		}
+		ix.postings[term][id] = true
+	}
+	ix.dirty = true
+}
+
+func (ix *index) remove(id string) {
+	entry, ok := ix.Entries[id]
+	if !ok {
+		return
+	}
+	for _, term := range entry.Terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.terms = nil
+		}
+	}
+	delete(ix.Entries, id)
+	ix.dirty = true
+}
+
+// candidates returns the conversations having, for every query term, a
+// term starting with it.
+func (ix *index) candidates(query []string) map[string]bool {
+	if ix.terms == nil {
+		ix.terms = make([]string, 0, len(ix.postings))
+		for term := range ix.postings {
+			ix.terms = append(ix.terms, term)
+		}
+		sort.Strings(ix.terms)
+	}
+
+	var result map[string]bool
+	for _, q := range query {
+		matching := make(map[string]bool)
+		for i := sort.SearchStrings(ix.terms, q); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], q); i++ {
+			for id := range ix.postings[ix.terms[i]] {
+				matching[id] = true
+			}
+		}
+		if result == nil {
+			result = matching
+			continue
+		}
+		for id := range result {
+			if !matching[id] {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// tokenize splits text into distinct lowercase terms of two or more characters.
+func tokenize(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(field)) < 2 || seen[field] {
+			continue
+		}
+		seen[field] = true
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+// conversationTerms returns the terms of the title and every message,
+// including other branches.
+func conversationTerms(c Conversation) []string {
+	var b strings.Builder
+	b.WriteString(c.Title)
+	for _, tag := range c.Tags {
+		b.WriteString(" ")
+		b.WriteString(tag)
+	}
+	for _, msg := range c.Messages {
+		b.WriteString("\n")
+		b.WriteString(msg.Content)
+	}
+	return tokenize(b.String())
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, indexFile)
+}
+
+// loadIndex reads the persisted index, starting over if it is missing,
+// unreadable or from an older version.
+func (s *Store) loadIndex() *index {
+	ix := newIndex()
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return ix
+	}
+	var stored index
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != indexVersion {
+		return ix
+	}
+	for id, entry := range stored.Entries {
+		ix.add(id, entry)
+	}
+	ix.dirty = false
+	return ix
+}
+
+// syncIndex brings the index up to date with the conversations on disk,
+// reindexing only those changed since they were last indexed. Files that
+// cannot be read are left out of the results. The caller must hold s.mu.
+func (s *Store) syncIndex() error {
+	if s.index == nil {
+		s.index = s.loadIndex()
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read store directory: %w", err)
+	}
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".json")
+		seen[id] = true
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if indexed, ok := s.index.Entries[id]; ok && indexed.ModTime.Equal(info.ModTime()) {
+			continue
+		}
+		c, err := s.load(id)
+		if err != nil {
+			s.index.add(id, indexEntry{ModTime: info.ModTime()})
+			continue
+		}
+		s.index.add(id, newIndexEntry(c, info.ModTime()))
+	}
+	for id := range s.index.Entries {
+		if !seen[id] {
+			s.index.remove(id)
+		}
+	}
+
+	if !s.index.dirty {
+		return nil
+	}
+	data, err := json.Marshal(s.index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	if err := os.WriteFile(s.indexPath(), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	s.index.dirty = false
+	return nil
+}
+
+// updateIndex reindexes a conversation that was just saved. The caller must
+// hold s.mu. Before the first search there is no index to keep up to date.
+func (s *Store) updateIndex(c Conversation) {
+	if s.index == nil {
+		return
+	}
+	info, err := os.Stat(s.path(c.ID))
+	if err != nil {
+		return
+	}
+	s.index.add(c.ID, newIndexEntry(c, info.ModTime()))
+}
+
+// Search finds messages containing every word of query, or a word starting
+// with it, most recently updated conversations first. At most limit hits
+// are returned; zero means no limit. A conversation contributes at most
+// conversationHits of them. Conversations are ranked from the
+// index and only read until the limit is reached; unreadable ones are
+// skipped.
+func (s *Store) Search(query string, limit int) ([]Hit, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.syncIndex(); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for id := range s.index.candidates(terms) {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.index.Entries[ids[i]].UpdatedAt.After(s.index.Entries[ids[j]].UpdatedAt)
+	})
+
+	patterns := make([]*regexp.Regexp, len(terms))
+	for i, term := range terms {
+		patterns[i] = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	}
+	var hits []Hit
+	for _, id := range ids {
+		c, err := s.load(id)
+		if err != nil {
+			continue
+		}
+		found := 0
+		for _, msg := range c.Messages {
+			if found == conversationHits {
+				break
+			}
+			if snippet, ok := matchSnippet(msg.Content, patterns); ok {
+				hits = append(hits, Hit{Conversation: c, Message: msg, Snippet: snippet})
+				found++
+			}
+		}
+		if found == 0 {
+			if snippet, ok := matchSnippet(c.Title, patterns); ok {
+				hits = append(hits, Hit{Conversation: c, Snippet: snippet})
+			}
+		}
+		if limit > 0 && len(hits) >= limit {
+			return hits[:limit], nil
+		}
+	}
+	return hits, nil
+}
+
+// matchSnippet reports whether text matches every pattern and returns the
+// text around the first match on a single line.
+func matchSnippet(text string, patterns []*regexp.Regexp) (string, bool) {
+	first := -1
+	for _, p := range patterns {
+		loc := p.FindStringIndex(text)
+		if loc == nil {
+			return "", false
+		}
+		if first < 0 {
+			first = loc[0]
+		}
+	}
+
+	before := []rune(text[:first])
+	after := []rune(text[first:])
+	start := max(0, len(before)-snippetContext)
+	end := min(len(after), 2*snippetContext)
+	snippet := strings.Join(strings.Fields(string(before[start:])+string(after[:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(after) {
+		snippet += "…"
+	}
+	return snippet, true
+}
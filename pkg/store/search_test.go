@@ -0,0 +1,171 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+func saveConversation(t *testing.T, s *Store, title string, updated time.Time, contents ...string) Conversation {
+	t.Helper()
+	c := NewConversation()
+	c.Title = title
+	c.UpdatedAt = updated
+	for _, content := range contents {
+		c.Messages = append(c.Messages, llm.Message{ID: content, Role: "user", Content: content})
+	}
+	if err := s.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return c
+}
+
+func TestStore_Search(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	now := time.Now()
+	older := saveConversation(t, s, "", now.Add(-time.Hour), "How do goroutines leak?", "unrelated")
+	newer := saveConversation(t, s, "", now, "Goroutine scheduling in depth")
+	titled := saveConversation(t, s, "Kubernetes notes", now.Add(-2*time.Hour), "pods")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string // conversation IDs of the hits in order
+	}{
+		{"prefix matches every form", "goroutine", []string{newer.ID, older.ID}},
+		{"all words required", "goroutines leak", []string{older.ID}},
+		{"case insensitive", "KUBERNETES", []string{titled.ID}},
+		{"no match", "rust", nil},
+		{"empty", "  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := s.Search(tt.query, 0)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var got []string
+			for _, h := range hits {
+				got = append(got, h.Conversation.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+
+	hits, _ := s.Search("leak", 0)
+	if len(hits) != 1 || hits[0].Message.ID != "How do goroutines leak?" || hits[0].Snippet != "How do goroutines leak?" {
+		t.Errorf("Search(leak) = %+v, want the matching message", hits)
+	}
+}
+
+func TestStore_SearchIndexUpdates(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	c := saveConversation(t, s, "", time.Now(), "first draft")
+	if hits, _ := s.Search("draft", 0); len(hits) != 1 {
+		t.Fatalf("Search(draft) = %d hits, want 1", len(hits))
+	}
+
+	// Saving after the index was built reindexes the conversation
+	c.Messages = []llm.Message{{Role: "user", Content: "final version"}}
+	if err := s.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if hits, _ := s.Search("draft", 0); len(hits) != 0 {
+		t.Errorf("Search(draft) after edit = %d hits, want 0", len(hits))
+	}
+
+	// A new store reads the persisted index and picks up files it missed
+	other := saveConversation(t, s, "", time.Now(), "another version")
+	if _, err := os.Stat(s.indexPath()); err != nil {
+		t.Fatalf("index not written: %v", err)
+	}
+	reopened, _ := Open(dir)
+	if hits, _ := reopened.Search("version", 0); len(hits) != 2 {
+		t.Errorf("Search(version) after reopen = %d hits, want 2", len(hits))
+	}
+
+	if err := reopened.Delete(other.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if hits, _ := reopened.Search("another", 0); len(hits) != 0 {
+		t.Errorf("Search(another) after delete = %d hits, want 0", len(hits))
+	}
+}
+
+func TestStore_SearchHitsPerConversation(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	var contents []string
+	for i := range conversationHits + 3 {
+		contents = append(contents, fmt.Sprintf("channel question %d", i))
+	}
+	long := saveConversation(t, s, "", time.Now(), contents...)
+	short := saveConversation(t, s, "", time.Now().Add(-time.Hour), "channels again")
+
+	hits, err := s.Search("channel", conversationHits+1)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	counts := make(map[string]int)
+	for _, h := range hits {
+		counts[h.Conversation.ID]++
+	}
+	if counts[long.ID] != conversationHits || counts[short.ID] != 1 {
+		t.Errorf("Search(channel) hits per conversation = %v, want %d and 1", counts, conversationHits)
+	}
+}
+
+func TestStore_SearchSkipsCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	now := time.Now()
+	newest := saveConversation(t, s, "", now, "shared words")
+	saveConversation(t, s, "", now.Add(-time.Hour), "shared words")
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, err := s.Search("shared", 1)
+	if err != nil {
+		t.Fatalf("Search() error = %v, want the corrupt file skipped", err)
+	}
+	if len(hits) != 1 || hits[0].Conversation.ID != newest.ID {
+		t.Errorf("Search(shared, 1) = %+v, want the newest conversation", hits)
+	}
+}
+
+func TestMatchSnippet(t *testing.T) {
+	long := "lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor needle incididunt ut labore et dolore magna aliqua ut enim ad minim veniam quis nostrud"
+	snippet, ok := matchSnippet(long, []*regexp.Regexp{regexp.MustCompile("(?i)needle")})
+	if !ok {
+		t.Fatal("matchSnippet() found no match")
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "needle") {
+		t.Errorf("matchSnippet() = %q, want elided context around needle", snippet)
+	}
+}
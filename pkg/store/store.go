@@ -0,0 +1,263 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/darling/mana/pkg/config"
+	"github.com/darling/mana/pkg/llm"
+)
+
+// ErrNotFound is returned when a conversation does not exist in the store.
+var ErrNotFound = errors.New("conversation not found")
+
+// SkippedError reports the conversation files List could not read. The
+// conversations returned along with it are still complete and usable.
+type SkippedError struct {
+	Errs []error
+}
+
+func (e *SkippedError) Error() string {
+	if len(e.Errs) == 1 {
+		return "skipped an unreadable conversation: " + e.Errs[0].Error()
+	}
+	return fmt.Sprintf("skipped %d unreadable conversations: %v", len(e.Errs), errors.Join(e.Errs...))
+}
+
+func (e *SkippedError) Unwrap() []error {
+	return e.Errs
+}
+
+// ErrInvalidID is returned for an ID that cannot name a conversation file.
+var ErrInvalidID = errors.New("invalid conversation ID")
+
+// Conversation is a single persisted chat session.
+type Conversation struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title,omitempty"`
+	Persona string   `json:"persona,omitempty"`
+	System  string   `json:"system,omitempty"` // overrides the persona's system prompt
+	Model   string   `json:"model,omitempty"`  // overrides the persona's model
+	Tags    []string `json:"tags,omitempty"`
+	// Pinned conversations are listed first; archived ones are hidden from
+	// the list unless asked for.
+	Pinned     bool      `json:"pinned,omitempty"`
+	ArchivedAt time.Time `json:"archived_at,omitzero"`
+	// Messages holds every message including alternative branches, linked
+	// by ParentID. Leaf is the last message of the branch shown; without it
+	// the messages form a single branch in order.
+	Messages  []llm.Message `json:"messages"`
+	Leaf      string        `json:"leaf,omitempty"`
+	Archived  []llm.Message `json:"archived,omitempty"` // turns replaced by a compaction summary
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// NewConversation returns an empty conversation with a fresh ID.
+func NewConversation() Conversation {
+	now := time.Now()
+	return Conversation{
+		ID:        uuid.NewString(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Store persists conversations as one JSON file each inside a directory.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	// index is loaded on the first search and kept up to date afterwards.
+	index *index
+}
+
+// DefaultDir returns the directory conversations are stored in by default.
+func DefaultDir() (string, error) {
+	base, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "conversations"), nil
+}
+
+// Open returns a store rooted at dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Dir returns the directory backing the store.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// ValidID reports whether id can name a conversation file: it must not be
+// empty or reach outside the store's directory.
+func ValidID(id string) bool {
+	return id != "" && id != "." && !strings.ContainsAny(id, `/\`) && !strings.Contains(id, "..")
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save writes the conversation to disk, replacing any previous version.
+func (s *Store) Save(c Conversation) error {
+	if c.ID == "" {
+		return errors.New("conversation ID is required")
+	}
+	if !ValidID(c.ID) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, c.ID)
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = time.Now()
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temporary file first so a crash never leaves a truncated conversation.
+	tmp := s.path(c.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(c.ID)); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	s.updateIndex(c)
+	return nil
+}
+
+// Load reads a conversation by ID.
+func (s *Store) Load(id string) (Conversation, error) {
+	if !ValidID(id) {
+		return Conversation{}, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+func (s *Store) load(id string) (Conversation, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Conversation{}, ErrNotFound
+	}
+	if err != nil {
+		return Conversation{}, fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Conversation{}, fmt.Errorf("failed to decode conversation %s: %w", id, err)
+	}
+	return c, nil
+}
+
+// List returns all stored conversations, most recently updated first.
+// Files that cannot be read are skipped and reported by a *SkippedError
+// returned with the others.
+func (s *Store) List() ([]Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store directory: %w", err)
+	}
+
+	var conversations []Conversation
+	var skipped []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		c, err := s.load(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		conversations = append(conversations, c)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	if len(skipped) > 0 {
+		return conversations, &SkippedError{Errs: skipped}
+	}
+	return conversations, nil
+}
+
+// Delete removes a conversation from the store.
+func (s *Store) Delete(id string) error {
+	if !ValidID(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err == nil && s.index != nil {
+		s.index.remove(id)
+	}
+	return err
+}
+
+// Find looks up a conversation by ID, a unique ID prefix, its title
+// (ignoring case) or "latest" for the most recently updated one.
+func (s *Store) Find(ref string) (Conversation, error) {
+	if ref == "" {
+		return Conversation{}, errors.New("no conversation given")
+	}
+	if ValidID(ref) {
+		if c, err := s.Load(ref); err == nil || !errors.Is(err, ErrNotFound) {
+			return c, err
+		}
+	}
+	conversations, err := s.List()
+	var skipped *SkippedError
+	if err != nil && !errors.As(err, &skipped) {
+		return Conversation{}, err
+	}
+	if ref == "latest" {
+		if len(conversations) == 0 {
+			return Conversation{}, ErrNotFound
+		}
+		return conversations[0], nil
+	}
+
+	var matches []Conversation
+	for _, c := range conversations {
+		if strings.HasPrefix(c.ID, ref) || strings.EqualFold(c.Title, ref) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Conversation{}, ErrNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return Conversation{}, fmt.Errorf("%q matches %d conversations, give more of the ID", ref, len(matches))
+}
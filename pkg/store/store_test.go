@@ -0,0 +1,176 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	c := NewConversation()
+	c.Title = "debugging"
+	c.Messages = []llm.Message{{Role: "user", Content: "hi"}}
+	c.Archived = []llm.Message{{Role: "assistant", Content: "old"}}
+
+	if err := s.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := s.Load(c.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Title != c.Title || len(got.Messages) != 1 || len(got.Archived) != 1 {
+		t.Errorf("Load() = %+v, want %+v", got, c)
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	older := NewConversation()
+	older.UpdatedAt = time.Now().Add(-time.Hour)
+	newer := NewConversation()
+
+	for _, c := range []Conversation{older, newer} {
+		if err := s.Save(c); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List() length = %d, want 2", len(list))
+	}
+	if list[0].ID != newer.ID {
+		t.Errorf("List()[0] = %s, want most recent %s", list[0].ID, newer.ID)
+	}
+}
+
+func TestStore_ListSkipsCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	c := NewConversation()
+	c.Title = "Readable"
+	if err := s.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.List()
+	var skipped *SkippedError
+	if !errors.As(err, &skipped) || len(skipped.Errs) != 1 {
+		t.Fatalf("List() error = %v, want one skipped file", err)
+	}
+	if len(list) != 1 || list[0].ID != c.ID {
+		t.Errorf("List() = %d conversations, want the readable one", len(list))
+	}
+	if found, err := s.Find("latest"); err != nil || found.ID != c.ID {
+		t.Errorf("Find(latest) = %s, %v, want the readable conversation", found.ID, err)
+	}
+}
+
+func TestStore_Delete(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	c := NewConversation()
+	if err := s.Save(c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := s.Delete(c.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Load(c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStore_Find(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	older := Conversation{ID: "abc-1", Title: "Release notes", UpdatedAt: time.Now().Add(-time.Hour)}
+	newer := Conversation{ID: "abd-2", UpdatedAt: time.Now()}
+	for _, c := range []Conversation{older, newer} {
+		if err := s.Save(c); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "abc-1", want: "abc-1"},
+		{ref: "abd", want: "abd-2"},
+		{ref: "release NOTES", want: "abc-1"},
+		{ref: "latest", want: "abd-2"},
+		{ref: "ab", wantErr: true},
+		{ref: "zzz", wantErr: true},
+		{ref: "../abc-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			c, err := s.Find(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Find(%q) = %s, want error", tt.ref, c.ID)
+				}
+				return
+			}
+			if err != nil || c.ID != tt.want {
+				t.Errorf("Find(%q) = %s, %v, want %s", tt.ref, c.ID, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_InvalidID(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, id := range []string{"../escaped", "a/b", `a\b`, "..", "."} {
+		if err := s.Save(Conversation{ID: id}); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Save(%q) error = %v, want ErrInvalidID", id, err)
+		}
+		if _, err := s.Load(id); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Load(%q) error = %v, want ErrInvalidID", id, err)
+		}
+		if err := s.Delete(id); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidID", id, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a conversation was written outside the store: %v", err)
+	}
+}
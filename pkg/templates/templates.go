@@ -0,0 +1,239 @@
+package templates
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Ext is the file extension prompt templates are loaded from.
+const Ext = ".tmpl"
+
+// Input describes where the value of a variable comes from.
+type Input int
+
+const (
+	// InputValue takes the value as given.
+	InputValue Input = iota
+	// InputStdin reads the value from standard input when it is not given.
+	InputStdin
+	// InputFile treats the given value as a path and uses the file contents.
+	InputFile
+)
+
+// Var is a named template variable.
+type Var struct {
+	Name     string
+	Default  string
+	Input    Input
+	Required bool
+}
+
+// Template is a prompt written as a Go text/template with declared variables.
+//
+// Variables are declared in an optional front matter block:
+//
+//	---
+//	description: Review a diff for concurrency bugs
+//	var: lang = go
+//	var: diff < stdin
+//	var: code < file
+//	var: focus
+//	---
+//	Review this {{.lang}} diff for concurrency bugs: {{.diff}}
+//
+// "name = value" sets a default, "name < stdin" and "name < file" select the
+// input source and a bare name is required.
+type Template struct {
+	Name        string
+	Description string
+	Vars        []Var
+
+	tmpl *template.Template
+}
+
+// Parse parses a template source.
+func Parse(name, src string) (Template, error) {
+	t := Template{Name: name}
+
+	body, err := t.parseFrontMatter(src)
+	if err != nil {
+		return Template{}, fmt.Errorf("template %s: %w", name, err)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return Template{}, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.tmpl = tmpl
+	return t, nil
+}
+
+func (t *Template) parseFrontMatter(src string) (string, error) {
+	if !strings.HasPrefix(src, "---\n") {
+		return src, nil
+	}
+	rest := src[len("---\n"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return "", errors.New("unterminated front matter")
+	}
+	header, body := rest[:end], rest[end+len("\n---"):]
+	body = strings.TrimPrefix(body, "\n")
+
+	scanner := bufio.NewScanner(strings.NewReader(header))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return "", fmt.Errorf("invalid front matter line %q", line)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "description":
+			t.Description = value
+		case "var":
+			v, err := parseVar(value)
+			if err != nil {
+				return "", err
+			}
+			t.Vars = append(t.Vars, v)
+		default:
+			return "", fmt.Errorf("unknown front matter key %q", key)
+		}
+	}
+	return body, nil
+}
+
+func parseVar(decl string) (Var, error) {
+	if name, def, ok := strings.Cut(decl, "="); ok {
+		return Var{Name: strings.TrimSpace(name), Default: strings.TrimSpace(def)}, validName(name)
+	}
+	if name, source, ok := strings.Cut(decl, "<"); ok {
+		v := Var{Name: strings.TrimSpace(name), Required: true}
+		switch strings.TrimSpace(source) {
+		case "stdin":
+			v.Input = InputStdin
+		case "file":
+			v.Input = InputFile
+		default:
+			return Var{}, fmt.Errorf("unknown input %q for variable %q", source, v.Name)
+		}
+		return v, validName(name)
+	}
+	return Var{Name: decl, Required: true}, validName(decl)
+}
+
+func validName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	return nil
+}
+
+// Resolve fills in defaults and reads stdin and file inputs. stdin may be nil
+// when standard input is not available, in which case stdin variables must be
+// given explicitly.
+func (t Template) Resolve(values map[string]string, stdin io.Reader) (map[string]string, error) {
+	resolved := make(map[string]string, len(t.Vars))
+	for k, v := range values {
+		resolved[k] = v
+	}
+
+	for _, v := range t.Vars {
+		value, given := resolved[v.Name]
+		switch {
+		case v.Input == InputFile && given && value != "":
+			data, err := os.ReadFile(value)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", v.Name, err)
+			}
+			value = string(data)
+		case v.Input == InputStdin && (!given || value == "") && stdin != nil:
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: failed to read stdin: %w", v.Name, err)
+			}
+			value, given = string(data), true
+			stdin = nil // stdin can only be consumed once
+		case !given:
+			value = v.Default
+		}
+
+		if v.Required && value == "" {
+			return nil, fmt.Errorf("variable %s is required", v.Name)
+		}
+		resolved[v.Name] = value
+	}
+	return resolved, nil
+}
+
+// Execute renders the template with already resolved values.
+func (t Template) Execute(values map[string]string) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, values); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", t.Name, err)
+	}
+	return b.String(), nil
+}
+
+// Library is a set of templates keyed by name.
+type Library struct {
+	templates map[string]Template
+}
+
+// Load reads every template file in dirs. Directories that do not exist are
+// skipped, and templates in later directories override earlier ones.
+func Load(dirs ...string) (*Library, error) {
+	l := &Library{templates: make(map[string]Template)}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != Ext {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template: %w", err)
+			}
+			t, err := Parse(strings.TrimSuffix(entry.Name(), Ext), string(data))
+			if err != nil {
+				return nil, err
+			}
+			l.templates[t.Name] = t
+		}
+	}
+	return l, nil
+}
+
+// Get returns the template with the given name.
+func (l *Library) Get(name string) (Template, bool) {
+	t, ok := l.templates[name]
+	return t, ok
+}
+
+// Names returns all template names in sorted order.
+func (l *Library) Names() []string {
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
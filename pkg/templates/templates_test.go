@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const reviewSrc = `---
+description: Review a diff
+var: lang = go
+var: diff < stdin
+var: focus
+---
+Review this {{.lang}} diff for {{.focus}}:
+{{.diff}}`
+
+func TestParse(t *testing.T) {
+	tmpl, err := Parse("review", reviewSrc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if tmpl.Description != "Review a diff" {
+		t.Errorf("Description = %q", tmpl.Description)
+	}
+	want := []Var{
+		{Name: "lang", Default: "go"},
+		{Name: "diff", Input: InputStdin, Required: true},
+		{Name: "focus", Required: true},
+	}
+	if !reflect.DeepEqual(tmpl.Vars, want) {
+		t.Errorf("Vars = %+v, want %+v", tmpl.Vars, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "unterminated front matter", src: "---\nvar: a\n"},
+		{name: "unknown key", src: "---\nmodel: x\n---\nbody"},
+		{name: "unknown input", src: "---\nvar: a < socket\n---\nbody"},
+		{name: "invalid template", src: "{{.a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse("t", tt.src); err == nil {
+				t.Error("Parse() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestTemplate_ResolveExecute(t *testing.T) {
+	tmpl, err := Parse("review", reviewSrc)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	values, err := tmpl.Resolve(map[string]string{"focus": "races"}, strings.NewReader("+x := 1"))
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	got, err := tmpl.Execute(values)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "Review this go diff for races:\n+x := 1"; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+
+	if _, err := tmpl.Resolve(map[string]string{"focus": "races"}, nil); err == nil {
+		t.Error("Resolve() without stdin error = nil, want required error")
+	}
+}
+
+func TestTemplate_ResolveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := Parse("explain", "---\nvar: code < file\n---\n{{.code}}")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	values, err := tmpl.Resolve(map[string]string{"code": path}, nil)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if values["code"] != "package main" {
+		t.Errorf("code = %q, want file contents", values["code"])
+	}
+}
+
+func TestLoad(t *testing.T) {
+	user, project := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(user, "review.tmpl"):    "user review",
+		filepath.Join(user, "explain.tmpl"):   "explain",
+		filepath.Join(project, "review.tmpl"): "project review",
+		filepath.Join(project, "README.md"):   "ignored",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lib, err := Load(user, project)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := lib.Names(), []string{"explain", "review"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	review, _ := lib.Get("review")
+	if out, _ := review.Execute(nil); out != "project review" {
+		t.Errorf("review = %q, want project override", out)
+	}
+}
@@ -4,13 +4,12 @@ import (
 	"log"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
-	"github.com/darling/mana/pkg/llm"
 
 	"github.com/darling/mana/pkg/tui/core"
 )
 
-func Run(manager *llm.Manager) error {
-	root := core.NewRootCmp(manager)
+func Run(opts core.Options) error {
+	root := core.NewRootCmp(opts)
 
 	p := tea.NewProgram(
 		root,
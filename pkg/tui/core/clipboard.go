@@ -0,0 +1,67 @@
+package core
+
+import (
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// CopiedMsg is delivered after text was copied to the clipboard.
+// What describes the copied item for the status line.
+type CopiedMsg struct {
+	What string
+	Err  error
+}
+
+// copyCmd copies text with an OSC 52 escape sequence, which also works over
+// SSH, and through the system clipboard when one is available. Err is only
+// set when neither could be used.
+func copyCmd(text, what string) tea.Cmd {
+	return tea.Batch(
+		tea.SetClipboard(text),
+		func() tea.Msg {
+			if clipboard.Unsupported {
+				return CopiedMsg{What: what}
+			}
+			// OSC 52 was sent either way, so only report a failure when
+			// the terminal is unlikely to support it.
+			err := clipboard.WriteAll(text)
+			if err != nil && !osc52Likely() {
+				return CopiedMsg{What: what, Err: err}
+			}
+			return CopiedMsg{What: what}
+		},
+	)
+}
+
+// osc52Likely guesses whether the terminal handles OSC 52 clipboard writes.
+func osc52Likely() bool {
+	return os.Getenv("TERM") != "linux"
+}
+
+// plainText turns rendered markdown back into text suitable for pasting:
+// escape sequences are removed, along with the margin the renderer adds.
+func plainText(rendered string) string {
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	indent := -1
+	for i, line := range lines {
+		line = strings.TrimRight(line, " ")
+		lines[i] = line
+		if line == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
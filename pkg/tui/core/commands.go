@@ -0,0 +1,237 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/export"
+	"github.com/darling/mana/pkg/persona"
+	"github.com/darling/mana/pkg/templates"
+	"github.com/darling/mana/pkg/tui/core/commands"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// Messages produced by slash commands. They are all handled by the main view.
+type (
+	// SetModelMsg overrides the model for the current conversation
+	SetModelMsg struct{ Model string }
+	// SetPersonaMsg switches the persona of the current conversation
+	SetPersonaMsg struct{ Name string }
+	// SetSystemPromptMsg overrides the persona's system prompt; empty restores it
+	SetSystemPromptMsg struct{ Prompt string }
+	// NewConversationMsg starts a new conversation, keeping the persona if Persona is empty
+	NewConversationMsg struct{ Persona string }
+	// AttachFileMsg attaches a file's contents to the next prompt
+	AttachFileMsg struct{ Path string }
+	// RetryMsg regenerates the last response
+	RetryMsg struct{}
+	// OpenTemplateMsg starts filling in a prompt template
+	OpenTemplateMsg struct{ Name string }
+	// CompareMsg asks several models to answer the last message side by side
+	CompareMsg struct{ Models []string }
+)
+
+// ModelsLoadedMsg carries the models offered by the provider.
+type ModelsLoadedMsg struct {
+	Models []string
+	Err    error
+}
+
+// modelCatalog is shared with command completions so they see models loaded
+// after the registry was built.
+type modelCatalog struct {
+	models []string
+}
+
+// newCommandRegistry builds the slash commands available in the prompt dialog.
+func newCommandRegistry(personas *persona.Library, tmpls *templates.Library, models *modelCatalog) *commands.Registry {
+	r := commands.NewRegistry()
+
+	r.Register(commands.Command{
+		Name:        "model",
+		Args:        "[model]",
+		Description: "switch model for this conversation",
+		Complete:    func(string) []string { return models.models },
+		Run: func(args string) (tea.Msg, error) {
+			if args == "" {
+				if len(models.models) == 0 {
+					return nil, errors.New("no models loaded yet, give a model name")
+				}
+				return layout.ShowSelectDialogMsg{ID: selectModel, Title: "Switch model", Options: models.models}, nil
+			}
+			return SetModelMsg{Model: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "persona",
+		Args:        "<name>",
+		Description: "switch persona",
+		Complete:    func(string) []string { return personas.Names() },
+		Run: func(args string) (tea.Msg, error) {
+			if _, ok := personas.Get(args); !ok {
+				return nil, errors.New("unknown persona")
+			}
+			return SetPersonaMsg{Name: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "system",
+		Args:        "[prompt]",
+		Description: "set the system prompt, or restore the persona's",
+		Run: func(args string) (tea.Msg, error) {
+			return SetSystemPromptMsg{Prompt: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "clear",
+		Description: "start over with the same persona",
+		Run: func(string) (tea.Msg, error) {
+			return NewConversationMsg{}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "new",
+		Args:        "[persona]",
+		Description: "start a new conversation",
+		Complete:    func(string) []string { return personas.Names() },
+		Run: func(args string) (tea.Msg, error) {
+			if args != "" {
+				if _, ok := personas.Get(args); !ok {
+					return nil, errors.New("unknown persona")
+				}
+			}
+			return NewConversationMsg{Persona: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "attach",
+		Args:        "<path>",
+		Description: "attach a file to the next message",
+		Complete:    completePath,
+		Run: func(args string) (tea.Msg, error) {
+			if args == "" {
+				return nil, errors.New("path is required")
+			}
+			info, err := os.Stat(args)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				return nil, errors.New("cannot attach a directory")
+			}
+			return AttachFileMsg{Path: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "retry",
+		Description: "regenerate the last response",
+		Run: func(string) (tea.Msg, error) {
+			return RetryMsg{}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "compact",
+		Args:        "[keep]",
+		Description: "summarize all but the last messages",
+		Run: func(args string) (tea.Msg, error) {
+			keep := compactKeep
+			if args != "" {
+				n, err := strconv.Atoi(args)
+				if err != nil || n < 0 {
+					return nil, errors.New("keep must be a non-negative number")
+				}
+				keep = n
+			}
+			return CompactMsg{Keep: keep}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "tmpl",
+		Args:        "<template>",
+		Description: "fill in a prompt template",
+		Complete:    func(string) []string { return tmpls.Names() },
+		Run: func(args string) (tea.Msg, error) {
+			if _, ok := tmpls.Get(args); !ok {
+				return nil, errors.New("unknown template")
+			}
+			return OpenTemplateMsg{Name: args}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "compare",
+		Args:        "<model> <model>...",
+		Description: "answer the last message with several models side by side",
+		Complete:    completeModels(models),
+		Run: func(args string) (tea.Msg, error) {
+			fields := strings.Fields(args)
+			if len(fields) < 2 {
+				return nil, errors.New("give at least two models")
+			}
+			return CompareMsg{Models: fields}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "export",
+		Args:        "[md|html|json|jsonl] [path]",
+		Description: "export this conversation to a file",
+		Complete: func(string) []string {
+			formats := make([]string, len(export.Formats))
+			for i, f := range export.Formats {
+				formats[i] = string(f)
+			}
+			return formats
+		},
+		Run: func(args string) (tea.Msg, error) {
+			name, path, _ := strings.Cut(strings.TrimSpace(args), " ")
+			if name == "" {
+				name = string(export.Markdown)
+			}
+			format, err := export.ParseFormat(name)
+			if err != nil {
+				return nil, err
+			}
+			return ExportMsg{Format: format, Path: strings.TrimSpace(path)}, nil
+		},
+	})
+	r.Register(commands.Command{
+		Name:        "search",
+		Args:        "[query]",
+		Description: "search all conversations",
+		Run: func(args string) (tea.Msg, error) {
+			return ShowSearchMsg{Query: args}, nil
+		},
+	})
+
+	return r
+}
+
+// completeModels completes the last of several space separated models.
+func completeModels(models *modelCatalog) func(string) []string {
+	return func(arg string) []string {
+		prefix := ""
+		if i := strings.LastIndex(arg, " "); i >= 0 {
+			prefix = arg[:i+1]
+		}
+		candidates := make([]string, len(models.models))
+		for i, model := range models.models {
+			candidates[i] = prefix + model
+		}
+		return candidates
+	}
+}
+
+// completePath suggests files and directories starting with arg.
+func completePath(arg string) []string {
+	matches, _ := filepath.Glob(arg + "*")
+	for i, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			matches[i] = match + string(filepath.Separator)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// Prefix marks input as a slash command rather than a prompt.
+const Prefix = "/"
+
+// ErrEmpty is returned when the input contains only the command prefix.
+var ErrEmpty = errors.New("no command given")
+
+// Command is a slash command that can be typed in place of a prompt.
+type Command struct {
+	Name        string
+	Args        string // argument hint, e.g. "<model>"
+	Description string
+
+	// Complete returns candidate values for the argument being typed. It is optional.
+	Complete func(arg string) []string
+
+	// Run turns the argument string into a message dispatched to the root component.
+	Run func(args string) (tea.Msg, error)
+}
+
+// Usage returns the command with its argument hint, e.g. "/model <model>".
+func (c Command) Usage() string {
+	if c.Args == "" {
+		return Prefix + c.Name
+	}
+	return Prefix + c.Name + " " + c.Args
+}
+
+// Registry holds the available slash commands.
+type Registry struct {
+	commands map[string]Command
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]Command)}
+}
+
+// Register adds a command. It panics if the name is already taken.
+func (r *Registry) Register(c Command) {
+	if _, exists := r.commands[c.Name]; exists {
+		panic(fmt.Sprintf("command %q already registered", c.Name))
+	}
+	r.commands[c.Name] = c
+}
+
+// Lookup returns the command with the given name.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// All returns every command sorted by name.
+func (r *Registry) All() []Command {
+	return r.Matching("")
+}
+
+// Matching returns the commands whose name starts with prefix, sorted by name.
+func (r *Registry) Matching(prefix string) []Command {
+	var matches []Command
+	for name, c := range r.commands {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
+
+// IsCommand reports whether input should be treated as a slash command.
+// Input starting with a doubled prefix is an escaped message; see Literal.
+func IsCommand(input string) bool {
+	trimmed := strings.TrimSpace(input)
+	return strings.HasPrefix(trimmed, Prefix) && !strings.HasPrefix(trimmed, Prefix+Prefix)
+}
+
+// Literal reports whether input starts with a doubled prefix, as in
+// "//etc/hosts", and returns it with the first prefix removed, to be sent
+// as a message instead of run as a command.
+func Literal(input string) (string, bool) {
+	i := strings.Index(input, Prefix+Prefix)
+	if i < 0 || strings.TrimSpace(input[:i]) != "" {
+		return input, false
+	}
+	return input[:i] + input[i+len(Prefix):], true
+}
+
+// Split separates input into the command name and its argument string.
+func Split(input string) (name, args string) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), Prefix)
+	name, args, _ = strings.Cut(input, " ")
+	return name, strings.TrimSpace(args)
+}
+
+// Execute parses input and runs the matching command.
+func (r *Registry) Execute(input string) (tea.Msg, error) {
+	name, args := Split(input)
+	if name == "" {
+		return nil, ErrEmpty
+	}
+	c, ok := r.commands[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %s%s", Prefix, name)
+	}
+	msg, err := c.Run(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.Usage(), err)
+	}
+	return msg, nil
+}
+
+// Suggestion is a completion candidate for partially typed input.
+type Suggestion struct {
+	// Value replaces the whole input when the suggestion is accepted.
+	Value string
+	// Hint describes the suggestion, e.g. the command usage and description.
+	Hint string
+}
+
+// Suggest returns completions for partially typed input. While the command
+// name is being typed it suggests commands; afterwards it suggests arguments.
+func (r *Registry) Suggest(input string) []Suggestion {
+	if !IsCommand(input) {
+		return nil
+	}
+	trimmed := strings.TrimLeft(input, " ")
+	name, args := Split(trimmed)
+
+	if !strings.Contains(trimmed, " ") {
+		var suggestions []Suggestion
+		for _, c := range r.Matching(name) {
+			suggestions = append(suggestions, Suggestion{
+				Value: Prefix + c.Name + " ",
+				Hint:  c.Usage() + "  " + c.Description,
+			})
+		}
+		return suggestions
+	}
+
+	c, ok := r.commands[name]
+	if !ok || c.Complete == nil {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, candidate := range c.Complete(args) {
+		if strings.HasPrefix(candidate, args) {
+			suggestions = append(suggestions, Suggestion{Value: Prefix + c.Name + " " + candidate, Hint: candidate})
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+type setModelMsg struct{ model string }
+
+func newTestRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(Command{
+		Name:        "model",
+		Args:        "<model>",
+		Description: "switch model",
+		Complete: func(arg string) []string {
+			return []string{"openai/gpt-4o", "anthropic/claude"}
+		},
+		Run: func(args string) (tea.Msg, error) {
+			if args == "" {
+				return nil, errors.New("model is required")
+			}
+			return setModelMsg{model: args}, nil
+		},
+	})
+	r.Register(Command{
+		Name:        "clear",
+		Description: "clear the conversation",
+		Run:         func(args string) (tea.Msg, error) { return "cleared", nil },
+	})
+	return r
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	r := newTestRegistry()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    tea.Msg
+		wantErr bool
+	}{
+		{name: "with argument", input: "/model  openai/gpt-4o ", want: setModelMsg{model: "openai/gpt-4o"}},
+		{name: "without argument", input: "/clear", want: "cleared"},
+		{name: "missing argument", input: "/model", wantErr: true},
+		{name: "unknown command", input: "/nope", wantErr: true},
+		{name: "empty command", input: "/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Execute(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_Suggest(t *testing.T) {
+	r := newTestRegistry()
+
+	values := func(suggestions []Suggestion) []string {
+		var out []string
+		for _, s := range suggestions {
+			out = append(out, s.Value)
+		}
+		return out
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "hello", want: nil},
+		{input: "/", want: []string{"/clear ", "/model "}},
+		{input: "/mo", want: []string{"/model "}},
+		{input: "/model open", want: []string{"/model openai/gpt-4o"}},
+		{input: "/clear x", want: nil},
+		{input: "//mo", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := values(r.Suggest(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{input: "//etc/hosts is empty", want: "/etc/hosts is empty", ok: true},
+		{input: "  //model", want: "  /model", ok: true},
+		{input: "/model", want: "/model"},
+		{input: "see //comment", want: "see //comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Literal(tt.input)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Literal(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+			if tt.ok && IsCommand(tt.input) {
+				t.Errorf("IsCommand(%q) = true for an escaped slash", tt.input)
+			}
+		})
+	}
+}
+
+func TestRegistry_RegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() did not panic on duplicate name")
+		}
+	}()
+	r := newTestRegistry()
+	r.Register(Command{Name: "clear"})
+}
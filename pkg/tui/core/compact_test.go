@@ -0,0 +1,119 @@
+package core
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+)
+
+func TestMainCmp_CompactToSelection(t *testing.T) {
+	m := newMainTestCmp(t)
+	m, _ = updateMain(t, m, tea.KeyPressMsg{Code: 'v', Text: "v"})
+	m, _ = updateMain(t, m, tea.KeyPressMsg{Code: 'k', Text: "k"})
+	m, _ = updateMain(t, m, tea.KeyPressMsg{Code: 'k', Text: "k"})
+	m, cmd := updateMain(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+	if cmd == nil || m.selecting {
+		t.Fatalf("x in selection mode did not compact (selecting = %v)", m.selecting)
+	}
+	msg, ok := cmd().(CompactedMsg)
+	if !ok || msg.Before != 2 || msg.LeafID != "a1" {
+		t.Fatalf("compacted %+v, want the messages up to the selected answer", msg)
+	}
+
+	m, _ = updateMain(t, m, msg)
+	var ids []string
+	for _, msg := range m.messages {
+		ids = append(ids, msg.ID)
+	}
+	if len(m.messages) != 3 || m.messages[0].Summarizes != 2 || ids[1] != "q2" || ids[2] != "a2" {
+		t.Errorf("messages after compacting = %v, want a summary of two then q2 and a2", ids)
+	}
+}
+
+func TestMainCmp_CompactedStale(t *testing.T) {
+	m := newMainTestCmp(t)
+	summary := llm.Message{ID: "s", Role: "system", Content: "summary", Summarizes: 2}
+
+	tests := []struct {
+		name string
+		msg  CompactedMsg
+	}{
+		{"other conversation", CompactedMsg{ConversationID: "other", LeafID: "a1", Summary: summary, Before: 2}},
+		{"branch changed", CompactedMsg{ConversationID: m.conversation.ID, LeafID: "gone", Summary: summary, Before: 2}},
+		{"history shrank", CompactedMsg{ConversationID: m.conversation.ID, LeafID: "a1", Summary: summary, Before: 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := m
+			m.compacting = true
+			m, _ = updateMain(t, m, tt.msg)
+			if len(m.messages) != 4 || m.messages[0].ID != "q1" {
+				t.Errorf("a stale summary changed the conversation: %d messages", len(m.messages))
+			}
+		})
+	}
+
+	m.compacting = true
+	m, _ = updateMain(t, m, CompactedMsg{ConversationID: m.conversation.ID, LeafID: "gone", Summary: summary, Before: 2})
+	if m.compacting {
+		t.Error("a stale summary left the conversation compacting")
+	}
+}
+
+func TestMainCmp_CompactKeepsBranches(t *testing.T) {
+	m := newMainTestCmp(t)
+	m = m.setTree(llm.NewTree([]llm.Message{
+		{ID: "q1", Role: "user", Content: "first question"},
+		{ID: "old", ParentID: "q1", Role: "assistant", Content: "old answer"},
+		{ID: "a1", ParentID: "q1", Role: "assistant", Content: "first answer"},
+		{ID: "q2b", ParentID: "a1", Role: "user", Content: "other question"},
+		{ID: "q2", ParentID: "a1", Role: "user", Content: "second question"},
+		{ID: "a2", ParentID: "q2", Role: "assistant", Content: "second answer"},
+	}, "a2"))
+	summary := llm.Message{ID: "s", Role: "system", Content: "summary", Pinned: true, Summarizes: 2}
+
+	m, cmd := updateMain(t, m, CompactedMsg{ConversationID: m.conversation.ID, LeafID: "a1", Summary: summary, Before: 2})
+	if cmd == nil {
+		t.Fatal("compacting did not save the conversation")
+	}
+	siblings, _ := m.tree.Siblings("q2")
+	if len(siblings) != 2 || siblings[0].ID != "q2b" || siblings[0].ParentID != "s" {
+		t.Errorf("siblings of q2 = %+v, want the other question below the summary", siblings)
+	}
+	if _, ok := m.tree.Get("old"); ok || !containsID(m.archived, "old") {
+		t.Error("the branch of a summarized turn was not archived")
+	}
+}
+
+func TestMainCmp_AutoCompact(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		want      bool
+	}{
+		{"off by default", 0, false},
+		{"above threshold", 1, true},
+		{"below threshold", 1 << 20, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMainTestCmp(t)
+			m.compactThreshold = tt.threshold
+			m, _ = updateMain(t, m, ChatResponseMsg{ConversationID: m.conversation.ID, ParentID: "a2", Message: llm.Message{Content: "answer"}})
+			if m.compacting != tt.want {
+				t.Errorf("compacting = %v, want %v", m.compacting, tt.want)
+			}
+		})
+	}
+}
+
+func containsID(msgs []llm.Message, id string) bool {
+	for _, msg := range msgs {
+		if msg.ID == id {
+			return true
+		}
+	}
+	return false
+}
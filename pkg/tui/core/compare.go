@@ -0,0 +1,307 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/viewport"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/glamour/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// compareWeight is the default share of the width given to each column.
+const compareWeight = 4
+
+// ShowCompareMsg requests sending History to every model in Models and
+// showing the answers side by side. ParentID is the message they answer.
+type ShowCompareMsg struct {
+	Models   []string
+	History  []llm.Message
+	ParentID string
+	Options  []llm.GenerateOption
+}
+
+// ComparePickedMsg is emitted when an answer was picked in the compare view.
+// Answers holds every successful answer in column order; Picked indexes it.
+type ComparePickedMsg struct {
+	ParentID string
+	Answers  []llm.Message
+	Picked   int
+}
+
+// compareResultMsg delivers one model's answer to the compare view.
+type compareResultMsg struct {
+	llm.Result
+	results <-chan llm.Result
+}
+
+// waitForResult reads the next answer from results; nil once all arrived.
+func waitForResult(results <-chan llm.Result) tea.Cmd {
+	return func() tea.Msg {
+		r, ok := <-results
+		if !ok {
+			return nil
+		}
+		return compareResultMsg{Result: r, results: results}
+	}
+}
+
+// compareColumn is one model's answer.
+type compareColumn struct {
+	model    string
+	result   *llm.Result
+	vp       viewport.Model
+	renderer *glamour.TermRenderer
+	weight   int
+}
+
+// CompareCmp is a full screen layer showing several models' answers to the
+// same history next to each other.
+type CompareCmp struct {
+	focused bool
+	width   int
+	height  int
+
+	parentID string
+	columns  []compareColumn
+	active   int
+	results  <-chan llm.Result
+	cancel   context.CancelFunc
+	keys     compareKeyMap
+	styles   styles
+}
+
+// NewCompareCmp starts the requests and returns the layer showing them.
+// Init must be run to start receiving answers.
+func NewCompareCmp(manager *llm.Manager, msg ShowCompareMsg) *CompareCmp {
+	c := &CompareCmp{parentID: msg.ParentID, keys: DefaultCompareKeyMap, styles: defaultStyles}
+	for _, model := range msg.Models {
+		c.columns = append(c.columns, compareColumn{model: model, weight: compareWeight})
+	}
+	if manager != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		c.results = manager.Compare(ctx, msg.History, msg.Models, msg.Options...)
+	}
+	return c
+}
+
+func (c *CompareCmp) Init() tea.Cmd {
+	if c.results == nil {
+		return nil
+	}
+	return waitForResult(c.results)
+}
+
+func (c *CompareCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case compareResultMsg:
+		if msg.Index >= 0 && msg.Index < len(c.columns) {
+			r := msg.Result
+			c.columns[msg.Index].result = &r
+			c.renderColumn(msg.Index)
+		}
+		return c, waitForResult(msg.results)
+	case ThemeChangedMsg:
+		// Rebuilds the renderers in the new glamour style
+		c.styles = newStyles(msg.Theme)
+		c.layout()
+		return c, nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keys.Prev):
+			c.active = (c.active - 1 + len(c.columns)) % max(1, len(c.columns))
+		case key.Matches(msg, c.keys.Next):
+			c.active = (c.active + 1) % max(1, len(c.columns))
+		case key.Matches(msg, c.keys.Narrow):
+			if c.active < len(c.columns) && c.columns[c.active].weight > 1 {
+				c.columns[c.active].weight--
+				c.layout()
+			}
+		case key.Matches(msg, c.keys.Widen):
+			if c.active < len(c.columns) {
+				c.columns[c.active].weight++
+				c.layout()
+			}
+		case key.Matches(msg, c.keys.Reset):
+			for i := range c.columns {
+				c.columns[i].weight = compareWeight
+			}
+			c.layout()
+		case key.Matches(msg, c.keys.Pick):
+			return c, c.pick()
+		case key.Matches(msg, c.keys.Cancel):
+			c.stop()
+			return c, func() tea.Msg { return layout.CancelledMsg{} }
+		default:
+			if c.active < len(c.columns) {
+				var cmd tea.Cmd
+				c.columns[c.active].vp, cmd = c.columns[c.active].vp.Update(msg)
+				return c, cmd
+			}
+		}
+	}
+	return c, nil
+}
+
+// pick continues the conversation with the focused column's answer. The
+// other answers are kept as sibling branches.
+func (c *CompareCmp) pick() tea.Cmd {
+	if c.active >= len(c.columns) {
+		return nil
+	}
+	if r := c.columns[c.active].result; r == nil || r.Err != nil {
+		return nil
+	}
+	c.stop()
+	picked := ComparePickedMsg{ParentID: c.parentID}
+	for i, col := range c.columns {
+		if col.result == nil || col.result.Err != nil {
+			continue
+		}
+		if i == c.active {
+			picked.Picked = len(picked.Answers)
+		}
+		picked.Answers = append(picked.Answers, col.result.Message)
+	}
+	return func() tea.Msg { return picked }
+}
+
+// stop cancels the requests still running.
+func (c *CompareCmp) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// columnWidths splits the width between the columns by weight.
+func (c *CompareCmp) columnWidths() []int {
+	sizes := make([]layout.Size, len(c.columns))
+	for i, col := range c.columns {
+		sizes[i] = layout.Flex(col.weight)
+	}
+	return layout.Split(c.width, sizes...)
+}
+
+// columnInner returns the content size of a column of the given width.
+func (c *CompareCmp) columnInner(width int) (int, int) {
+	// Same chrome as the main view; see MainCmp.innerDimensions
+	s := c.styles.FocusedBox
+	innerW := max(1, width-s.GetHorizontalPadding()-s.GetHorizontalFrameSize())
+	// Two header rows: the model and its stats
+	innerH := max(1, c.height-s.GetVerticalPadding()-s.GetVerticalFrameSize()-2)
+	return innerW, innerH
+}
+
+// layout resizes every column to its share of the width.
+func (c *CompareCmp) layout() {
+	for i, width := range c.columnWidths() {
+		innerW, innerH := c.columnInner(width)
+		col := &c.columns[i]
+		offset := col.vp.YOffset
+		col.vp = viewport.New(viewport.WithWidth(innerW), viewport.WithHeight(innerH))
+		col.renderer = newMarkdownRenderer(c.styles.Markdown, innerW)
+		c.renderColumn(i)
+		col.vp.SetYOffset(offset)
+	}
+}
+
+// renderColumn renders a column's answer the way the main view renders
+// messages.
+func (c *CompareCmp) renderColumn(i int) {
+	col := &c.columns[i]
+	if col.result == nil || col.result.Err != nil {
+		col.vp.SetContent("")
+		return
+	}
+	col.vp.SetContent(renderMarkdown(col.renderer, col.result.Message.Content, col.vp.Width()))
+}
+
+func (c *CompareCmp) View() string {
+	widths := c.columnWidths()
+	views := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		style := c.styles.BlurredBox
+		if i == c.active {
+			style = c.styles.FocusedBox
+		}
+		innerW, innerH := c.columnInner(widths[i])
+		title := col.model
+		if i == c.active {
+			title = c.styles.SelectedMessage.Render(title)
+		}
+		header := lipgloss.NewStyle().MaxWidth(innerW).Render(title) + "\n" +
+			c.styles.ConversationHeader.MaxWidth(innerW).Render(compareStats(col.result))
+		body := lipgloss.NewStyle().Width(innerW).Height(innerH).MaxHeight(innerH).Render(col.vp.View())
+		if col.result != nil && col.result.Err != nil {
+			body = lipgloss.NewStyle().Width(innerW).Height(innerH).Render(c.styles.ErrorText.Render(hardWrap(col.result.Err.Error(), innerW)))
+		}
+		views[i] = style.Width(widths[i]).Height(c.height).MaxHeight(c.height).Render(header + "\n" + body)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
+
+// compareStats summarizes latency, tokens and cost of an answer.
+func compareStats(r *llm.Result) string {
+	switch {
+	case r == nil:
+		return "waiting…"
+	case r.Err != nil:
+		return "failed after " + formatLatency(r.Latency)
+	}
+	parts := []string{formatLatency(r.Latency)}
+	if u := r.Message.Usage; u != nil {
+		parts = append(parts, fmt.Sprintf("%d→%d tok", u.PromptTokens, u.CompletionTokens))
+		if u.Cost > 0 {
+			parts = append(parts, fmt.Sprintf("$%.4f", u.Cost))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+func (c *CompareCmp) SetSize(width, height int) tea.Cmd {
+	// Leave the status bar visible for the key help
+	c.width, c.height = width, max(1, height-1)
+	c.layout()
+	return nil
+}
+
+func (c *CompareCmp) GetSize() (int, int) { return c.width, c.height }
+
+func (c *CompareCmp) SetFocused(focused bool) (layout.FocusScope, tea.Cmd) {
+	c.focused = focused
+	return c, nil
+}
+
+func (c *CompareCmp) IsFocused() bool { return c.focused }
+
+func (c *CompareCmp) Clone() layout.FocusScope {
+	clone := *c
+	clone.columns = append([]compareColumn(nil), c.columns...)
+	return &clone
+}
+
+func (c *CompareCmp) Bindings() []key.Binding {
+	return []key.Binding{c.keys.Prev, c.keys.Next, c.keys.Narrow, c.keys.Widen, c.keys.Reset, c.keys.Pick, c.keys.Cancel}
+}
+
+func (c *CompareCmp) LayerMeta() layout.LayerMeta {
+	return layout.LayerMeta{
+		ID:          "compare",
+		Z:           150,
+		Modal:       true,
+		CaptureKeys: true,
+		Pos:         layout.Position{Anchor: layout.TopLeft},
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/tui/core/theme"
+)
+
+func TestCompareCmp_ColumnWidths(t *testing.T) {
+	c := NewCompareCmp(nil, ShowCompareMsg{Models: []string{"a", "b", "c"}})
+	c.SetSize(100, 20)
+	c.Update(tea.KeyPressMsg{Code: '>', Text: ">"})
+
+	widths := c.columnWidths()
+	if widths[0] <= widths[1] {
+		t.Errorf("widened column is %d wide, others %d", widths[0], widths[1])
+	}
+	if total := widths[0] + widths[1] + widths[2]; total != 100 {
+		t.Errorf("columns take %d columns, want 100", total)
+	}
+}
+
+func TestCompareCmp_Pick(t *testing.T) {
+	c := NewCompareCmp(nil, ShowCompareMsg{Models: []string{"a", "bad", "c"}, ParentID: "q"})
+	c.SetSize(90, 20)
+	c.Update(compareResultMsg{Result: llm.Result{Index: 0, Model: "a", Message: llm.Message{Content: "from a"}}})
+	c.Update(compareResultMsg{Result: llm.Result{Index: 1, Model: "bad", Err: errors.New("unavailable")}})
+	c.Update(compareResultMsg{Result: llm.Result{Index: 2, Model: "c", Message: llm.Message{Content: "from c"}}})
+
+	// Move to the last column and pick it
+	c.Update(tea.KeyPressMsg{Code: 'h', Text: "h"})
+	_, cmd := c.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter did not pick an answer")
+	}
+	picked, ok := cmd().(ComparePickedMsg)
+	if !ok {
+		t.Fatalf("got %T, want ComparePickedMsg", cmd())
+	}
+	if picked.ParentID != "q" || len(picked.Answers) != 2 || picked.Answers[picked.Picked].Content != "from c" {
+		t.Errorf("picked = %+v, want the answer from c of two", picked)
+	}
+}
+
+func TestCompareCmp_ThemeChanged(t *testing.T) {
+	c := NewCompareCmp(nil, ShowCompareMsg{Models: []string{"a", "b"}})
+	c.SetSize(80, 20)
+	before := c.columns[0].renderer
+	c.Update(ThemeChangedMsg{Theme: theme.Light})
+	if c.styles.Markdown != theme.Light.Markdown {
+		t.Errorf("markdown style = %q after switching to light", c.styles.Markdown)
+	}
+	for i, col := range c.columns {
+		if col.renderer == nil || col.renderer == before {
+			t.Errorf("column %d kept its renderer", i)
+		}
+	}
+}
+
+func TestCompareStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *llm.Result
+		want   string
+	}{
+		{"waiting", nil, "waiting…"},
+		{"failed", &llm.Result{Latency: 1500 * time.Millisecond, Err: errors.New("x")}, "failed after 1.5s"},
+		{"no usage", &llm.Result{Latency: 2 * time.Second}, "2.0s"},
+		{"usage", &llm.Result{Latency: time.Second, Message: llm.Message{Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 20, Cost: 0.0012}}}, "1.0s · 10→20 tok · $0.0012"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareStats(tt.result); got != tt.want {
+				t.Errorf("compareStats() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
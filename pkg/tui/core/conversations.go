@@ -0,0 +1,428 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// Dialog IDs used by the Conversations pane. They end in the ID of the
+// conversation they act on; root routes answers with sidebarDialogPrefix
+// to the sidebar.
+const (
+	sidebarDialogPrefix = "sidebar."
+	formRename          = sidebarDialogPrefix + "rename:"
+	formTags            = sidebarDialogPrefix + "tags:"
+	confirmDelete       = sidebarDialogPrefix + "delete:"
+)
+
+// listConversations is the ID of the list in the Conversations pane.
+const listConversations = "conversations"
+
+// conversationsLoadedMsg carries the stored conversations for the sidebar.
+type conversationsLoadedMsg struct {
+	Conversations []store.Conversation
+	Err           error
+}
+
+// ConversationUpdatedMsg is delivered after the title, tags, pin or archive
+// state of a stored conversation changed.
+type ConversationUpdatedMsg struct {
+	Conversation store.Conversation
+	Err          error
+}
+
+// ConversationDeletedMsg is delivered after a conversation was deleted.
+type ConversationDeletedMsg struct {
+	ID  string
+	Err error
+}
+
+// conversationItem is a row of the Conversations pane.
+type conversationItem struct {
+	ID       string
+	Title    string
+	Tags     []string
+	Pinned   bool
+	Archived bool
+}
+
+// ConversationsCmp is the sidebar pane listing stored conversations, pinned
+// ones first. Archived conversations are listed separately.
+type ConversationsCmp struct {
+	focused bool
+	width   int
+	height  int
+
+	store        *store.Store
+	items        []conversationItem
+	list         layout.List[conversationItem]
+	showArchived bool
+
+	keys   conversationsKeyMap
+	styles styles
+}
+
+func NewConversationsCmp(st *store.Store) ConversationsCmp {
+	p := ConversationsCmp{store: st, list: layout.NewList(listConversations, conversationRenderer(defaultStyles))}
+	p.list.WithFilter(conversationText)
+	p.list.SetLoading(st != nil)
+	return p.setKeys(DefaultConversationsKeyMap).setStyles(defaultStyles)
+}
+
+// setKeys sets the bindings of the pane and of its list.
+func (p ConversationsCmp) setKeys(keys conversationsKeyMap) ConversationsCmp {
+	p.keys = keys
+	listKeys := layout.DefaultListKeyMap
+	listKeys.Up, listKeys.Down, listKeys.Select, listKeys.Filter = keys.Up, keys.Down, keys.Open, keys.Filter
+	p.list.WithKeys(listKeys)
+	p.list.WithEmpty(p.emptyText())
+	return p
+}
+
+// setStyles sets the styles of the pane and of its list.
+func (p ConversationsCmp) setStyles(s styles) ConversationsCmp {
+	p.styles = s
+	p.list.WithStyles(s.Layout)
+	p.list.WithRender(conversationRenderer(s))
+	return p
+}
+
+func (p ConversationsCmp) emptyText() string {
+	if p.showArchived {
+		return "Nothing archived"
+	}
+	return "No conversations yet"
+}
+
+// conversationRenderer draws a row of the list: a star for pinned
+// conversations, the title and the tags.
+func conversationRenderer(s styles) layout.ListRenderFunc[conversationItem] {
+	tags := lipgloss.NewStyle().Foreground(s.Subtle)
+	return func(item conversationItem, width int) string {
+		marker := "  "
+		if item.Pinned {
+			marker = "★ "
+		}
+		text := marker + item.Title
+		if len(item.Tags) > 0 {
+			text += tags.Render(" #" + strings.Join(item.Tags, " #"))
+		}
+		return text
+	}
+}
+
+// conversationText is what the filter of the list matches.
+func conversationText(item conversationItem) string {
+	return item.Title + " " + strings.Join(item.Tags, " ")
+}
+
+func (p ConversationsCmp) Init() tea.Cmd {
+	return p.loadCmd()
+}
+
+// loadCmd lists the stored conversations in the background.
+func (p ConversationsCmp) loadCmd() tea.Cmd {
+	if p.store == nil {
+		return nil
+	}
+	st := p.store
+	return func() tea.Msg {
+		conversations, err := st.List()
+		return conversationsLoadedMsg{Conversations: conversations, Err: err}
+	}
+}
+
+func (p ConversationsCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case layout.ComponentSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		p.list.SetSize(paneListSize(p.width, p.height))
+	case ThemeChangedMsg:
+		p = p.setStyles(newStyles(msg.Theme))
+	case conversationsLoadedMsg:
+		var skipped *store.SkippedError
+		if errors.As(msg.Err, &skipped) {
+			// The readable conversations are still listed
+			p = p.setItems(msg.Conversations)
+			return p, layout.Toast(layout.ToastError, msg.Err.Error())
+		}
+		if msg.Err != nil {
+			p.list.SetError(msg.Err)
+			return p, nil
+		}
+		p = p.setItems(msg.Conversations)
+	case ConversationSavedMsg:
+		if msg.Err == nil {
+			p = p.upsert(msg.Conversation)
+		}
+	case ConversationUpdatedMsg:
+		if msg.Err != nil {
+			p.list.SetError(msg.Err)
+		}
+		return p, p.loadCmd()
+	case ConversationDeletedMsg:
+		if msg.Err != nil {
+			p.list.SetError(msg.Err)
+		}
+		return p, p.loadCmd()
+	case layout.ListSelectedMsg[conversationItem]:
+		if msg.ID == listConversations {
+			return p, func() tea.Msg { return OpenConversationMsg{ID: msg.Item.ID} }
+		}
+	case tea.MouseMsg:
+		// The list starts below the frame and the title
+		x, y := paneListOffset()
+		_, cmd := p.list.Update(layout.TranslateMouse(msg, -x, -y))
+		return p, cmd
+	case layout.ConfirmedMsg:
+		if id, ok := strings.CutPrefix(msg.ID, confirmDelete); ok {
+			return p, p.deleteCmd(id)
+		}
+	case layout.FormSubmittedMsg:
+		if id, ok := strings.CutPrefix(msg.ID, formRename); ok {
+			title := strings.TrimSpace(msg.Values["title"])
+			return p, updateConversationCmd(p.store, id, func(c *store.Conversation) { c.Title = title })
+		}
+		if id, ok := strings.CutPrefix(msg.ID, formTags); ok {
+			tags := llm.NormalizeTags(strings.FieldsFunc(msg.Values["tags"], isTagSeparator), 0)
+			return p, updateConversationCmd(p.store, id, func(c *store.Conversation) { c.Tags = tags })
+		}
+	case tea.KeyPressMsg:
+		if !p.focused {
+			return p, nil
+		}
+		return p.handleKey(msg)
+	}
+	return p, nil
+}
+
+func (p ConversationsCmp) handleKey(msg tea.KeyPressMsg) (ConversationsCmp, tea.Cmd) {
+	if p.list.HandlesKey(msg) {
+		_, cmd := p.list.Update(msg)
+		return p, cmd
+	}
+	if key.Matches(msg, p.keys.ShowArchived) {
+		p.showArchived = !p.showArchived
+		p.list.WithEmpty(p.emptyText())
+		p.list.SetItems(p.visible())
+		p.list.Select(0)
+		return p, nil
+	}
+
+	item, ok := p.selectedItem()
+	if !ok {
+		return p, nil
+	}
+	switch {
+	case key.Matches(msg, p.keys.Rename):
+		return p, func() tea.Msg {
+			return layout.ShowFormDialogMsg{ID: formRename + item.ID, Title: "Rename conversation", Fields: []layout.FormField{
+				{Name: "title", Value: item.Title},
+			}}
+		}
+	case key.Matches(msg, p.keys.Tag):
+		return p, func() tea.Msg {
+			return layout.ShowFormDialogMsg{ID: formTags + item.ID, Title: "Tag conversation", Fields: []layout.FormField{
+				{Name: "tags", Value: strings.Join(item.Tags, " "), Placeholder: "space separated"},
+			}}
+		}
+	case key.Matches(msg, p.keys.Pin):
+		pinned := !item.Pinned
+		return p, updateConversationCmd(p.store, item.ID, func(c *store.Conversation) { c.Pinned = pinned })
+	case key.Matches(msg, p.keys.Archive):
+		archived := !item.Archived
+		return p, updateConversationCmd(p.store, item.ID, func(c *store.Conversation) {
+			c.ArchivedAt = time.Time{}
+			if archived {
+				c.ArchivedAt = time.Now()
+			}
+		})
+	case key.Matches(msg, p.keys.Delete):
+		text := fmt.Sprintf("Delete %q?", item.Title)
+		return p, func() tea.Msg { return layout.ShowConfirmDialogMsg{ID: confirmDelete + item.ID, Text: text} }
+	}
+	return p, nil
+}
+
+// HandlesKey reports whether the pane takes msg rather than the sidebar.
+// Moving past either end of the list is left to the sidebar, which moves
+// on to the next pane.
+func (p ConversationsCmp) HandlesKey(msg tea.KeyPressMsg) bool {
+	if p.list.HandlesKey(msg) {
+		return true
+	}
+	if key.Matches(msg, p.keys.Up) || key.Matches(msg, p.keys.Down) {
+		return false
+	}
+	for _, b := range p.Bindings() {
+		if key.Matches(msg, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// CapturesKeys reports whether the list filter is being typed.
+func (p ConversationsCmp) CapturesKeys() bool {
+	return p.list.CapturesKeys()
+}
+
+// setItems replaces the listed conversations, keeping the selection on the
+// same conversation when it is still listed.
+func (p ConversationsCmp) setItems(conversations []store.Conversation) ConversationsCmp {
+	current, _ := p.selectedItem()
+	p.items = make([]conversationItem, len(conversations))
+	for i, c := range conversations {
+		p.items[i] = newConversationItem(c)
+	}
+	return p.refresh(current.ID)
+}
+
+// upsert lists a saved conversation first among its peers, replacing its
+// row if it was listed, without reading the store again.
+func (p ConversationsCmp) upsert(c store.Conversation) ConversationsCmp {
+	current, _ := p.selectedItem()
+	items := []conversationItem{newConversationItem(c)}
+	for _, item := range p.items {
+		if item.ID != c.ID {
+			items = append(items, item)
+		}
+	}
+	p.items = items
+	return p.refresh(current.ID)
+}
+
+// refresh sorts the pins on top and lists the visible conversations,
+// selecting the conversation with the given ID when it is listed.
+func (p ConversationsCmp) refresh(selected string) ConversationsCmp {
+	// The store lists the most recently updated first; pins go on top
+	sort.SliceStable(p.items, func(i, j int) bool {
+		return p.items[i].Pinned && !p.items[j].Pinned
+	})
+	visible := p.visible()
+	p.list.SetItems(visible)
+	for i, item := range visible {
+		if item.ID == selected {
+			p.list.Select(i)
+		}
+	}
+	return p
+}
+
+func newConversationItem(c store.Conversation) conversationItem {
+	return conversationItem{
+		ID:       c.ID,
+		Title:    conversationTitle(c.Title, c.Messages),
+		Tags:     c.Tags,
+		Pinned:   c.Pinned,
+		Archived: !c.ArchivedAt.IsZero(),
+	}
+}
+
+// visible returns the conversations listed: the archived ones or the others.
+func (p ConversationsCmp) visible() []conversationItem {
+	var items []conversationItem
+	for _, item := range p.items {
+		if item.Archived == p.showArchived {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (p ConversationsCmp) selectedItem() (conversationItem, bool) {
+	return p.list.Selected()
+}
+
+// deleteCmd deletes a conversation from the store in the background.
+func (p ConversationsCmp) deleteCmd(id string) tea.Cmd {
+	if p.store == nil {
+		return nil
+	}
+	st := p.store
+	return func() tea.Msg {
+		return ConversationDeletedMsg{ID: id, Err: st.Delete(id)}
+	}
+}
+
+// updateConversationCmd changes a stored conversation in the background.
+// Its UpdatedAt is kept, so the change does not reorder the list.
+func updateConversationCmd(st *store.Store, id string, change func(*store.Conversation)) tea.Cmd {
+	if st == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		c, err := st.Load(id)
+		if err != nil {
+			return ConversationUpdatedMsg{Conversation: store.Conversation{ID: id}, Err: err}
+		}
+		change(&c)
+		return ConversationUpdatedMsg{Conversation: c, Err: st.Save(c)}
+	}
+}
+
+func isTagSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t'
+}
+
+func (p ConversationsCmp) View() string {
+	boxStyle := p.styles.BlurredBox
+	if p.focused {
+		boxStyle = p.styles.FocusedBox
+	}
+
+	title := "Conversations"
+	if p.showArchived {
+		title = "Archived"
+	}
+	header := lipgloss.NewStyle().Bold(true).Render(title)
+	view := lipgloss.JoinVertical(lipgloss.Top, header, p.list.View())
+	return boxStyle.Width(p.width).Height(p.height).Render(view)
+}
+
+func (p ConversationsCmp) SetFocused(focused bool) (layout.Focusable, tea.Cmd) {
+	p.focused = focused
+	_, cmd := p.list.SetFocused(focused)
+	return p, cmd
+}
+
+func (p ConversationsCmp) IsFocused() bool {
+	return p.focused
+}
+
+func (p ConversationsCmp) Clone() layout.Focusable {
+	clone := p
+	clone.items = append([]conversationItem(nil), p.items...)
+	clone.list = *p.list.Clone().(*layout.List[conversationItem])
+	return clone
+}
+
+func (p ConversationsCmp) Bindings() []key.Binding {
+	if p.list.CapturesKeys() {
+		return p.list.Bindings()
+	}
+	pin := p.keys.Pin
+	archive := p.keys.Archive
+	showArchived := p.keys.ShowArchived
+	if item, ok := p.selectedItem(); ok && item.Pinned {
+		pin.SetHelp(pin.Help().Key, "unpin")
+	}
+	if p.showArchived {
+		archive.SetHelp(archive.Help().Key, "unarchive")
+		showArchived.SetHelp(showArchived.Help().Key, "show active")
+	}
+	bindings := p.list.Bindings()
+	return append(bindings, p.keys.Rename, p.keys.Tag, pin, archive, p.keys.Delete, showArchived)
+}
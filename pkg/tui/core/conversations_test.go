@@ -0,0 +1,219 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+func TestConversationsCmp_Actions(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	now := time.Now()
+	for i, title := range []string{"older", "newer"} {
+		c := store.NewConversation()
+		c.Title = title
+		c.UpdatedAt = now.Add(time.Duration(i) * time.Minute)
+		c.Messages = []llm.Message{{Role: "user", Content: title}}
+		if err := st.Save(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewConversationsCmp(st)
+	focused, _ := p.SetFocused(true)
+	p = focused.(ConversationsCmp)
+	// run feeds msg to the pane, then every message its commands produce
+	run := func(msg tea.Msg) {
+		for msg != nil {
+			model, cmd := p.Update(msg)
+			p = model.(ConversationsCmp)
+			msg = nil
+			if cmd != nil {
+				msg = cmd()
+			}
+		}
+	}
+	titles := func() []string {
+		var out []string
+		for _, item := range p.visible() {
+			out = append(out, item.Title)
+		}
+		return out
+	}
+
+	run(p.Init()())
+	if got := titles(); len(got) != 2 || got[0] != "newer" {
+		t.Fatalf("listed %v, want newest first", got)
+	}
+
+	down := tea.KeyPressMsg{Code: 'j', Text: "j"}
+	if !p.HandlesKey(down) {
+		t.Error("HandlesKey(down) = false above the last conversation")
+	}
+	run(down)
+	if p.HandlesKey(down) {
+		t.Error("HandlesKey(down) = true on the last conversation, want the sidebar to move on")
+	}
+
+	// Pinning moves "older" to the top, and the selection follows it
+	run(tea.KeyPressMsg{Code: 'p', Text: "p"})
+	if got := titles(); got[0] != "older" || p.list.Index() != 0 {
+		t.Errorf("after pin listed %v with %d selected, want older first and selected", got, p.list.Index())
+	}
+
+	item, _ := p.selectedItem()
+	run(layout.FormSubmittedMsg{ID: formRename + item.ID, Values: map[string]string{"title": " renamed "}})
+	run(layout.FormSubmittedMsg{ID: formTags + item.ID, Values: map[string]string{"tags": "Go, #tui"}})
+	stored, _ := st.Load(item.ID)
+	if stored.Title != "renamed" || len(stored.Tags) != 2 || stored.Tags[1] != "tui" || !stored.Pinned {
+		t.Errorf("stored %+v, want renamed, pinned and tagged go, tui", stored)
+	}
+
+	run(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if got := titles(); len(got) != 1 || got[0] != "newer" {
+		t.Errorf("after archive listed %v, want only newer", got)
+	}
+	run(tea.KeyPressMsg{Code: 'A', Text: "A"})
+	if got := titles(); len(got) != 1 || got[0] != "renamed" {
+		t.Errorf("archive listed %v, want renamed", got)
+	}
+
+	// Delete only asks; the confirmation deletes
+	model, cmd := p.Update(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	p = model.(ConversationsCmp)
+	confirm, ok := cmd().(layout.ShowConfirmDialogMsg)
+	if !ok {
+		t.Fatalf("delete did not ask for confirmation")
+	}
+	if _, err := st.Load(item.ID); err != nil {
+		t.Fatal("deleted before confirming")
+	}
+	run(layout.ConfirmedMsg{ID: confirm.ID})
+	if _, err := st.Load(item.ID); err == nil {
+		t.Error("conversation still stored after confirming delete")
+	}
+	if got := titles(); len(got) != 0 {
+		t.Errorf("archive listed %v after delete, want nothing", got)
+	}
+}
+
+func TestConversationsCmp_Saved(t *testing.T) {
+	conversation := func(id, title string, pinned bool) store.Conversation {
+		return store.Conversation{ID: id, Title: title, Pinned: pinned}
+	}
+	p := NewConversationsCmp(nil)
+	p = p.setItems([]store.Conversation{
+		conversation("a", "a", false),
+		conversation("pin", "pin", true),
+		conversation("b", "b", false),
+	})
+
+	tests := []struct {
+		name string
+		msg  ConversationSavedMsg
+		want []string
+	}{
+		{"new conversation", ConversationSavedMsg{ID: "c", Conversation: conversation("c", "c", false)}, []string{"pin", "c", "a", "b"}},
+		{"listed conversation", ConversationSavedMsg{ID: "b", Conversation: conversation("b", "b2", false)}, []string{"pin", "b2", "a"}},
+		{"pinned conversation", ConversationSavedMsg{ID: "pin", Conversation: conversation("pin", "pin2", true)}, []string{"pin2", "a", "b"}},
+		{"failed save", ConversationSavedMsg{ID: "c", Conversation: conversation("c", "c", false), Err: errors.New("disk full")}, []string{"pin", "a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, cmd := p.Update(tt.msg)
+			if cmd != nil {
+				t.Error("a save reloaded the list")
+			}
+			var got []string
+			for _, item := range model.(ConversationsCmp).visible() {
+				got = append(got, item.Title)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("listed %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMainCmp_Title(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMainCmp(&llm.Manager{}, st, nil, nil)
+	if m.titleCmd() != nil {
+		t.Error("titleCmd() without an exchange != nil")
+	}
+	m = m.setTree(llm.NewTree([]llm.Message{
+		{Role: "user", Content: "hi"},
+		{Role: "assistant", Content: "hello"},
+	}, ""))
+	if m.titleCmd() == nil {
+		t.Error("titleCmd() after the first exchange = nil")
+	}
+	m.titles.Disabled = true
+	if m.titleCmd() != nil {
+		t.Error("titleCmd() with titles disabled != nil")
+	}
+
+	m.titling = true
+	m, _ = m.setTitle(ConversationTitledMsg{ID: m.conversation.ID, Title: "Greetings", Tags: []string{"smalltalk"}})
+	if m.titling || m.conversation.Title != "Greetings" || len(m.conversation.Tags) != 1 {
+		t.Errorf("after setTitle conversation = %+v", m.conversation)
+	}
+
+	// A title arriving after a rename does not replace it
+	m = m.syncConversation(store.Conversation{ID: m.conversation.ID, Title: "Mine"})
+	m, _ = m.setTitle(ConversationTitledMsg{ID: m.conversation.ID, Title: "Generated"})
+	if m.conversation.Title != "Mine" {
+		t.Errorf("Title = %q, want the chosen one", m.conversation.Title)
+	}
+}
+
+func TestFirstExchange(t *testing.T) {
+	long := strings.Repeat("é", titleTokens*4)
+	tests := []struct {
+		name     string
+		messages []llm.Message
+		want     []string
+	}{
+		{"no answer yet", []llm.Message{{Role: "system", Content: "be nice"}, {Role: "user", Content: "q1"}}, nil},
+		{"later turns left out", []llm.Message{
+			{Role: "system", Content: "be nice"},
+			{Role: "user", Content: "q1"},
+			{Role: "assistant", Content: "a1"},
+			{Role: "user", Content: "q2"},
+			{Role: "assistant", Content: "a2"},
+		}, []string{"q1", "a1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, msg := range firstExchange(tt.messages) {
+				got = append(got, msg.Content)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("firstExchange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	exchange := firstExchange([]llm.Message{{Role: "user", Content: long}, {Role: "assistant", Content: "a"}})
+	if tokens := llm.EstimateTokens(exchange); tokens > titleTokens {
+		t.Errorf("a long question is sent as %d tokens, want at most %d", tokens, titleTokens)
+	}
+	if !utf8.ValidString(exchange[0].Content) {
+		t.Error("cutting a long question split a character")
+	}
+}
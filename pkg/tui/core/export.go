@@ -0,0 +1,81 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/export"
+	"github.com/darling/mana/pkg/store"
+)
+
+// ExportMsg writes the conversation to Path, or a file named after it in
+// the working directory when Path is empty
+type ExportMsg struct {
+	Format export.Format
+	Path   string
+}
+
+// ExportedMsg is delivered once an export was written
+type ExportedMsg struct {
+	Path string
+	Err  error
+}
+
+// export writes the conversation in the background.
+func (m MainCmp) export(format export.Format, path string) (MainCmp, tea.Cmd) {
+	if len(m.messages) == 0 {
+		m.err = errors.New("nothing to export yet")
+		return m, nil
+	}
+	c := m.snapshot()
+	if path == "" {
+		path = exportFileName(conversationTitle(c.Title, c.Messages), c.ID) + format.Extension()
+	}
+	return m, func() tea.Msg {
+		return ExportedMsg{Path: path, Err: writeExport(path, c, format)}
+	}
+}
+
+func writeExport(path string, c store.Conversation, format export.Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := export.Write(f, c, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// exportFileName turns a title into a file name, keeping the start of the
+// conversation ID so exports of similarly named conversations differ.
+func exportFileName(title, id string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	if name == "" {
+		return "mana-" + id
+	}
+	return name + "-" + id
+}
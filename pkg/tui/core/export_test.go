@@ -0,0 +1,20 @@
+package core
+
+import "testing"
+
+func TestExportFileName(t *testing.T) {
+	tests := []struct {
+		title, id, want string
+	}{
+		{"Fix the Go build!", "0123456789", "fix-the-go-build-01234567"},
+		{"  ** ", "abc", "mana-abc"},
+		{"Über cool: 2 things", "abcdefgh-1", "über-cool-2-things-abcdefgh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := exportFileName(tt.title, tt.id); got != tt.want {
+				t.Errorf("exportFileName(%q, %q) = %q, want %q", tt.title, tt.id, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// searchLimit is the most hits the global search shows.
+const searchLimit = 50
+
+// ShowSearchMsg opens the search across all stored conversations
+type ShowSearchMsg struct {
+	Query string
+}
+
+// searchResultMsg carries the hits for Query.
+type searchResultMsg struct {
+	Query string
+	Hits  []store.Hit
+	Err   error
+}
+
+// SearchCmp is a modal layer searching every stored conversation. Opening
+// a hit emits a CommandMsg carrying an OpenConversationMsg.
+type SearchCmp struct {
+	focused  bool
+	width    int
+	height   int
+	store    *store.Store
+	input    textinput.Model
+	hits     []store.Hit
+	selected int
+	err      error
+	keys     globalSearchKeyMap
+	styles   styles
+}
+
+// NewSearchCmp creates the search layer, starting with query.
+func NewSearchCmp(st *store.Store, query string) *SearchCmp {
+	ti := textinput.New()
+	ti.Prompt = "search: "
+	ti.Placeholder = "words in any conversation..."
+	ti.SetValue(query)
+	ti.Focus()
+	return &SearchCmp{store: st, input: ti, keys: DefaultGlobalSearchKeyMap, styles: defaultStyles}
+}
+
+func (c *SearchCmp) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, c.searchCmd())
+}
+
+// searchCmd looks up the current query in the background.
+func (c *SearchCmp) searchCmd() tea.Cmd {
+	query := c.input.Value()
+	if c.store == nil || strings.TrimSpace(query) == "" {
+		return nil
+	}
+	st := c.store
+	return func() tea.Msg {
+		hits, err := st.Search(query, searchLimit)
+		return searchResultMsg{Query: query, Hits: hits, Err: err}
+	}
+}
+
+func (c *SearchCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case searchResultMsg:
+		// Results of a query typed over in the meantime are stale
+		if msg.Query == c.input.Value() {
+			c.hits, c.err, c.selected = msg.Hits, msg.Err, 0
+		}
+		return c, nil
+	case ThemeChangedMsg:
+		c.styles = newStyles(msg.Theme)
+		return c, nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, c.keys.Up):
+			if c.selected > 0 {
+				c.selected--
+			}
+			return c, nil
+		case key.Matches(msg, c.keys.Down):
+			if c.selected < len(c.hits)-1 {
+				c.selected++
+			}
+			return c, nil
+		case key.Matches(msg, c.keys.Open):
+			if c.selected >= len(c.hits) {
+				return c, nil
+			}
+			hit := c.hits[c.selected]
+			open := OpenConversationMsg{ID: hit.Conversation.ID, MessageID: hit.Message.ID}
+			return c, func() tea.Msg { return layout.CommandMsg{Msg: open} }
+		case key.Matches(msg, c.keys.Cancel):
+			return c, func() tea.Msg { return layout.CancelledMsg{} }
+		}
+	}
+
+	var cmd tea.Cmd
+	before := c.input.Value()
+	c.input, cmd = c.input.Update(msg)
+	if c.input.Value() != before {
+		if strings.TrimSpace(c.input.Value()) == "" {
+			c.hits, c.err = nil, nil
+		}
+		return c, tea.Batch(cmd, c.searchCmd())
+	}
+	return c, cmd
+}
+
+func (c *SearchCmp) boxWidth() int {
+	return max(60, c.width*2/3)
+}
+
+// visibleHits is how many hits fit, each taking two rows.
+func (c *SearchCmp) visibleHits() int {
+	return max(1, (c.height-8)/2)
+}
+
+func (c *SearchCmp) View() string {
+	style := c.styles.Layout.DialogBox.Padding(0, 1).Width(c.boxWidth())
+
+	innerW := c.boxWidth() - 4 // account for border and padding
+	dim := lipgloss.NewStyle().Foreground(c.styles.Subtle)
+	line := lipgloss.NewStyle().MaxWidth(innerW)
+
+	rows := []string{c.input.View(), ""}
+	switch {
+	case c.err != nil:
+		rows = append(rows, c.styles.ErrorText.Render("error: "+c.err.Error()))
+	case len(c.hits) == 0 && strings.TrimSpace(c.input.Value()) != "":
+		rows = append(rows, dim.Render("No matches"))
+	}
+
+	// Keep the selection inside the visible window
+	start := 0
+	if visible := c.visibleHits(); c.selected >= visible {
+		start = c.selected - visible + 1
+	}
+	end := min(len(c.hits), start+c.visibleHits())
+	for i := start; i < end; i++ {
+		hit := c.hits[i]
+		title := conversationTitle(hit.Conversation.Title, hit.Conversation.Messages)
+		date := dim.Render(hit.Conversation.UpdatedAt.Format("Jan 2 15:04"))
+		gap := max(1, innerW-lipgloss.Width(title)-lipgloss.Width(date))
+		head := line.Render(title + strings.Repeat(" ", gap) + date)
+		if i == c.selected {
+			head = lipgloss.NewStyle().Reverse(true).Render(head)
+		}
+		snippet := hit.Snippet
+		if hit.Message.Role != "" {
+			snippet = hit.Message.Role + ": " + snippet
+		}
+		rows = append(rows, head, line.Render(dim.Render("  "+snippet)))
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+func (c *SearchCmp) SetSize(width, height int) tea.Cmd {
+	c.width, c.height = width, height
+	c.input.SetWidth(c.boxWidth() - 12)
+	return nil
+}
+
+func (c *SearchCmp) GetSize() (int, int) { return c.width, c.height }
+
+func (c *SearchCmp) SetFocused(focused bool) (layout.FocusScope, tea.Cmd) {
+	c.focused = focused
+	if focused {
+		return c, c.input.Focus()
+	}
+	c.input.Blur()
+	return c, nil
+}
+
+func (c *SearchCmp) IsFocused() bool { return c.focused }
+
+func (c *SearchCmp) Clone() layout.FocusScope {
+	clone := *c
+	clone.hits = append([]store.Hit(nil), c.hits...)
+	return &clone
+}
+
+func (c *SearchCmp) Bindings() []key.Binding {
+	return []key.Binding{c.keys.Up, c.keys.Down, c.keys.Open, c.keys.Cancel}
+}
+
+func (c *SearchCmp) LayerMeta() layout.LayerMeta {
+	return layout.LayerMeta{
+		ID:          "search",
+		Z:           200,
+		Modal:       true,
+		CaptureKeys: true,
+		DismissKeys: c.keys.Cancel.Keys(),
+		Scrim:       true,
+		Pos:         layout.Position{Anchor: layout.TopCenter, Y: 2},
+	}
+}
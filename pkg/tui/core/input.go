@@ -0,0 +1,137 @@
+package core
+
+import (
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// focusPaneMsg asks the root to move focus to the pane with ID.
+type focusPaneMsg struct {
+	ID string
+}
+
+// inputResizedMsg is emitted when the inline input wants a different height,
+// so the root can give the rest of the column back to the main view.
+type inputResizedMsg struct{}
+
+// InputCmp is the prompt docked below the main view. It grows with its
+// content up to maxLines and shares its editor with the prompt dialog.
+type InputCmp struct {
+	focused  bool
+	width    int
+	height   int
+	maxLines int
+	input    layout.PromptInput
+	keys     inputKeyMap
+	styles   styles
+}
+
+func NewInputCmp(initial string, maxLines int, opts layout.PromptOptions) InputCmp {
+	return InputCmp{
+		maxLines: max(1, maxLines),
+		input:    layout.NewPromptInput(initial, opts),
+		keys:     DefaultInputKeyMap,
+		styles:   defaultStyles,
+	}
+}
+
+// setStyles sets the styles of the input and of its editor.
+func (m InputCmp) setStyles(s styles) InputCmp {
+	m.styles = s
+	m.input.SetStyles(s.Layout)
+	return m
+}
+
+func (m InputCmp) Init() tea.Cmd { return nil }
+
+func (m InputCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before := m.Height()
+
+	switch msg := msg.(type) {
+	case layout.ComponentSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.input.SetWidth(max(1, m.width-m.styles.FocusedBox.GetHorizontalFrameSize()))
+		m.input.SetHeight(m.textLines())
+		return m, nil
+	case ThemeChangedMsg:
+		m = m.setStyles(newStyles(msg.Theme))
+		return m, nil
+	case tea.KeyPressMsg:
+		if !m.focused {
+			return m, nil
+		}
+		switch {
+		case key.Matches(msg, m.keys.Leave):
+			draft := m.input.Value()
+			return m, tea.Batch(
+				func() tea.Msg { return layout.PromptCancelledMsg{Draft: draft} },
+				func() tea.Msg { return focusPaneMsg{ID: paneMain} },
+			)
+		case key.Matches(msg, m.keys.FocusNext) && !m.input.HasSuggestions():
+			return m, func() tea.Msg { return focusPaneMsg{ID: paneSidebar} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.input.SetHeight(m.textLines())
+	if m.Height() != before {
+		cmd = tea.Batch(cmd, func() tea.Msg { return inputResizedMsg{} })
+	}
+	return m, cmd
+}
+
+func (m InputCmp) View() string {
+	boxStyle := m.styles.BlurredBox
+	if m.focused {
+		boxStyle = m.styles.FocusedBox
+	}
+	innerW := max(1, m.width-boxStyle.GetHorizontalFrameSize())
+	content := lipgloss.NewStyle().Width(innerW).MaxWidth(innerW).Render(m.input.View())
+	return boxStyle.Width(m.width).Render(content)
+}
+
+// Height is the number of rows the input needs for its current content.
+func (m InputCmp) Height() int {
+	return m.textLines() + m.input.ExtraLines() + m.styles.FocusedBox.GetVerticalFrameSize()
+}
+
+// textLines is the editor height: one row per visual line, up to maxLines.
+func (m InputCmp) textLines() int {
+	return min(max(1, m.input.VisualLines()), m.maxLines)
+}
+
+// Value returns the text of the input.
+func (m InputCmp) Value() string { return m.input.Value() }
+
+// SetValue replaces the text of the input.
+func (m InputCmp) SetValue(s string) InputCmp {
+	m.input.SetValue(s)
+	m.input.SetHeight(m.textLines())
+	return m
+}
+
+// CapturesKeys reports that printable keys belong to the input while it is
+// focused, so single-letter global bindings must not fire.
+func (m InputCmp) CapturesKeys() bool { return m.focused }
+
+func (m InputCmp) SetFocused(focused bool) (layout.Focusable, tea.Cmd) {
+	m.focused = focused
+	if focused {
+		return m, m.input.Focus()
+	}
+	m.input.Blur()
+	return m, nil
+}
+
+func (m InputCmp) IsFocused() bool { return m.focused }
+
+func (m InputCmp) Clone() layout.Focusable { return m }
+
+func (m InputCmp) Bindings() []key.Binding {
+	return append(m.input.Bindings(), m.keys.Leave)
+}
@@ -3,19 +3,73 @@ package core
 import "github.com/charmbracelet/bubbles/v2/key"
 
 type keyMap struct {
+	// Quit works everywhere outside of layers, even while a text input has
+	// the keyboard, so it should not be a printable key
 	Quit      key.Binding
 	FocusNext key.Binding
+	Palette   key.Binding
+	Search    key.Binding
+
+	FocusLeft  key.Binding
+	FocusDown  key.Binding
+	FocusUp    key.Binding
+	FocusRight key.Binding
+
+	ToggleSidebar key.Binding
+	Maximize      key.Binding
+	SidebarNarrow key.Binding
+	SidebarWiden  key.Binding
 }
 
 var DefaultKeyMap = keyMap{
 	Quit: key.NewBinding(
-		key.WithKeys("ctrl+c", "q", "esc"),
-		key.WithHelp("ctrl+c, q, esc", "quit"),
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("ctrl+c", "quit"),
 	),
 	FocusNext: key.NewBinding(
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "focus next"),
 	),
+	FocusLeft: key.NewBinding(
+		key.WithKeys("alt+h"),
+		key.WithHelp("alt+h", "focus left"),
+	),
+	FocusDown: key.NewBinding(
+		key.WithKeys("alt+j"),
+		key.WithHelp("alt+j", "focus down"),
+	),
+	FocusUp: key.NewBinding(
+		key.WithKeys("alt+k"),
+		key.WithHelp("alt+k", "focus up"),
+	),
+	FocusRight: key.NewBinding(
+		key.WithKeys("alt+l"),
+		key.WithHelp("alt+l", "focus right"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "commands"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search all conversations"),
+	),
+	ToggleSidebar: key.NewBinding(
+		key.WithKeys("ctrl+b"),
+		key.WithHelp("ctrl+b", "toggle sidebar"),
+	),
+	Maximize: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "maximize pane"),
+	),
+	SidebarNarrow: key.NewBinding(
+		key.WithKeys("ctrl+left"),
+		key.WithHelp("ctrl+←", "narrower sidebar"),
+	),
+	SidebarWiden: key.NewBinding(
+		key.WithKeys("ctrl+right"),
+		key.WithHelp("ctrl+→", "wider sidebar"),
+	),
 }
 
 type sidebarKeyMap struct {
@@ -23,6 +77,8 @@ type sidebarKeyMap struct {
 	FocusDown key.Binding
 	Enter     key.Binding
 	Create    key.Binding
+	Grow      key.Binding
+	Shrink    key.Binding
 }
 
 var DefaultSidebarKeyMap = sidebarKeyMap{
@@ -42,12 +98,120 @@ var DefaultSidebarKeyMap = sidebarKeyMap{
 		key.WithKeys("c"),
 		key.WithHelp("c", "create new"),
 	),
+	Grow: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "taller pane"),
+	),
+	Shrink: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "shorter pane"),
+	),
+}
+
+// conversationsKeyMap is active in the Conversations pane of the sidebar
+type conversationsKeyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	Open         key.Binding
+	Filter       key.Binding
+	Rename       key.Binding
+	Tag          key.Binding
+	Pin          key.Binding
+	Archive      key.Binding
+	Delete       key.Binding
+	ShowArchived key.Binding
+}
+
+var DefaultConversationsKeyMap = conversationsKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename"),
+	),
+	Tag: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "tags"),
+	),
+	Pin: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pin"),
+	),
+	Archive: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "archive"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d", "delete"),
+		key.WithHelp("d", "delete"),
+	),
+	ShowArchived: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "show archived"),
+	),
+}
+
+// modelsKeyMap is active in the Models pane of the sidebar
+type modelsKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Filter key.Binding
+}
+
+var DefaultModelsKeyMap = modelsKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "use model"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
 }
 
 type mainKeyMap struct {
 	Redraw     key.Binding
 	Create     key.Binding
 	ShowDialog key.Binding
+	Compact    key.Binding
+
+	NewConversation key.Binding
+	Persona         key.Binding
+	Template        key.Binding
+
+	Select key.Binding
+	Yank   key.Binding
+
+	Regenerate key.Binding
+	Edit       key.Binding
+	PrevBranch key.Binding
+	NextBranch key.Binding
+
+	Compare key.Binding
+	Search  key.Binding
 }
 
 var DefaultMainKeyMap = mainKeyMap{
@@ -63,4 +227,221 @@ var DefaultMainKeyMap = mainKeyMap{
 		key.WithKeys("d"),
 		key.WithHelp("d", "show dialog"),
 	),
+	Compact: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "compact"),
+	),
+	NewConversation: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new conversation"),
+	),
+	Persona: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "persona"),
+	),
+	Template: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "template"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "select"),
+	),
+	Yank: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y N", "yank code block"),
+	),
+	Regenerate: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "regenerate"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit last message"),
+	),
+	PrevBranch: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous branch"),
+	),
+	NextBranch: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next branch"),
+	),
+	Compare: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "compare models"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+}
+
+// searchKeyMap is active while searching the transcript in the main view
+type searchKeyMap struct {
+	Confirm key.Binding
+	Clear   key.Binding
+	Next    key.Binding
+	Prev    key.Binding
+}
+
+var DefaultSearchKeyMap = searchKeyMap{
+	Confirm: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "done typing"),
+	),
+	Clear: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "clear search"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	Prev: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+}
+
+// selectKeyMap is active while moving the selection cursor in the main view
+type selectKeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	PrevBranch key.Binding
+	NextBranch key.Binding
+	Copy       key.Binding
+	CopyCode   key.Binding
+	CopyRaw    key.Binding
+	Edit       key.Binding
+	Compact    key.Binding
+	Exit       key.Binding
+}
+
+var DefaultSelectKeyMap = selectKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next"),
+	),
+	PrevBranch: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "previous branch"),
+	),
+	NextBranch: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "next branch"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy"),
+	),
+	CopyCode: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "copy code"),
+	),
+	CopyRaw: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "copy markdown"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit and resend"),
+	),
+	Compact: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "compact up to here"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc", "v"),
+		key.WithHelp("esc", "done"),
+	),
+}
+
+type inputKeyMap struct {
+	Leave     key.Binding
+	FocusNext key.Binding
+}
+
+var DefaultInputKeyMap = inputKeyMap{
+	Leave: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back to messages"),
+	),
+	FocusNext: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "focus next"),
+	),
+}
+
+// compareKeyMap is active in the side-by-side compare view
+type compareKeyMap struct {
+	Prev   key.Binding
+	Next   key.Binding
+	Narrow key.Binding
+	Widen  key.Binding
+	Reset  key.Binding
+	Pick   key.Binding
+	Cancel key.Binding
+}
+
+var DefaultCompareKeyMap = compareKeyMap{
+	Prev: key.NewBinding(
+		key.WithKeys("left", "h", "shift+tab"),
+		key.WithHelp("←/h", "previous column"),
+	),
+	Next: key.NewBinding(
+		key.WithKeys("right", "l", "tab"),
+		key.WithHelp("→/l", "next column"),
+	),
+	Narrow: key.NewBinding(
+		key.WithKeys("<"),
+		key.WithHelp("<", "narrow"),
+	),
+	Widen: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "widen"),
+	),
+	Reset: key.NewBinding(
+		key.WithKeys("="),
+		key.WithHelp("=", "equal widths"),
+	),
+	Pick: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "continue with answer"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+// globalSearchKeyMap is active in the search across all conversations
+type globalSearchKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Cancel key.Binding
+}
+
+var DefaultGlobalSearchKeyMap = globalSearchKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "ctrl+p"),
+		key.WithHelp("↑", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "ctrl+n"),
+		key.WithHelp("↓", "down"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "open"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "close"),
+	),
 }
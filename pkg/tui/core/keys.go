@@ -0,0 +1,243 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+
+	"github.com/darling/mana/pkg/config"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// KeyMaps holds every key binding of the TUI. The tag of each field names
+// the scope its actions are configured under, as in "main.compact".
+type KeyMaps struct {
+	Global        keyMap               `keys:"global"`
+	Sidebar       sidebarKeyMap        `keys:"sidebar"`
+	Conversations conversationsKeyMap  `keys:"conversations"`
+	Models        modelsKeyMap         `keys:"models"`
+	Main          mainKeyMap           `keys:"main"`
+	Search        searchKeyMap         `keys:"search"`
+	Select        selectKeyMap         `keys:"select"`
+	Input         inputKeyMap          `keys:"input"`
+	Compare       compareKeyMap        `keys:"compare"`
+	GlobalSearch  globalSearchKeyMap   `keys:"global_search"`
+	Confirm       layout.ConfirmKeyMap `keys:"confirm"`
+	SelectDialog  layout.SelectKeyMap  `keys:"select_dialog"`
+	Form          layout.FormKeyMap    `keys:"form"`
+	Palette       layout.PaletteKeyMap `keys:"palette"`
+	Prompt        layout.PromptKeyMap  `keys:"prompt"`
+}
+
+// DefaultKeyMaps returns the built-in bindings.
+func DefaultKeyMaps() KeyMaps {
+	return KeyMaps{
+		Global:        DefaultKeyMap,
+		Sidebar:       DefaultSidebarKeyMap,
+		Conversations: DefaultConversationsKeyMap,
+		Models:        DefaultModelsKeyMap,
+		Main:          DefaultMainKeyMap,
+		Search:        DefaultSearchKeyMap,
+		Select:        DefaultSelectKeyMap,
+		Input:         DefaultInputKeyMap,
+		Compare:       DefaultCompareKeyMap,
+		GlobalSearch:  DefaultGlobalSearchKeyMap,
+		Confirm:       layout.DefaultConfirmKeyMap,
+		SelectDialog:  layout.DefaultSelectKeyMap,
+		Form:          layout.DefaultFormKeyMap,
+		Palette:       layout.DefaultPaletteKeyMap,
+		Prompt:        layout.DefaultPromptKeyMap,
+	}
+}
+
+// keyPresets rebind actions on top of the defaults. The vim preset adds
+// ctrl+j/ctrl+k to lists with a text input, y/n to confirmations and i/a to
+// start writing.
+var keyPresets = map[string]map[string][]string{
+	"default": nil,
+	"vim": {
+		"global.focus_next":  {"tab", "ctrl+w"},
+		"main.create":        {"i"},
+		"main.show_dialog":   {"a"},
+		"conversations.open": {"enter", "o"},
+		"confirm.confirm":    {"enter", "y"},
+		"confirm.cancel":     {"esc", "n"},
+		"form.next":          {"tab", "down", "ctrl+j"},
+		"form.prev":          {"shift+tab", "up", "ctrl+k"},
+		"palette.up":         {"up", "ctrl+p", "ctrl+k"},
+		"palette.down":       {"down", "ctrl+n", "ctrl+j"},
+		"global_search.up":   {"up", "ctrl+p", "ctrl+k"},
+		"global_search.down": {"down", "ctrl+n", "ctrl+j"},
+		"select_dialog.up":   {"up", "k", "ctrl+k"},
+		"select_dialog.down": {"down", "j", "ctrl+j"},
+	},
+}
+
+// keyContexts lists scopes that are active at the same time, so their
+// actions must not share keys. Every scope is also checked on its own.
+// Modes that take the keyboard, like search or selection, only share it with
+// quit; the keys they take over from their pane are theirs by design.
+var keyContexts = [][]string{
+	{"global", "main"},
+	{"global", "sidebar"},
+	{"global", "conversations"},
+	{"global", "models"},
+	{"global.quit", "search"},
+	{"global.quit", "select"},
+	{"global.quit", "input"},
+	{"global.quit", "prompt"},
+}
+
+// LoadKeyMaps builds the key bindings configured in cfg: the preset, then
+// the prompt keys, then single rebound actions. It fails on unknown presets
+// or actions and on actions active at the same time sharing a key.
+func LoadKeyMaps(cfg config.Config) (KeyMaps, error) {
+	km := DefaultKeyMaps()
+	bindings := km.bindings()
+
+	preset := cfg.Keys.Preset
+	if preset == "" {
+		preset = "default"
+	}
+	overrides, ok := keyPresets[preset]
+	if !ok {
+		return km, fmt.Errorf("unknown key preset %q", preset)
+	}
+	for name, keys := range overrides {
+		if b, ok := bindings[name]; ok {
+			rebind(b, keys)
+		}
+	}
+
+	for name, keys := range map[string][]string{
+		"prompt.submit":  cfg.Prompt.SubmitKeys,
+		"prompt.newline": cfg.Prompt.NewlineKeys,
+		"prompt.editor":  cfg.Prompt.EditorKeys,
+	} {
+		if len(keys) > 0 {
+			bindings[name].SetKeys(keys...)
+			bindings[name].SetHelp(keys[0], bindings[name].Help().Desc)
+		}
+	}
+
+	var errs []error
+	for _, name := range sortedKeys(cfg.Keys.Bindings) {
+		b, ok := bindings[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key binding %q", name))
+			continue
+		}
+		rebind(b, cfg.Keys.Bindings[name])
+	}
+	if len(errs) > 0 {
+		return km, errors.Join(errs...)
+	}
+	return km, km.conflicts()
+}
+
+// rebind sets the keys of b, and the keys shown in help. No keys disable b.
+func rebind(b *key.Binding, keys []string) {
+	if len(keys) == 0 {
+		b.SetKeys()
+		b.SetEnabled(false)
+		return
+	}
+	b.SetKeys(keys...)
+	b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+	b.SetEnabled(true)
+}
+
+// bindings returns every binding of km by its "scope.action" name. The
+// action is the snake cased field name of the binding.
+func (km *KeyMaps) bindings() map[string]*key.Binding {
+	out := make(map[string]*key.Binding)
+	v := reflect.ValueOf(km).Elem()
+	for i := range v.NumField() {
+		scope := v.Type().Field(i).Tag.Get("keys")
+		keys := v.Field(i)
+		for j := range keys.NumField() {
+			if b, ok := keys.Field(j).Addr().Interface().(*key.Binding); ok {
+				out[scope+"."+snakeCase(keys.Type().Field(j).Name)] = b
+			}
+		}
+	}
+	return out
+}
+
+// conflicts reports keys bound to more than one action of a context.
+func (km *KeyMaps) conflicts() error {
+	bindings := km.bindings()
+	contexts := append([][]string(nil), keyContexts...)
+	scopes := make(map[string]bool)
+	for name := range bindings {
+		scope, _, _ := strings.Cut(name, ".")
+		if !scopes[scope] {
+			scopes[scope] = true
+			contexts = append(contexts, []string{scope})
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, context := range contexts {
+		actions := make(map[string][]string)
+		for _, name := range sortedKeys(bindings) {
+			if !inContext(name, context) || !bindings[name].Enabled() {
+				continue
+			}
+			for _, k := range bindings[name].Keys() {
+				actions[k] = append(actions[k], name)
+			}
+		}
+		for k, names := range actions {
+			if len(names) > 1 {
+				found[fmt.Sprintf("key %q is bound to %s", k, strings.Join(names, " and "))] = true
+			}
+		}
+	}
+
+	var errs []error
+	for _, msg := range sortedKeys(found) {
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// inContext reports whether the action name belongs to context, which
+// lists whole scopes or single actions.
+func inContext(name string, context []string) bool {
+	scope, _, _ := strings.Cut(name, ".")
+	for _, c := range context {
+		if c == scope || c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/config"
+)
+
+func TestLoadKeyMaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   string
+		bindings map[string][]string
+		wantErr  string
+	}{
+		{name: "defaults"},
+		{name: "vim", preset: "vim"},
+		{name: "rebind", bindings: map[string][]string{"global.quit": {"ctrl+q"}, "confirm.cancel": {"n"}}},
+		{name: "unknown preset", preset: "emacs", wantErr: `unknown key preset "emacs"`},
+		{name: "unknown action", bindings: map[string][]string{"main.fly": {"f"}}, wantErr: `unknown key binding "main.fly"`},
+		{name: "same scope", bindings: map[string][]string{"main.compact": {"y"}}, wantErr: `key "y" is bound to main.compact and main.yank`},
+		{name: "global and pane", bindings: map[string][]string{"global.search": {"x"}}, wantErr: `key "x" is bound to global.search and main.compact`},
+		{name: "quit while typing", bindings: map[string][]string{"global.quit": {"ctrl+g"}}, wantErr: `key "ctrl+g" is bound to global.quit and prompt.editor`},
+		{name: "mode overrides pane", bindings: map[string][]string{"search.clear": {"x"}}},
+		{name: "unbound", bindings: map[string][]string{"main.compact": {}, "main.yank": {"x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Keys = config.KeysConfig{Preset: tt.preset, Bindings: tt.bindings}
+			_, err := LoadKeyMaps(cfg)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("LoadKeyMaps() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("LoadKeyMaps() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadKeyMaps_Apply(t *testing.T) {
+	cfg := config.Default()
+	cfg.Prompt.SubmitKeys = []string{"ctrl+s"}
+	cfg.Keys.Preset = "vim"
+	cfg.Keys.Bindings = map[string][]string{
+		"global.quit":  {"ctrl+q"},
+		"main.persona": {},
+	}
+	km, err := LoadKeyMaps(cfg)
+	if err != nil {
+		t.Fatalf("LoadKeyMaps() error = %v", err)
+	}
+
+	quit := tea.KeyPressMsg{Code: 'q', Mod: tea.ModCtrl}
+	if !key.Matches(quit, km.Global.Quit) || km.Global.Quit.Help().Key != "ctrl+q" {
+		t.Errorf("Quit = %v (%q), want ctrl+q", km.Global.Quit.Keys(), km.Global.Quit.Help().Key)
+	}
+	if km.Main.Persona.Enabled() {
+		t.Error("unbound main.persona is still enabled")
+	}
+	if !reflect.DeepEqual(km.Main.Create.Keys(), []string{"i"}) {
+		t.Errorf("vim main.create = %v, want [i]", km.Main.Create.Keys())
+	}
+	if !reflect.DeepEqual(km.Prompt.Submit.Keys(), []string{"ctrl+s"}) {
+		t.Errorf("prompt.submit = %v, want the prompt config keys", km.Prompt.Submit.Keys())
+	}
+	// The defaults are left alone
+	if !reflect.DeepEqual(DefaultKeyMap.Quit.Keys(), []string{"ctrl+c"}) {
+		t.Errorf("DefaultKeyMap.Quit = %v after loading", DefaultKeyMap.Quit.Keys())
+	}
+}
+
+func TestKeyPresets_KnownActions(t *testing.T) {
+	km := DefaultKeyMaps()
+	bindings := km.bindings()
+	for preset, overrides := range keyPresets {
+		for name := range overrides {
+			if _, ok := bindings[name]; !ok {
+				t.Errorf("preset %s rebinds unknown action %q", preset, name)
+			}
+		}
+	}
+}
@@ -2,45 +2,53 @@ package layout
 
 import (
 	"github.com/charmbracelet/bubbles/v2/key"
-	"github.com/charmbracelet/bubbles/v2/textarea"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 )
 
-// ConfirmedMsg is sent when the user confirms the dialog
-type ConfirmedMsg struct{}
+// ConfirmedMsg is sent when the user confirms the dialog. ID is the one
+// given in ShowConfirmDialogMsg.
+type ConfirmedMsg struct {
+	ID string
+}
 
 // CancelledMsg is sent when the user cancels the dialog
-type CancelledMsg struct{}
+type CancelledMsg struct {
+	ID string
+}
 
 // ConfirmDialog is an example layer implementation
 type ConfirmDialog struct {
 	focused bool
 	width   int
 	height  int
+	id      string
 	text    string
-	keys    struct {
-		Confirm key.Binding
-		Cancel  key.Binding
+	keys    ConfirmKeyMap
+	styles  Styles
+}
+
+// NewConfirmDialog creates a new confirmation dialog. Its answer carries id
+// so the component that asked can tell it apart.
+func NewConfirmDialog(id, text string) *ConfirmDialog {
+	return &ConfirmDialog{
+		id:     id,
+		text:   text,
+		keys:   DefaultConfirmKeyMap,
+		styles: DefaultStyles,
 	}
 }
 
-// NewConfirmDialog creates a new confirmation dialog
-func NewConfirmDialog(text string) *ConfirmDialog {
-	cd := &ConfirmDialog{
-		text: text,
-	}
-
-	cd.keys.Confirm = key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "confirm"),
-	)
-	cd.keys.Cancel = key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "cancel"),
-	)
+// WithKeys replaces the dialog's key bindings.
+func (c *ConfirmDialog) WithKeys(keys ConfirmKeyMap) *ConfirmDialog {
+	c.keys = keys
+	return c
+}
 
-	return cd
+// WithStyles replaces the styles the dialog draws with.
+func (c *ConfirmDialog) WithStyles(styles Styles) *ConfirmDialog {
+	c.styles = styles
+	return c
 }
 
 // Init implements tea.Model
@@ -54,9 +62,9 @@ func (c *ConfirmDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch {
 		case key.Matches(msg, c.keys.Confirm):
-			return c, func() tea.Msg { return ConfirmedMsg{} }
+			return c, func() tea.Msg { return ConfirmedMsg{ID: c.id} }
 		case key.Matches(msg, c.keys.Cancel):
-			return c, func() tea.Msg { return CancelledMsg{} }
+			return c, func() tea.Msg { return CancelledMsg{ID: c.id} }
 		}
 	}
 	return c, nil
@@ -64,18 +72,12 @@ func (c *ConfirmDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View implements tea.Model
 func (c *ConfirmDialog) View() string {
-	style := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
-		Padding(1, 2).
-		Width(40).
-		Align(lipgloss.Center).
-		Foreground(lipgloss.Color("15"))
+	style := c.styles.DialogBox.Width(40).Align(lipgloss.Center)
 
 	content := c.text + "\n\n" +
-		lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("[Enter]") +
+		c.styles.ConfirmKey.Render("["+c.keys.Confirm.Help().Key+"]") +
 		" Confirm • " +
-		lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("[Esc]") +
+		c.styles.CancelKey.Render("["+c.keys.Cancel.Help().Key+"]") +
 		" Cancel"
 
 	return style.Render(content)
@@ -123,7 +125,7 @@ func (c *ConfirmDialog) LayerMeta() LayerMeta {
 		Z:           100,
 		Modal:       true,
 		CaptureKeys: true,
-		DismissKeys: []string{"esc"},
+		DismissKeys: keyNames(c.keys.Cancel),
 		Scrim:       true,
 		Pos: Position{
 			Anchor: Center,
@@ -131,117 +133,6 @@ func (c *ConfirmDialog) LayerMeta() LayerMeta {
 	}
 }
 
-// PromptDialog is a modal layer to capture a prompt from the user
-type PromptDialog struct {
-	focused bool
-	width   int
-	height  int
-	input   textarea.Model
-	keys    struct {
-		Submit key.Binding
-		Cancel key.Binding
-	}
-}
-
-func NewPromptDialog(initial string) *PromptDialog {
-	ti := textarea.New()
-	ti.Placeholder = "Type your message..."
-	ti.SetValue(initial)
-	ti.Focus()
-
-	pd := &PromptDialog{input: ti}
-	pd.keys.Submit = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "send"))
-	pd.keys.Cancel = key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel"))
-	return pd
-}
-
-func (p *PromptDialog) Init() tea.Cmd { return textarea.Blink }
-
-func (p *PromptDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch m := msg.(type) {
-	case tea.KeyPressMsg:
-		switch {
-		case key.Matches(m, p.keys.Submit):
-			text := p.input.Value()
-			return p, func() tea.Msg { return PromptSubmittedMsg{Text: text} }
-		case key.Matches(m, p.keys.Cancel):
-			return p, func() tea.Msg { return CancelledMsg{} }
-		}
-	}
-	var cmd tea.Cmd
-	p.input, cmd = p.input.Update(msg)
-	return p, cmd
-}
-
-func (p *PromptDialog) View() string {
-	style := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
-		Padding(1, 2).
-		Width(max(40, p.width/2)).
-		Align(lipgloss.Left).
-		Foreground(lipgloss.Color("15"))
-
-	controls := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("[Enter]") +
-		" Send • " +
-		lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("[Esc]") +
-		" Cancel"
-
-	return style.Render(p.input.View() + "\n\n" + controls)
-}
-
-func (p *PromptDialog) SetSize(width, height int) tea.Cmd {
-	p.width, p.height = width, height
-	boxWidth := max(40, width/2)
-	inputWidth := boxWidth - 6 // account for border and padding
-	if inputWidth < 10 {
-		inputWidth = 10
-	}
-	p.input.SetWidth(inputWidth)
-	// Aim for a few visible lines; cap to dialog height if small
-	visibleLines := 6
-	if height > 0 {
-		// Roughly estimate available height inside the box (accounting for borders and controls)
-		boxHeight := max(8, height/3)
-		if boxHeight < 8 {
-			boxHeight = 8
-		}
-		// Reserve ~3 lines for controls and padding
-		if boxHeight-3 < visibleLines {
-			visibleLines = boxHeight - 3
-			if visibleLines < 3 {
-				visibleLines = 3
-			}
-		}
-	}
-	p.input.SetHeight(visibleLines)
-	return nil
-}
-func (p *PromptDialog) GetSize() (int, int) { return p.width, p.height }
-func (p *PromptDialog) SetFocused(focused bool) (FocusScope, tea.Cmd) {
-	p.focused = focused
-	if focused {
-		p.input.Focus()
-	} else {
-		p.input.Blur()
-	}
-	return p, nil
-}
-func (p *PromptDialog) IsFocused() bool         { return p.focused }
-func (p *PromptDialog) Clone() FocusScope       { clone := *p; return &clone }
-func (p *PromptDialog) Bindings() []key.Binding { return []key.Binding{p.keys.Submit, p.keys.Cancel} }
-func (p *PromptDialog) LayerMeta() LayerMeta {
-	return LayerMeta{
-		ID:          "prompt",
-		Z:           100,
-		Modal:       true,
-		CaptureKeys: true,
-		DismissKeys: []string{"esc"},
-		Scrim:       true,
-		Pos:         Position{Anchor: Center},
-	}
-}
-
 func max(a, b int) int {
 	if a > b {
 		return a
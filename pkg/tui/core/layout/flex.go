@@ -0,0 +1,226 @@
+package layout
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+type sizeKind int
+
+const (
+	flexSize sizeKind = iota
+	fixedSize
+	percentSize
+)
+
+// Size is how much of its parent's length a node takes along the parent's
+// axis: a fixed number of cells, a percentage, or a share of what the
+// others leave. Min and Max bound the result; zero means unbounded. The
+// zero Size is Flex(1).
+type Size struct {
+	kind     sizeKind
+	value    int
+	min, max int
+}
+
+// Fixed takes n cells.
+func Fixed(n int) Size { return Size{kind: fixedSize, value: n} }
+
+// Percent takes p percent of the parent, rounded down.
+func Percent(p int) Size { return Size{kind: percentSize, value: p} }
+
+// Flex shares the cells left by fixed and percentage siblings with the
+// other flex siblings, by weight.
+func Flex(weight int) Size { return Size{kind: flexSize, value: weight} }
+
+// Min returns s taking at least n cells, room permitting.
+func (s Size) Min(n int) Size { s.min = n; return s }
+
+// Max returns s taking at most n cells.
+func (s Size) Max(n int) Size { s.max = n; return s }
+
+func (s Size) clamp(n int) int {
+	if s.max > 0 {
+		n = min(n, s.max)
+	}
+	return max(max(n, s.min), 0)
+}
+
+func (s Size) weight() int {
+	return max(1, s.value)
+}
+
+// Axis is the direction a container lays out its children.
+type Axis int
+
+const (
+	Horizontal Axis = iota
+	Vertical
+)
+
+// Node is an element of a layout tree: an item standing for a component,
+// or a row or column of nodes.
+type Node struct {
+	id       string
+	size     Size
+	axis     Axis
+	children []Node
+}
+
+// Item is a leaf of the tree, the component with id.
+func Item(id string, size Size) Node {
+	return Node{id: id, size: size}
+}
+
+// Row lays out children from left to right.
+func Row(size Size, children ...Node) Node {
+	return Node{size: size, axis: Horizontal, children: children}
+}
+
+// Column lays out children from top to bottom.
+func Column(size Size, children ...Node) Node {
+	return Node{size: size, axis: Vertical, children: children}
+}
+
+// WithID names a row or column, so that it appears in the computed
+// rectangles.
+func (n Node) WithID(id string) Node {
+	n.id = id
+	return n
+}
+
+// Compute lays the tree out in r and returns the rectangle of every node
+// with an ID. The root fills r whatever its size.
+func (n Node) Compute(r Rect) map[string]Rect {
+	rects := make(map[string]Rect)
+	n.compute(r, rects)
+	return rects
+}
+
+func (n Node) compute(r Rect, rects map[string]Rect) {
+	if n.id != "" {
+		rects[n.id] = r
+	}
+	if len(n.children) == 0 {
+		return
+	}
+
+	total := r.Width
+	if n.axis == Vertical {
+		total = r.Height
+	}
+	sizes := make([]Size, len(n.children))
+	for i, c := range n.children {
+		sizes[i] = c.size
+	}
+
+	offset := 0
+	for i, length := range Split(total, sizes...) {
+		child := Rect{X: r.X + offset, Y: r.Y, Width: length, Height: r.Height}
+		if n.axis == Vertical {
+			child = Rect{X: r.X, Y: r.Y + offset, Width: r.Width, Height: length}
+		}
+		n.children[i].compute(child, rects)
+		offset += length
+	}
+}
+
+// Apply lays the tree out in r and sends every node with an ID its size
+// through send, in tree order. It returns the rectangles and the batched
+// commands.
+func (n Node) Apply(r Rect, send func(id string, msg ComponentSizeMsg) tea.Cmd) (map[string]Rect, tea.Cmd) {
+	rects := n.Compute(r)
+	var cmds []tea.Cmd
+	n.walk(func(id string) {
+		rect := rects[id]
+		cmds = append(cmds, send(id, ComponentSizeMsg{Width: rect.Width, Height: rect.Height}))
+	})
+	return rects, tea.Batch(cmds...)
+}
+
+func (n Node) walk(visit func(id string)) {
+	if n.id != "" {
+		visit(n.id)
+	}
+	for _, c := range n.children {
+		c.walk(visit)
+	}
+}
+
+// Split divides total cells between sizes. Fixed and percentage sizes are
+// settled first, then flex sizes share the rest by weight; cells left over
+// from rounding go to the first flex sizes. When the sizes do not fit,
+// the last ones are cut short.
+func Split(total int, sizes ...Size) []int {
+	lengths := make([]int, len(sizes))
+	var flex []int
+	used := 0
+	for i, s := range sizes {
+		switch s.kind {
+		case fixedSize:
+			lengths[i] = s.clamp(s.value)
+		case percentSize:
+			lengths[i] = s.clamp(total * s.value / 100)
+		default:
+			flex = append(flex, i)
+			continue
+		}
+		used += lengths[i]
+	}
+
+	shareFlex(max(0, total-used), sizes, flex, lengths)
+
+	// Cut the overflow from the end
+	left := total
+	for i := range lengths {
+		lengths[i] = max(0, min(lengths[i], left))
+		left -= lengths[i]
+	}
+	return lengths
+}
+
+// shareFlex divides free cells between the flex sizes at indexes. A size
+// its bounds hold back keeps its bounded length and the others share the
+// rest again.
+func shareFlex(free int, sizes []Size, indexes []int, lengths []int) {
+	for len(indexes) > 0 {
+		weights := 0
+		for _, i := range indexes {
+			weights += sizes[i].weight()
+		}
+		var open []int
+		left := free
+		for _, i := range indexes {
+			share := free * sizes[i].weight() / weights
+			lengths[i] = sizes[i].clamp(share)
+			left -= lengths[i]
+			if lengths[i] == share {
+				open = append(open, i)
+			}
+		}
+		if len(open) == len(indexes) {
+			// Rounding leftovers go to the first sizes with room for them
+			for gave := true; left > 0 && gave; {
+				gave = false
+				for _, i := range open {
+					if left == 0 || sizes[i].max > 0 && lengths[i] >= sizes[i].max {
+						continue
+					}
+					lengths[i]++
+					left--
+					gave = true
+				}
+			}
+			return
+		}
+		// Settle the bounded sizes and share again among the others
+		for _, i := range indexes {
+			if !slices.Contains(open, i) {
+				free -= lengths[i]
+			}
+		}
+		free = max(0, free)
+		indexes = open
+	}
+}
@@ -0,0 +1,80 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		sizes []Size
+		want  []int
+	}{
+		{"fixed and flex", 100, []Size{Fixed(30), Flex(1)}, []int{30, 70}},
+		{"percent", 100, []Size{Percent(25), Flex(1)}, []int{25, 75}},
+		{"weights", 30, []Size{Flex(4), Flex(1), Flex(1)}, []int{20, 5, 5}},
+		{"rounding to the first", 31, []Size{Flex(1), Flex(1), Flex(1)}, []int{11, 10, 10}},
+		{"zero value flexes", 10, []Size{{}, Flex(1)}, []int{5, 5}},
+		{"min", 100, []Size{Percent(10).Min(16), Flex(1)}, []int{16, 84}},
+		{"max", 100, []Size{Fixed(90).Max(60), Flex(1)}, []int{60, 40}},
+		{"flex max", 100, []Size{Flex(1).Max(20), Flex(1)}, []int{20, 80}},
+		{"flex min", 100, []Size{Flex(1).Min(70), Flex(1), Flex(1)}, []int{70, 15, 15}},
+		{"leftovers skip a full size", 11, []Size{Flex(1).Max(5), Flex(1)}, []int{5, 6}},
+		{"overflow cut from the end", 50, []Size{Fixed(30), Fixed(30), Flex(1)}, []int{30, 20, 0}},
+		{"hidden", 40, []Size{Fixed(0), Flex(1)}, []int{0, 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.total, tt.sizes...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split(%d) = %v, want %v", tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNode_Compute(t *testing.T) {
+	tree := Column(Flex(1),
+		Row(Flex(1),
+			Item("side", Fixed(20)),
+			Column(Flex(1),
+				Item("main", Flex(1)),
+				Item("input", Fixed(3)),
+			).WithID("right"),
+		),
+		Item("status", Fixed(1)),
+	)
+	want := map[string]Rect{
+		"side":   {X: 0, Y: 0, Width: 20, Height: 23},
+		"right":  {X: 20, Y: 0, Width: 60, Height: 23},
+		"main":   {X: 20, Y: 0, Width: 60, Height: 20},
+		"input":  {X: 20, Y: 20, Width: 60, Height: 3},
+		"status": {X: 0, Y: 23, Width: 80, Height: 1},
+	}
+	if got := tree.Compute(Rect{Width: 80, Height: 24}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compute = %v, want %v", got, want)
+	}
+}
+
+func TestNode_Apply(t *testing.T) {
+	tree := Row(Flex(1), Item("a", Percent(50)), Item("b", Flex(1)))
+	var got []string
+	var sizes []ComponentSizeMsg
+	_, cmd := tree.Apply(Rect{Width: 9, Height: 2}, func(id string, msg ComponentSizeMsg) tea.Cmd {
+		got = append(got, id)
+		sizes = append(sizes, msg)
+		return func() tea.Msg { return id }
+	})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sent to %v, want %v", got, want)
+	}
+	if want := []ComponentSizeMsg{{Width: 4, Height: 2}, {Width: 5, Height: 2}}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("sizes = %v, want %v", sizes, want)
+	}
+	if batch, ok := cmd().(tea.BatchMsg); !ok || len(batch) != 2 {
+		t.Errorf("commands were not batched: %v", cmd())
+	}
+}
@@ -2,6 +2,7 @@ package layout
 
 import (
 	"errors"
+	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 
@@ -10,7 +11,12 @@ import (
 
 // FocusChangedMsg is a message used to signal that the focused component has changed.
 // It is used to trigger updates in other components, such as the help view in the status bar.
-type FocusChangedMsg struct{}
+// From and To are the paths of the focused leaves before and after, like
+// "sidebar/models"; From is empty when nothing had focus.
+type FocusChangedMsg struct {
+	From string
+	To   string
+}
 
 type Focusable interface {
 	components.Component
@@ -20,36 +26,121 @@ type Focusable interface {
 	Clone() Focusable
 }
 
+// FocusContainer is implemented by components that hold focusable children
+// of their own, usually in a FocusManager, making the focus a tree.
+type FocusContainer interface {
+	Focusable
+
+	// FocusedPath returns the path of the focused leaf below the container,
+	// relative to it, or "" when it has none.
+	FocusedPath() string
+	// MoveFocus moves the focus among the children in dir. It reports false
+	// when there is no child that way, leaving the move to the parent.
+	MoveFocus(dir Direction) (Focusable, tea.Cmd, bool)
+}
+
+// KeyHandler is implemented by components that leave some keys to their
+// ancestors. Components without it take every key while focused.
+type KeyHandler interface {
+	HandlesKey(msg tea.KeyPressMsg) bool
+}
+
+// Direction is a way to move the focus on screen
+type Direction int
+
+const (
+	Left Direction = iota
+	Down
+	Up
+	Right
+)
+
+// pathSep separates the IDs of a focus path
+const pathSep = "/"
+
+type focusEntry struct {
+	id        string
+	component Focusable
+	bounds    Rect
+}
+
+// FocusManager keeps focusable components by stable IDs and tracks which
+// one has focus. Components are added and removed at runtime; Rects set
+// with SetBounds make directional movement possible.
 type FocusManager struct {
-	components   []Focusable
+	scope        string
+	entries      []focusEntry
 	focusedIndex int
 	wrap         bool
 }
 
-func NewFocusManager(components []Focusable, wrap bool) FocusManager {
+// NewFocusManager creates an empty manager. Scope prefixes the paths in
+// the FocusChangedMsg it sends; it is the path of the container holding
+// the manager, or empty at the root.
+func NewFocusManager(scope string, wrap bool) FocusManager {
 	return FocusManager{
-		components:   cloneComponents(components),
+		scope:        scope,
 		focusedIndex: -1,
 		wrap:         wrap,
 	}
 }
 
-func cloneComponents(cs []Focusable) []Focusable {
-	cloned := make([]Focusable, len(cs))
-	for i, c := range cs {
-		cloned[i] = c.Clone()
+// Add appends component under id. IDs must be unique within the manager.
+func (fm FocusManager) Add(id string, component Focusable) (FocusManager, error) {
+	if fm.index(id) >= 0 {
+		return fm, fmt.Errorf("focusable %q already exists", id)
+	}
+	newFM := fm.Clone()
+	newFM.entries = append(newFM.entries, focusEntry{id: id, component: component.Clone()})
+	return newFM, nil
+}
+
+// Remove drops the component with id. When it had focus, the focus moves
+// to the component before it, or the next one if it was first.
+func (fm FocusManager) Remove(id string) (FocusManager, tea.Cmd) {
+	i := fm.index(id)
+	if i < 0 {
+		return fm, nil
+	}
+	from := fm.FocusedPath()
+	newFM := fm.Clone()
+	newFM.entries = append(newFM.entries[:i], newFM.entries[i+1:]...)
+
+	switch {
+	case i > fm.focusedIndex:
+		return newFM, nil
+	case i < fm.focusedIndex:
+		newFM.focusedIndex--
+		return newFM, nil
+	}
+	newFM.focusedIndex = -1
+	if len(newFM.entries) == 0 {
+		return newFM, newFM.changed(from)
+	}
+	next := max(0, i-1)
+	var cmd tea.Cmd
+	newFM.entries[next].component, cmd = newFM.entries[next].component.SetFocused(true)
+	newFM.focusedIndex = next
+	return newFM, tea.Batch(cmd, newFM.changed(from))
+}
+
+func (fm FocusManager) index(id string) int {
+	for i, e := range fm.entries {
+		if e.id == id {
+			return i
+		}
 	}
-	return cloned
+	return -1
 }
 
 func (fm FocusManager) blurCurrent() (FocusManager, tea.Cmd) {
-	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.components) {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
 		return fm, nil
 	}
 
 	newFM := fm
 	var cmd tea.Cmd
-	newFM.components[newFM.focusedIndex], cmd = newFM.components[newFM.focusedIndex].SetFocused(false)
+	newFM.entries[newFM.focusedIndex].component, cmd = newFM.entries[newFM.focusedIndex].component.SetFocused(false)
 	return newFM, cmd
 }
 
@@ -60,6 +151,7 @@ func (fm FocusManager) focus(index int) (FocusManager, tea.Cmd) {
 	if fm.focusedIndex == index && fm.focusedIndex != -1 {
 		return fm, nil // No change, no command.
 	}
+	from := fm.FocusedPath()
 
 	// Get a blurred manager and the blur command.
 	blurredFM, blurCmd := fm.blurCurrent()
@@ -68,16 +160,20 @@ func (fm FocusManager) focus(index int) (FocusManager, tea.Cmd) {
 	focusedFM := blurredFM
 	focusedFM.focusedIndex = index
 	var focusCmd tea.Cmd
-	focusedFM.components[focusedFM.focusedIndex], focusCmd = focusedFM.components[focusedFM.focusedIndex].SetFocused(true)
+	focusedFM.entries[focusedFM.focusedIndex].component, focusCmd = focusedFM.entries[focusedFM.focusedIndex].component.SetFocused(true)
 
-	// Command to signal that focus has changed.
-	focusChangedCmd := func() tea.Msg { return FocusChangedMsg{} }
+	return focusedFM, tea.Batch(blurCmd, focusCmd, focusedFM.changed(from))
+}
 
-	return focusedFM, tea.Batch(blurCmd, focusCmd, focusChangedCmd)
+// changed returns the command signalling that the focus moved away from
+// the leaf at path from.
+func (fm FocusManager) changed(from string) tea.Cmd {
+	msg := FocusChangedMsg{From: from, To: fm.FocusedPath()}
+	return func() tea.Msg { return msg }
 }
 
 func (fm FocusManager) FocusNext() (FocusManager, tea.Cmd) {
-	if len(fm.components) == 0 {
+	if len(fm.entries) == 0 {
 		return fm, nil
 	}
 
@@ -85,21 +181,21 @@ func (fm FocusManager) FocusNext() (FocusManager, tea.Cmd) {
 		return fm.focus(0)
 	}
 
-	if fm.focusedIndex == len(fm.components)-1 && !fm.wrap {
+	if fm.focusedIndex == len(fm.entries)-1 && !fm.wrap {
 		return fm, nil
 	}
 
-	nextIndex := (fm.focusedIndex + 1) % len(fm.components)
+	nextIndex := (fm.focusedIndex + 1) % len(fm.entries)
 	return fm.focus(nextIndex)
 }
 
 func (fm FocusManager) FocusPrev() (FocusManager, tea.Cmd) {
-	if len(fm.components) == 0 {
+	if len(fm.entries) == 0 {
 		return fm, nil
 	}
 
 	if fm.focusedIndex == -1 {
-		return fm.focus(len(fm.components) - 1)
+		return fm.focus(len(fm.entries) - 1)
 	}
 
 	if fm.focusedIndex == 0 && !fm.wrap {
@@ -108,30 +204,127 @@ func (fm FocusManager) FocusPrev() (FocusManager, tea.Cmd) {
 
 	prevIndex := fm.focusedIndex - 1
 	if prevIndex < 0 {
-		prevIndex = len(fm.components) - 1
+		prevIndex = len(fm.entries) - 1
 	}
 	return fm.focus(prevIndex)
 }
 
-func (fm FocusManager) Focus(index int) (FocusManager, tea.Cmd, error) {
-	if index < 0 || index >= len(fm.components) {
-		return fm, nil, errors.New("index out of bounds")
+// Focus gives the focus to the component with id.
+func (fm FocusManager) Focus(id string) (FocusManager, tea.Cmd, error) {
+	index := fm.index(id)
+	if index < 0 {
+		return fm, nil, fmt.Errorf("no focusable %q", id)
 	}
 
 	newFM, cmd := fm.focus(index)
 	return newFM, cmd, nil
 }
 
+// SetBounds records where the component with id is drawn, for directional
+// movement. Components with empty bounds are skipped by Move.
+func (fm FocusManager) SetBounds(id string, bounds Rect) FocusManager {
+	if i := fm.index(id); i >= 0 {
+		fm.entries = append([]focusEntry(nil), fm.entries...)
+		fm.entries[i].bounds = bounds
+	}
+	return fm
+}
+
+// Move moves the focus in dir: first within the focused component if it is
+// a FocusContainer, then to the nearest component that way. It reports
+// false when there is none, leaving the move to an enclosing manager.
+func (fm FocusManager) Move(dir Direction) (FocusManager, tea.Cmd, bool) {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
+		return fm, nil, false
+	}
+	if c, ok := fm.entries[fm.focusedIndex].component.(FocusContainer); ok {
+		// The container's own manager reports the change
+		if updated, cmd, moved := c.MoveFocus(dir); moved {
+			newFM := fm.Clone()
+			newFM.entries[fm.focusedIndex].component = updated.Clone()
+			return newFM, cmd, true
+		}
+	}
+
+	target := fm.nearest(dir)
+	if target < 0 {
+		return fm, nil, false
+	}
+	newFM, cmd := fm.focus(target)
+	return newFM, cmd, true
+}
+
+// nearest returns the index of the closest component in dir from the
+// focused one that shares some rows or columns with it, or -1.
+func (fm FocusManager) nearest(dir Direction) int {
+	from := fm.entries[fm.focusedIndex].bounds
+	best, bestDist, bestOverlap := -1, 0, 0
+	for i, e := range fm.entries {
+		to := e.bounds
+		if i == fm.focusedIndex || to.Width <= 0 || to.Height <= 0 {
+			continue
+		}
+		var dist, overlap int
+		switch dir {
+		case Left:
+			dist, overlap = from.X-(to.X+to.Width), span(from.Y, from.Height, to.Y, to.Height)
+		case Right:
+			dist, overlap = to.X-(from.X+from.Width), span(from.Y, from.Height, to.Y, to.Height)
+		case Up:
+			dist, overlap = from.Y-(to.Y+to.Height), span(from.X, from.Width, to.X, to.Width)
+		case Down:
+			dist, overlap = to.Y-(from.Y+from.Height), span(from.X, from.Width, to.X, to.Width)
+		}
+		if dist < 0 || overlap <= 0 {
+			continue
+		}
+		if best < 0 || dist < bestDist || dist == bestDist && overlap > bestOverlap {
+			best, bestDist, bestOverlap = i, dist, overlap
+		}
+	}
+	return best
+}
+
+// span returns how far the ranges [a, a+al) and [b, b+bl) overlap.
+func span(a, al, b, bl int) int {
+	return min(a+al, b+bl) - max(a, b)
+}
+
+// UpdateKey gives a key press to the focused component, unless it is a
+// KeyHandler leaving the key to its ancestors. It reports whether the key
+// was taken; if not, the caller handles it itself or passes it up.
+func (fm FocusManager) UpdateKey(msg tea.KeyPressMsg) (FocusManager, tea.Cmd, bool) {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
+		return fm, nil, false
+	}
+	if h, ok := fm.entries[fm.focusedIndex].component.(KeyHandler); ok && !h.HandlesKey(msg) {
+		return fm, nil, false
+	}
+	newFM, cmd := fm.UpdateFocused(msg)
+	return newFM, cmd, true
+}
+
+// HandlesKey reports whether the focused component takes msg.
+func (fm FocusManager) HandlesKey(msg tea.KeyPressMsg) bool {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
+		return false
+	}
+	if h, ok := fm.entries[fm.focusedIndex].component.(KeyHandler); ok {
+		return h.HandlesKey(msg)
+	}
+	return true
+}
+
 func (fm FocusManager) UpdateFocused(msg tea.Msg) (FocusManager, tea.Cmd) {
-	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.components) {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
 		return fm, nil
 	}
 
-	newFM := fm
-	updatedModel, cmd := newFM.components[newFM.focusedIndex].Update(msg)
+	newFM := fm.Clone()
+	updatedModel, cmd := newFM.entries[newFM.focusedIndex].component.Update(msg)
 
 	if updatedFocusable, ok := updatedModel.(Focusable); ok {
-		newFM.components[newFM.focusedIndex] = updatedFocusable.Clone()
+		newFM.entries[newFM.focusedIndex].component = updatedFocusable.Clone()
 	}
 
 	return newFM, cmd
@@ -139,12 +332,12 @@ func (fm FocusManager) UpdateFocused(msg tea.Msg) (FocusManager, tea.Cmd) {
 
 func (fm FocusManager) UpdateAll(msg tea.Msg) (FocusManager, tea.Cmd) {
 	var cmds []tea.Cmd
-	newFM := fm
+	newFM := fm.Clone()
 
-	for i, component := range newFM.components {
-		updatedModel, cmd := component.Update(msg)
+	for i, e := range newFM.entries {
+		updatedModel, cmd := e.component.Update(msg)
 		if updatedFocusable, ok := updatedModel.(Focusable); ok {
-			newFM.components[i] = updatedFocusable.Clone()
+			newFM.entries[i].component = updatedFocusable.Clone()
 		}
 		cmds = append(cmds, cmd)
 	}
@@ -152,36 +345,85 @@ func (fm FocusManager) UpdateAll(msg tea.Msg) (FocusManager, tea.Cmd) {
 	return newFM, tea.Batch(cmds...)
 }
 
-func (fm FocusManager) Get(index int) (Focusable, error) {
-	if index < 0 || index >= len(fm.components) {
-		return nil, errors.New("index out of bounds")
+// Get returns the component with id.
+func (fm FocusManager) Get(id string) (Focusable, error) {
+	index := fm.index(id)
+	if index < 0 {
+		return nil, fmt.Errorf("no focusable %q", id)
 	}
-	return fm.components[index].Clone(), nil
+	return fm.entries[index].component.Clone(), nil
+}
+
+// IDs returns the IDs of the components, in order.
+func (fm FocusManager) IDs() []string {
+	ids := make([]string, len(fm.entries))
+	for i, e := range fm.entries {
+		ids[i] = e.id
+	}
+	return ids
 }
 
 func (fm FocusManager) GetAll() []Focusable {
-	return cloneComponents(fm.components)
+	all := make([]Focusable, len(fm.entries))
+	for i, e := range fm.entries {
+		all[i] = e.component.Clone()
+	}
+	return all
 }
 
 func (fm FocusManager) GetFocused() (Focusable, error) {
-	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.components) {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
 		return nil, errors.New("no component is focused")
 	}
-	return fm.components[fm.focusedIndex].Clone(), nil
+	return fm.entries[fm.focusedIndex].component.Clone(), nil
 }
 
-func (fm FocusManager) Set(index int, component Focusable) (FocusManager, error) {
-	if index < 0 || index >= len(fm.components) {
-		return fm, errors.New("index out of bounds")
+// FocusedID returns the ID of the focused component, or "".
+func (fm FocusManager) FocusedID() string {
+	if fm.focusedIndex < 0 || fm.focusedIndex >= len(fm.entries) {
+		return ""
+	}
+	return fm.entries[fm.focusedIndex].id
+}
+
+// FocusedPath returns the path of the focused leaf: the scope, the ID of
+// the focused component and, for a FocusContainer, the path within it.
+func (fm FocusManager) FocusedPath() string {
+	id := fm.FocusedID()
+	if id == "" {
+		return ""
+	}
+	path := id
+	if fm.scope != "" {
+		path = fm.scope + pathSep + id
+	}
+	if c, ok := fm.entries[fm.focusedIndex].component.(FocusContainer); ok {
+		if inner := c.FocusedPath(); inner != "" {
+			path += pathSep + inner
+		}
+	}
+	return path
+}
+
+// Set replaces the component with id.
+func (fm FocusManager) Set(id string, component Focusable) (FocusManager, error) {
+	index := fm.index(id)
+	if index < 0 {
+		return fm, fmt.Errorf("no focusable %q", id)
 	}
 	newFM := fm.Clone()
-	newFM.components[index] = component.Clone()
+	newFM.entries[index].component = component.Clone()
 	return newFM, nil
 }
 
 func (fm FocusManager) Clone() FocusManager {
+	entries := make([]focusEntry, len(fm.entries))
+	for i, e := range fm.entries {
+		entries[i] = focusEntry{id: e.id, component: e.component.Clone(), bounds: e.bounds}
+	}
 	return FocusManager{
-		components:   cloneComponents(fm.components),
+		scope:        fm.scope,
+		entries:      entries,
 		focusedIndex: fm.focusedIndex,
 		wrap:         fm.wrap,
 	}
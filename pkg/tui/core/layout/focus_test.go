@@ -0,0 +1,217 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// testFocusable records its focus and the keys it is sent. It takes only
+// the keys in handles when that is set.
+type testFocusable struct {
+	focused bool
+	handles []string
+	keys    []string
+}
+
+func (f testFocusable) Init() tea.Cmd { return nil }
+func (f testFocusable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if k, ok := msg.(tea.KeyPressMsg); ok {
+		f.keys = append(append([]string(nil), f.keys...), k.String())
+	}
+	return f, nil
+}
+func (f testFocusable) View() string                           { return "" }
+func (f testFocusable) SetFocused(b bool) (Focusable, tea.Cmd) { f.focused = b; return f, nil }
+func (f testFocusable) IsFocused() bool                        { return f.focused }
+func (f testFocusable) Clone() Focusable                       { return f }
+func (f testFocusable) HandlesKey(msg tea.KeyPressMsg) bool {
+	for _, k := range f.handles {
+		if k == msg.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// testContainer holds a manager of its own
+type testContainer struct {
+	testFocusable
+	fm FocusManager
+}
+
+func (c testContainer) SetFocused(b bool) (Focusable, tea.Cmd) { c.focused = b; return c, nil }
+func (c testContainer) Clone() Focusable                       { c.fm = c.fm.Clone(); return c }
+func (c testContainer) FocusedPath() string                    { return c.fm.FocusedID() }
+func (c testContainer) MoveFocus(dir Direction) (Focusable, tea.Cmd, bool) {
+	var cmd tea.Cmd
+	var moved bool
+	c.fm, cmd, moved = c.fm.Move(dir)
+	return c, cmd, moved
+}
+
+func mustAdd(t *testing.T, fm FocusManager, id string, c Focusable) FocusManager {
+	t.Helper()
+	fm, err := fm.Add(id, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fm
+}
+
+func TestFocusManager_AddRemove(t *testing.T) {
+	fm := NewFocusManager("", false)
+	for _, id := range []string{"a", "b", "c"} {
+		fm = mustAdd(t, fm, id, testFocusable{})
+	}
+	if _, err := fm.Add("b", testFocusable{}); err == nil {
+		t.Error("adding a duplicate ID succeeded")
+	}
+
+	fm, _, _ = fm.Focus("b")
+	fm, cmd := fm.Remove("b")
+	if got := fm.FocusedID(); got != "a" {
+		t.Errorf("focus after removing the focused component = %q, want a", got)
+	}
+	if msg := findFocusChanged(cmd); msg != (FocusChangedMsg{From: "b", To: "a"}) {
+		t.Errorf("removal sent %+v", msg)
+	}
+	if got, want := fm.IDs(), []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IDs = %v, want %v", got, want)
+	}
+
+	fm, _, _ = fm.Focus("c")
+	fm, _ = fm.Remove("a")
+	if got := fm.FocusedID(); got != "c" {
+		t.Errorf("focus after removing another component = %q, want c", got)
+	}
+	if c, _ := fm.Get("c"); !c.IsFocused() {
+		t.Error("focused component lost its focus")
+	}
+	if _, _, err := fm.Focus("a"); err == nil {
+		t.Error("focusing a removed component succeeded")
+	}
+}
+
+func TestFocusManager_Move(t *testing.T) {
+	// +-------+-------+
+	// | left  |  top  |
+	// |       +-------+
+	// |       |bottom |
+	// +-------+-------+
+	fm := NewFocusManager("", false)
+	fm = mustAdd(t, fm, "left", testFocusable{})
+	fm = mustAdd(t, fm, "top", testFocusable{})
+	fm = mustAdd(t, fm, "bottom", testFocusable{})
+	fm = mustAdd(t, fm, "hidden", testFocusable{})
+	fm = fm.SetBounds("left", Rect{Width: 10, Height: 10}).
+		SetBounds("top", Rect{X: 10, Width: 10, Height: 5}).
+		SetBounds("bottom", Rect{X: 10, Y: 5, Width: 10, Height: 5})
+	fm, _, _ = fm.Focus("left")
+
+	steps := []struct {
+		dir   Direction
+		want  string
+		moved bool
+	}{
+		{Right, "top", true},
+		{Down, "bottom", true},
+		{Down, "bottom", false},
+		{Left, "left", true},
+		{Up, "left", false},
+	}
+	for i, step := range steps {
+		var moved bool
+		fm, _, moved = fm.Move(step.dir)
+		if got := fm.FocusedID(); got != step.want || moved != step.moved {
+			t.Fatalf("step %d: focused %q (moved %v), want %q (moved %v)", i, got, moved, step.want, step.moved)
+		}
+	}
+}
+
+func TestFocusManager_Nested(t *testing.T) {
+	inner := NewFocusManager("side", false)
+	inner = mustAdd(t, inner, "one", testFocusable{})
+	inner = mustAdd(t, inner, "two", testFocusable{})
+	inner = inner.SetBounds("one", Rect{Width: 10, Height: 5}).SetBounds("two", Rect{Y: 5, Width: 10, Height: 5})
+	inner, _ = inner.FocusNext()
+
+	fm := NewFocusManager("", true)
+	fm = mustAdd(t, fm, "side", testContainer{fm: inner})
+	fm = mustAdd(t, fm, "main", testFocusable{})
+	fm = fm.SetBounds("side", Rect{Width: 10, Height: 10}).SetBounds("main", Rect{X: 10, Width: 10, Height: 10})
+	fm, _, _ = fm.Focus("main")
+
+	fm, cmd, _ := fm.Move(Left)
+	if got := fm.FocusedPath(); got != "side/one" {
+		t.Errorf("FocusedPath = %q, want side/one", got)
+	}
+	if msg := findFocusChanged(cmd); msg != (FocusChangedMsg{From: "main", To: "side/one"}) {
+		t.Errorf("moving into the container sent %+v", msg)
+	}
+
+	// Within the container its own manager reports the change
+	fm, cmd, _ = fm.Move(Down)
+	if msg := findFocusChanged(cmd); msg != (FocusChangedMsg{From: "side/one", To: "side/two"}) {
+		t.Errorf("moving within the container sent %+v", msg)
+	}
+
+	// Nothing is right of the lower child inside, so the outer manager moves
+	fm, _, _ = fm.Move(Right)
+	if got := fm.FocusedPath(); got != "main" {
+		t.Errorf("FocusedPath = %q, want main", got)
+	}
+}
+
+// findFocusChanged runs cmd and returns the FocusChangedMsg among its
+// messages.
+func findFocusChanged(cmd tea.Cmd) FocusChangedMsg {
+	if cmd == nil {
+		return FocusChangedMsg{}
+	}
+	switch msg := cmd().(type) {
+	case FocusChangedMsg:
+		return msg
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if found := findFocusChanged(c); found != (FocusChangedMsg{}) {
+				return found
+			}
+		}
+	}
+	return FocusChangedMsg{}
+}
+
+func TestFocusManager_UpdateKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		focused     Focusable
+		key         tea.KeyPressMsg
+		wantHandled bool
+	}{
+		{"handled", testFocusable{handles: []string{"j"}}, tea.KeyPressMsg{Code: 'j', Text: "j"}, true},
+		{"bubbles up", testFocusable{handles: []string{"j"}}, tea.KeyPressMsg{Code: 'x', Text: "x"}, false},
+		{"no focus", nil, tea.KeyPressMsg{Code: 'j', Text: "j"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := NewFocusManager("", false)
+			if tt.focused != nil {
+				fm = mustAdd(t, fm, "a", tt.focused)
+				fm, _ = fm.FocusNext()
+			}
+			fm, _, handled := fm.UpdateKey(tt.key)
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if tt.focused == nil {
+				return
+			}
+			c, _ := fm.Get("a")
+			if got := len(c.(testFocusable).keys) > 0; got != tt.wantHandled {
+				t.Errorf("component got the key = %v, want %v", got, tt.wantHandled)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// FormField describes a single input of a form dialog
+type FormField struct {
+	Name        string
+	Value       string
+	Placeholder string
+}
+
+// ShowFormDialogMsg requests opening a dialog to fill in Fields.
+// ID is echoed back in the resulting FormSubmittedMsg.
+type ShowFormDialogMsg struct {
+	ID     string
+	Title  string
+	Fields []FormField
+}
+
+// FormSubmittedMsg is emitted by the form dialog with the value of every field
+type FormSubmittedMsg struct {
+	ID     string
+	Values map[string]string
+}
+
+// FormDialog is a modal layer with one text input per field
+type FormDialog struct {
+	focused bool
+	width   int
+	height  int
+	id      string
+	title   string
+	names   []string
+	inputs  []textinput.Model
+	active  int
+	keys    FormKeyMap
+	styles  Styles
+}
+
+// NewFormDialog creates a new form dialog
+func NewFormDialog(id, title string, fields []FormField) *FormDialog {
+	fd := &FormDialog{id: id, title: title, keys: DefaultFormKeyMap, styles: DefaultStyles}
+	for _, field := range fields {
+		ti := textinput.New()
+		ti.Prompt = field.Name + ": "
+		ti.Placeholder = field.Placeholder
+		ti.SetValue(field.Value)
+		fd.names = append(fd.names, field.Name)
+		fd.inputs = append(fd.inputs, ti)
+	}
+	if len(fd.inputs) > 0 {
+		fd.inputs[0].Focus()
+	}
+	return fd
+}
+
+// WithKeys replaces the dialog's key bindings.
+func (f *FormDialog) WithKeys(keys FormKeyMap) *FormDialog {
+	f.keys = keys
+	return f
+}
+
+// WithStyles replaces the styles the dialog draws with.
+func (f *FormDialog) WithStyles(styles Styles) *FormDialog {
+	f.styles = styles
+	return f
+}
+
+func (f *FormDialog) Init() tea.Cmd { return textinput.Blink }
+
+func (f *FormDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch {
+		case key.Matches(m, f.keys.Next):
+			return f, f.focusField(f.active + 1)
+		case key.Matches(m, f.keys.Prev):
+			return f, f.focusField(f.active - 1)
+		case key.Matches(m, f.keys.Submit):
+			values := make(map[string]string, len(f.inputs))
+			for i, input := range f.inputs {
+				values[f.names[i]] = input.Value()
+			}
+			submitted := FormSubmittedMsg{ID: f.id, Values: values}
+			return f, func() tea.Msg { return submitted }
+		case key.Matches(m, f.keys.Cancel):
+			return f, func() tea.Msg { return CancelledMsg{} }
+		}
+	}
+
+	if len(f.inputs) == 0 {
+		return f, nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.active], cmd = f.inputs[f.active].Update(msg)
+	return f, cmd
+}
+
+func (f *FormDialog) focusField(index int) tea.Cmd {
+	if len(f.inputs) == 0 {
+		return nil
+	}
+	index = (index + len(f.inputs)) % len(f.inputs)
+	f.inputs[f.active].Blur()
+	f.active = index
+	return f.inputs[f.active].Focus()
+}
+
+func (f *FormDialog) View() string {
+	style := f.styles.DialogBox.Width(max(40, f.width/2)).Align(lipgloss.Left)
+
+	var rows []string
+	if f.title != "" {
+		rows = append(rows, lipgloss.NewStyle().Bold(true).Render(f.title), "")
+	}
+	for _, input := range f.inputs {
+		rows = append(rows, input.View())
+	}
+
+	controls := f.styles.ConfirmKey.Render("["+f.keys.Submit.Help().Key+"]") +
+		" Submit • " +
+		f.styles.CancelKey.Render("["+f.keys.Cancel.Help().Key+"]") +
+		" Cancel"
+	rows = append(rows, "", controls)
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+func (f *FormDialog) SetSize(width, height int) tea.Cmd {
+	f.width, f.height = width, height
+	inputWidth := max(40, width/2) - 6 // account for border and padding
+	for i := range f.inputs {
+		f.inputs[i].SetWidth(max(10, inputWidth-lipgloss.Width(f.inputs[i].Prompt)))
+	}
+	return nil
+}
+func (f *FormDialog) GetSize() (int, int) { return f.width, f.height }
+func (f *FormDialog) SetFocused(focused bool) (FocusScope, tea.Cmd) {
+	f.focused = focused
+	if len(f.inputs) > 0 {
+		if focused {
+			f.inputs[f.active].Focus()
+		} else {
+			f.inputs[f.active].Blur()
+		}
+	}
+	return f, nil
+}
+func (f *FormDialog) IsFocused() bool { return f.focused }
+func (f *FormDialog) Clone() FocusScope {
+	clone := *f
+	clone.inputs = append([]textinput.Model(nil), f.inputs...)
+	return &clone
+}
+func (f *FormDialog) Bindings() []key.Binding {
+	return []key.Binding{f.keys.Next, f.keys.Prev, f.keys.Submit, f.keys.Cancel}
+}
+func (f *FormDialog) LayerMeta() LayerMeta {
+	return LayerMeta{
+		ID:          "form",
+		Z:           100,
+		Modal:       true,
+		CaptureKeys: true,
+		DismissKeys: keyNames(f.keys.Cancel),
+		Scrim:       true,
+		Pos:         Position{Anchor: Center},
+	}
+}
@@ -0,0 +1,49 @@
+package layout
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore matches pattern against s as a case-insensitive subsequence.
+// It reports whether every pattern rune was found in order and a score that
+// is higher for tighter matches: consecutive runes and runes at the start of
+// a word earn bonuses, gaps between matched runes cost points.
+func FuzzyScore(pattern, s string) (int, bool) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return 0, true
+	}
+
+	target := []rune(strings.ToLower(s))
+	score := 0
+	prev := -1
+	ti := 0
+	for _, pr := range pattern {
+		found := false
+		for ; ti < len(target); ti++ {
+			if target[ti] != pr {
+				continue
+			}
+			switch {
+			case prev >= 0 && ti == prev+1:
+				score += 5 // consecutive
+			case ti == 0 || !unicode.IsLetter(target[ti-1]) && !unicode.IsDigit(target[ti-1]):
+				score += 3 // start of a word
+			default:
+				score++
+			}
+			if prev >= 0 {
+				score -= min(ti-prev-1, 3)
+			}
+			prev = ti
+			ti++
+			found = true
+			break
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
@@ -0,0 +1,33 @@
+package layout
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		wantOK  bool
+	}{
+		{pattern: "", s: "anything", wantOK: true},
+		{pattern: "cmp", s: "compact", wantOK: true},
+		{pattern: "CMP", s: "compact", wantOK: true},
+		{pattern: "nc", s: "new conversation", wantOK: true},
+		{pattern: "pmc", s: "compact", wantOK: false},
+		{pattern: "xyz", s: "compact", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.s, func(t *testing.T) {
+			if _, ok := FuzzyScore(tt.pattern, tt.s); ok != tt.wantOK {
+				t.Errorf("FuzzyScore(%q, %q) ok = %v, want %v", tt.pattern, tt.s, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFuzzyScore_Ranking(t *testing.T) {
+	better, _ := FuzzyScore("mod", "/model")
+	worse, _ := FuzzyScore("mod", "main: open dialog")
+	if better <= worse {
+		t.Errorf("consecutive match score %d should beat scattered match %d", better, worse)
+	}
+}
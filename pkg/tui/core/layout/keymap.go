@@ -0,0 +1,118 @@
+package layout
+
+import "github.com/charmbracelet/bubbles/v2/key"
+
+// ConfirmKeyMap is active in a ConfirmDialog
+type ConfirmKeyMap struct {
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+var DefaultConfirmKeyMap = ConfirmKeyMap{
+	Confirm: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+	Cancel:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// SelectKeyMap is active in a SelectDialog
+type SelectKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Cancel key.Binding
+}
+
+var DefaultSelectKeyMap = SelectKeyMap{
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:   key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Select: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+	Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// FormKeyMap is active in a FormDialog
+type FormKeyMap struct {
+	Next   key.Binding
+	Prev   key.Binding
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+var DefaultFormKeyMap = FormKeyMap{
+	Next:   key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "next field")),
+	Prev:   key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "previous field")),
+	Submit: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "submit")),
+	Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// PaletteKeyMap is active in the CommandPalette
+type PaletteKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Run    key.Binding
+	Cancel key.Binding
+}
+
+var DefaultPaletteKeyMap = PaletteKeyMap{
+	Up:     key.NewBinding(key.WithKeys("up", "ctrl+p"), key.WithHelp("↑", "up")),
+	Down:   key.NewBinding(key.WithKeys("down", "ctrl+n"), key.WithHelp("↓", "down")),
+	Run:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "run")),
+	Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+}
+
+// PromptKeyMap is active in a PromptInput. Cancel only applies to the
+// PromptDialog; the inline input leaves with its own key.
+type PromptKeyMap struct {
+	Submit         key.Binding
+	Newline        key.Binding
+	Editor         key.Binding
+	Complete       key.Binding
+	NextSuggestion key.Binding
+	PrevSuggestion key.Binding
+	HistoryPrev    key.Binding
+	HistoryNext    key.Binding
+	Cancel         key.Binding
+}
+
+var DefaultPromptKeyMap = PromptKeyMap{
+	Submit:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "send")),
+	Newline:        key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"), key.WithHelp("alt+enter", "newline")),
+	Editor:         key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "$EDITOR")),
+	Complete:       key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "complete")),
+	NextSuggestion: key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "next suggestion")),
+	PrevSuggestion: key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "previous suggestion")),
+	HistoryPrev:    key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "previous prompt")),
+	HistoryNext:    key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "next prompt")),
+	Cancel:         key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+}
+
+// keyNames returns the keys of b, used as a layer's DismissKeys so that
+// rebinding cancel also rebinds dismissing.
+func keyNames(b key.Binding) []string {
+	if !b.Enabled() {
+		return nil
+	}
+	return b.Keys()
+}
+
+// ListKeyMap is active in a List. Filter starts typing a filter, which
+// Accept keeps and Clear drops.
+type ListKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Select   key.Binding
+	Filter   key.Binding
+	Accept   key.Binding
+	Clear    key.Binding
+}
+
+var DefaultListKeyMap = ListKeyMap{
+	Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+	PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+	Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+	Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+	Accept:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "done filtering")),
+	Clear:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter")),
+}
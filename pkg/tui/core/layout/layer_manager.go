@@ -2,15 +2,31 @@ package layout
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 )
 
-// LayerManager manages a stack of layer overlays
+// Rect is the area a layer covers on screen
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Contains reports whether the cell at x, y lies inside r
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// LayerManager manages a stack of layer overlays, and the toasts drawn
+// above them. Toasts are not layers: they never take keys or the mouse.
 type LayerManager struct {
 	layers []Layer
+	toasts toastStack
+	styles Styles
 	width  int
 	height int
 }
@@ -19,6 +35,7 @@ type LayerManager struct {
 func NewLayerManager() *LayerManager {
 	return &LayerManager{
 		layers: make([]Layer, 0),
+		styles: DefaultStyles,
 	}
 }
 
@@ -26,10 +43,15 @@ func NewLayerManager() *LayerManager {
 func (lm *LayerManager) SetSize(w, h int) {
 	lm.width, lm.height = w, h
 	for i := range lm.layers {
-		lm.layers[i].SetSize(w, h)
+		lm.layers[i].SetSize(lm.layerSize(lm.layers[i].LayerMeta().Pos))
 	}
 }
 
+// SetStyles sets the styles of the scrim and the toasts.
+func (lm *LayerManager) SetStyles(styles Styles) {
+	lm.styles = styles
+}
+
 // Push adds a new layer to the stack
 func (lm *LayerManager) Push(l Layer) tea.Cmd {
 	// Defocus current top layer
@@ -39,7 +61,7 @@ func (lm *LayerManager) Push(l Layer) tea.Cmd {
 
 	// Focus and size the new layer
 	l.SetFocused(true)
-	l.SetSize(lm.width, lm.height)
+	l.SetSize(lm.layerSize(l.LayerMeta().Pos))
 
 	// Add to stack and sort by Z-order
 	lm.layers = append(lm.layers, l)
@@ -108,6 +130,15 @@ func (lm *LayerManager) sortLayers() {
 
 // Update routes input to the appropriate layer
 func (lm *LayerManager) Update(msg tea.Msg) (*LayerManager, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case ShowToastMsg:
+		return lm, lm.toasts.show(msg), true
+	case toastExpiredMsg:
+		lm.toasts.expire(msg.ID)
+		return lm, nil, true
+	case tea.MouseMsg:
+		return lm.updateMouse(msg)
+	}
 	if top := lm.Top(); top != nil {
 		meta := top.LayerMeta()
 
@@ -135,6 +166,71 @@ func (lm *LayerManager) Update(msg tea.Msg) (*LayerManager, tea.Cmd, bool) {
 	return lm, nil, false
 }
 
+// UpdateAll sends msg to every layer, not only the top one, for changes
+// all of them draw with.
+func (lm *LayerManager) UpdateAll(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for i, l := range lm.layers {
+		updated, cmd := l.Update(msg)
+		if layer, ok := updated.(Layer); ok {
+			lm.layers[i] = layer
+		}
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
+
+// updateMouse hit-tests a mouse event against the top layer. Inside, the
+// layer gets the event relative to its top left corner. A click outside a
+// layer with dismiss keys closes it; otherwise modal layers and layers
+// capturing the mouse swallow the event, and the rest let it through.
+func (lm *LayerManager) updateMouse(msg tea.MouseMsg) (*LayerManager, tea.Cmd, bool) {
+	top := lm.Top()
+	if top == nil {
+		return lm, nil, false
+	}
+	meta := top.LayerMeta()
+	bounds := lm.Bounds(top)
+	m := msg.Mouse()
+
+	inside := bounds.Contains(m.X, m.Y)
+	if !inside {
+		if _, click := msg.(tea.MouseClickMsg); click && len(meta.DismissKeys) > 0 {
+			lm.Pop()
+			return lm, nil, true
+		}
+		if !meta.CaptureMouse {
+			return lm, nil, meta.Modal
+		}
+	}
+
+	newTop, cmd := top.Update(TranslateMouse(msg, -bounds.X, -bounds.Y))
+	if nt, ok := newTop.(Layer); ok {
+		lm.layers[len(lm.layers)-1] = nt
+	}
+	return lm, cmd, true
+}
+
+// TranslateMouse moves the position of a mouse event by dx, dy, as when
+// handing it to a component drawn at -dx, -dy
+func TranslateMouse(msg tea.MouseMsg, dx, dy int) tea.MouseMsg {
+	switch m := msg.(type) {
+	case tea.MouseClickMsg:
+		m.X, m.Y = m.X+dx, m.Y+dy
+		return m
+	case tea.MouseReleaseMsg:
+		m.X, m.Y = m.X+dx, m.Y+dy
+		return m
+	case tea.MouseWheelMsg:
+		m.X, m.Y = m.X+dx, m.Y+dy
+		return m
+	case tea.MouseMotionMsg:
+		m.X, m.Y = m.X+dx, m.Y+dy
+		return m
+	}
+	return msg
+}
+
 // HelpBindings returns the help bindings for the current top layer
 func (lm *LayerManager) HelpBindings() []key.Binding {
 	if top := lm.Top(); top != nil {
@@ -143,28 +239,69 @@ func (lm *LayerManager) HelpBindings() []key.Binding {
 	return nil
 }
 
-// RenderOver renders all layers over the base content using lipgloss Canvas
+// RenderOver renders all layers over the base content using lipgloss Canvas.
+// Layers with a scrim dim everything drawn below them. Toasts come last and
+// are never dimmed.
 func (lm *LayerManager) RenderOver(base string) string {
-	if len(lm.layers) == 0 {
-		return base
+	out := base
+	for _, layer := range lm.layers {
+		meta := layer.LayerMeta()
+		if meta.Scrim {
+			out = lm.styles.ScrimText.Render(ansi.Strip(out))
+		}
+		out = lm.place(out, lm.render(layer), meta.Pos)
 	}
+	return lm.place(out, lm.toasts.View(lm.width, lm.styles), toastPos)
+}
 
-	canvas := lipgloss.NewCanvas(lipgloss.NewLayer(base))
+// place draws content at pos over out
+func (lm *LayerManager) place(out, content string, pos Position) string {
+	if content == "" {
+		return out
+	}
+	r := lm.bounds(pos, content)
+	canvas := lipgloss.NewCanvas(
+		lipgloss.NewLayer(out),
+		lipgloss.NewLayer(content).X(r.X).Y(r.Y).Z(1),
+	)
+	// The canvas ends lines with CRLF; keep plain newlines like the base
+	return strings.ReplaceAll(canvas.Render(), "\r\n", "\n")
+}
 
-	// Add all layers
-	for _, layer := range lm.layers {
-		meta := layer.LayerMeta()
-		content := layer.View()
+// Bounds returns the area l covers when rendered
+func (lm *LayerManager) Bounds(l Layer) Rect {
+	return lm.bounds(l.LayerMeta().Pos, lm.render(l))
+}
 
-		if content == "" {
-			continue
-		}
+// render returns the view of l, cut to its bounding box if it has one
+func (lm *LayerManager) render(l Layer) string {
+	content := l.View()
+	pos := l.LayerMeta().Pos
+	if pos.Width <= 0 && pos.Height <= 0 {
+		return content
+	}
+	w, h := lm.layerSize(pos)
+	return lipgloss.NewStyle().MaxWidth(w).MaxHeight(h).Render(content)
+}
 
-		x, y := lm.calculatePosition(meta.Pos, content)
-		canvas.AddLayers(lipgloss.NewLayer(content).X(x).Y(y).Z(meta.Z))
+// layerSize is the size a layer at pos may take: its bounding box, within
+// the screen, or the whole screen.
+func (lm *LayerManager) layerSize(pos Position) (int, int) {
+	w, h := lm.width, lm.height
+	if pos.Width > 0 {
+		w = min(pos.Width, lm.width)
 	}
+	if pos.Height > 0 {
+		h = min(pos.Height, lm.height)
+	}
+	return w, h
+}
 
-	return canvas.Render()
+// bounds places content of a layer at pos on screen
+func (lm *LayerManager) bounds(pos Position, content string) Rect {
+	w, h := lipgloss.Width(content), lipgloss.Height(content)
+	x, y := lm.calculatePosition(pos, content)
+	return Rect{X: x, Y: y, Width: w, Height: h}
 }
 
 // calculatePosition determines the X,Y coordinates for layer placement
@@ -0,0 +1,129 @@
+package layout
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/tui/tuitest"
+)
+
+// testLayer draws its text and records what it is sent
+type testLayer struct {
+	meta          LayerMeta
+	text          string
+	width, height int
+	msgs          []tea.Msg
+}
+
+func (l *testLayer) Init() tea.Cmd { return nil }
+func (l *testLayer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	l.msgs = append(l.msgs, msg)
+	return l, nil
+}
+func (l *testLayer) View() string                          { return l.text }
+func (l *testLayer) SetSize(w, h int) tea.Cmd              { l.width, l.height = w, h; return nil }
+func (l *testLayer) GetSize() (int, int)                   { return l.width, l.height }
+func (l *testLayer) Bindings() []key.Binding               { return nil }
+func (l *testLayer) SetFocused(bool) (FocusScope, tea.Cmd) { return l, nil }
+func (l *testLayer) IsFocused() bool                       { return true }
+func (l *testLayer) Clone() FocusScope                     { clone := *l; return &clone }
+func (l *testLayer) LayerMeta() LayerMeta                  { return l.meta }
+
+var testBase = strings.TrimSuffix(strings.Repeat(strings.Repeat(".", 20)+"\n", 8), "\n")
+
+const testBox = "+----+\n|hey!|\n+----+"
+
+func TestLayerManager_RenderOver(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers []*testLayer
+	}{
+		{"empty", nil},
+		{"center", []*testLayer{{meta: LayerMeta{ID: "a", Pos: Position{Anchor: Center}}, text: testBox}}},
+		{"offset clamped", []*testLayer{{meta: LayerMeta{ID: "a", Pos: Position{Anchor: BottomRight, X: 3, Y: 1}}, text: testBox}}},
+		{"sized", []*testLayer{{
+			meta: LayerMeta{ID: "a", Pos: Position{Anchor: TopLeft, X: 2, Y: 1, Width: 4, Height: 2}},
+			text: testBox,
+		}}},
+		{"scrim", []*testLayer{{meta: LayerMeta{ID: "a", Modal: true, Scrim: true, Pos: Position{Anchor: Center}}, text: testBox}}},
+		{"stacked", []*testLayer{
+			{meta: LayerMeta{ID: "a", Z: 1, Pos: Position{Anchor: TopLeft}}, text: testBox},
+			{meta: LayerMeta{ID: "b", Z: 2, Scrim: true, Pos: Position{Anchor: TopLeft, X: 3, Y: 1}}, text: testBox},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := NewLayerManager()
+			lm.SetSize(20, 8)
+			for _, l := range tt.layers {
+				lm.Push(l)
+			}
+			tuitest.AssertGolden(t, "layers_"+strings.ReplaceAll(tt.name, " ", "_"), lm.RenderOver(testBase))
+		})
+	}
+}
+
+func TestLayerManager_Size(t *testing.T) {
+	lm := NewLayerManager()
+	lm.SetSize(20, 8)
+	full := &testLayer{meta: LayerMeta{ID: "full"}}
+	sized := &testLayer{meta: LayerMeta{ID: "sized", Pos: Position{Width: 10, Height: 30}}}
+	lm.Push(full)
+	lm.Push(sized)
+	lm.SetSize(30, 12)
+	if w, h := full.GetSize(); w != 30 || h != 12 {
+		t.Errorf("unsized layer size = %dx%d, want the screen", w, h)
+	}
+	if w, h := sized.GetSize(); w != 10 || h != 12 {
+		t.Errorf("sized layer size = %dx%d, want its box within the screen", w, h)
+	}
+}
+
+func TestLayerManager_Mouse(t *testing.T) {
+	click := func(x, y int) tea.MouseClickMsg {
+		return tea.MouseClickMsg{X: x, Y: y, Button: tea.MouseLeft}
+	}
+	centered := Position{Anchor: Center} // the test box covers x 7-12, y 2-4
+
+	tests := []struct {
+		name        string
+		meta        LayerMeta
+		msg         tea.MouseMsg
+		wantHandled bool
+		wantPopped  bool
+		wantMsg     tea.Msg
+	}{
+		{"inside", LayerMeta{Pos: centered}, click(8, 3), true, false, click(1, 1)},
+		{"outside dismissible", LayerMeta{Modal: true, DismissKeys: []string{"esc"}, Pos: centered}, click(0, 0), true, true, nil},
+		{"outside modal", LayerMeta{Modal: true, Pos: centered}, click(0, 0), true, false, nil},
+		{"outside", LayerMeta{Pos: centered}, click(0, 0), false, false, nil},
+		{"wheel outside dismissible", LayerMeta{Modal: true, DismissKeys: []string{"esc"}, Pos: centered}, tea.MouseWheelMsg{X: 0, Y: 0}, true, false, nil},
+		{"captured outside", LayerMeta{CaptureMouse: true, Pos: centered}, click(0, 0), true, false, click(-7, -2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lm := NewLayerManager()
+			lm.SetSize(20, 8)
+			layer := &testLayer{meta: tt.meta, text: testBox}
+			lm.Push(layer)
+
+			_, _, handled := lm.Update(tt.msg)
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+			if popped := !lm.HasLayers(); popped != tt.wantPopped {
+				t.Errorf("popped = %v, want %v", popped, tt.wantPopped)
+			}
+			var got tea.Msg
+			if len(layer.msgs) > 0 {
+				got = layer.msgs[0]
+			}
+			if got != tt.wantMsg {
+				t.Errorf("layer got %v, want %v", got, tt.wantMsg)
+			}
+		})
+	}
+}
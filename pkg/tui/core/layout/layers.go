@@ -35,8 +35,8 @@ const (
 type Position struct {
 	X, Y   int    // absolute offsets from anchor
 	Anchor Anchor // positioning anchor
-	Width  int    // desired bounding box (0 = auto)
-	Height int    // desired bounding box (0 = auto)
+	Width  int    // bounding box the layer is sized and cut to (0 = screen)
+	Height int    // bounding box the layer is sized and cut to (0 = screen)
 }
 
 // LayerMeta contains metadata about how a layer should behave
@@ -45,9 +45,9 @@ type LayerMeta struct {
 	Z            int      // z-order (higher = on top)
 	Pos          Position // placement information
 	Modal        bool     // captures all input
-	CaptureMouse bool     // captures mouse events
+	CaptureMouse bool     // gets mouse events outside its bounds too
 	CaptureKeys  bool     // captures keyboard events
-	DismissKeys  []string // keys that dismiss the layer
+	DismissKeys  []string // keys that dismiss the layer; it also closes on a click outside
 	Scrim        bool     // draw dim background under modal
 }
 
@@ -72,13 +72,22 @@ type DismissLayerByIDMsg struct {
 
 // ShowConfirmDialogMsg is a convenience message for showing a confirm dialog
 type ShowConfirmDialogMsg struct {
+	ID   string
 	Text string
 }
 
-// ShowPromptDialogMsg requests opening the prompt dialog
-type ShowPromptDialogMsg struct{}
+// ShowPromptDialogMsg requests opening the prompt dialog, prefilled with Text
+type ShowPromptDialogMsg struct {
+	Text string
+}
 
 // PromptSubmittedMsg is emitted by the prompt dialog when user submits text
 type PromptSubmittedMsg struct {
 	Text string
 }
+
+// CommandMsg is emitted by the prompt dialog when a slash command ran.
+// Msg is the command's result and should be dispatched once the dialog closes.
+type CommandMsg struct {
+	Msg tea.Msg
+}
@@ -0,0 +1,410 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// ListSelectedMsg is emitted by a List when an item is chosen, by its
+// select key or a click. ID is the one the list was created with.
+type ListSelectedMsg[T any] struct {
+	ID    string
+	Index int // of the item among all items, filtered or not
+	Item  T
+}
+
+// ListRenderFunc renders an item as a single line at most width cells wide.
+// The list highlights the selected line itself.
+type ListRenderFunc[T any] func(item T, width int) string
+
+// List is a scrollable list of items to pick one from. It shows a loading,
+// error or empty state in place of the items, and narrows them down with a
+// fuzzy filter when it was given the text to match.
+//
+// Methods have pointer receivers; a component holding a List by value
+// updates it in place with `_, cmd := c.list.Update(msg)`.
+type List[T any] struct {
+	id     string
+	items  []T
+	render ListRenderFunc[T]
+	text   func(T) string // what the filter matches; nil disables filtering
+
+	visible []int // indexes of the items matching the filter
+	cursor  int   // index into visible
+	offset  int   // first visible item shown
+
+	filter    textinput.Model
+	filtering bool
+
+	loading bool
+	err     error
+	empty   string
+
+	focused       bool
+	width, height int
+	keys          ListKeyMap
+	styles        Styles
+}
+
+// NewList creates an empty list rendering items with render. Choosing an
+// item emits a ListSelectedMsg with id.
+func NewList[T any](id string, render ListRenderFunc[T]) List[T] {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	return List[T]{
+		id:     id,
+		render: render,
+		filter: ti,
+		empty:  "Nothing here",
+		keys:   DefaultListKeyMap,
+		styles: DefaultStyles,
+	}
+}
+
+// WithRender replaces how items are drawn.
+func (l *List[T]) WithRender(render ListRenderFunc[T]) *List[T] {
+	l.render = render
+	return l
+}
+
+// WithFilter lets the list be filtered by the text returned for each item.
+func (l *List[T]) WithFilter(text func(T) string) *List[T] {
+	l.text = text
+	return l
+}
+
+// WithEmpty sets what the list shows when it has no items.
+func (l *List[T]) WithEmpty(text string) *List[T] {
+	l.empty = text
+	return l
+}
+
+// WithKeys replaces the list's key bindings.
+func (l *List[T]) WithKeys(keys ListKeyMap) *List[T] {
+	l.keys = keys
+	return l
+}
+
+// WithStyles replaces the styles the list draws its states with.
+func (l *List[T]) WithStyles(styles Styles) *List[T] {
+	l.styles = styles
+	return l
+}
+
+// SetItems replaces the items and ends the loading and error states. The
+// cursor keeps its position as far as the items reach.
+func (l *List[T]) SetItems(items []T) {
+	l.items = append([]T(nil), items...)
+	l.loading, l.err = false, nil
+	l.refilter(-1)
+}
+
+// Items returns every item, filtered or not.
+func (l *List[T]) Items() []T {
+	return append([]T(nil), l.items...)
+}
+
+// SetLoading shows that the items are on their way.
+func (l *List[T]) SetLoading(loading bool) {
+	l.loading = loading
+}
+
+// SetError shows err in place of the items; nil shows them again.
+func (l *List[T]) SetError(err error) {
+	l.err = err
+	l.loading = false
+}
+
+// Select moves the cursor to the item at index, if it passes the filter.
+func (l *List[T]) Select(index int) {
+	for i, v := range l.visible {
+		if v == index {
+			l.cursor = i
+			l.scroll()
+			return
+		}
+	}
+}
+
+// Index returns the index of the item under the cursor among all items, or
+// -1 when no item is shown.
+func (l *List[T]) Index() int {
+	if l.cursor < 0 || l.cursor >= len(l.visible) {
+		return -1
+	}
+	return l.visible[l.cursor]
+}
+
+// Selected returns the item under the cursor.
+func (l *List[T]) Selected() (T, bool) {
+	var zero T
+	index := l.Index()
+	if index < 0 || l.loading || l.err != nil {
+		return zero, false
+	}
+	return l.items[index], true
+}
+
+// Visible returns the items passing the filter, in order.
+func (l *List[T]) Visible() []T {
+	items := make([]T, len(l.visible))
+	for i, v := range l.visible {
+		items[i] = l.items[v]
+	}
+	return items
+}
+
+func (l *List[T]) Init() tea.Cmd { return nil }
+
+func (l *List[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ComponentSizeMsg:
+		return l, l.SetSize(msg.Width, msg.Height)
+	case tea.KeyPressMsg:
+		if !l.focused {
+			return l, nil
+		}
+		return l, l.handleKey(msg)
+	case tea.MouseClickMsg:
+		if msg.Button == tea.MouseLeft {
+			return l, l.click(msg.Y)
+		}
+	case tea.MouseWheelMsg:
+		switch msg.Button {
+		case tea.MouseWheelUp:
+			l.move(-1)
+		case tea.MouseWheelDown:
+			l.move(1)
+		}
+	}
+	return l, nil
+}
+
+func (l *List[T]) handleKey(msg tea.KeyPressMsg) tea.Cmd {
+	if l.filtering {
+		switch {
+		case key.Matches(msg, l.keys.Accept):
+			l.filtering = false
+			l.filter.Blur()
+			return nil
+		case key.Matches(msg, l.keys.Clear):
+			l.clearFilter()
+			return nil
+		}
+		before := l.filter.Value()
+		var cmd tea.Cmd
+		l.filter, cmd = l.filter.Update(msg)
+		if l.filter.Value() != before {
+			// The best place to start from is the first match
+			l.refilter(-1)
+			l.cursor = 0
+			l.scroll()
+		}
+		return cmd
+	}
+
+	switch {
+	case key.Matches(msg, l.keys.Up):
+		l.move(-1)
+	case key.Matches(msg, l.keys.Down):
+		l.move(1)
+	case key.Matches(msg, l.keys.PageUp):
+		l.move(-l.rows())
+	case key.Matches(msg, l.keys.PageDown):
+		l.move(l.rows())
+	case key.Matches(msg, l.keys.Filter) && l.text != nil:
+		l.filtering = true
+		return l.filter.Focus()
+	case key.Matches(msg, l.keys.Clear) && l.filter.Value() != "":
+		l.clearFilter()
+	case key.Matches(msg, l.keys.Select):
+		return l.choose()
+	}
+	return nil
+}
+
+// HandlesKey reports whether the list takes msg rather than its parent.
+// Moving past either end is left to the parent, so that it can move on to
+// the next component.
+func (l *List[T]) HandlesKey(msg tea.KeyPressMsg) bool {
+	switch {
+	case l.filtering:
+		return true
+	case key.Matches(msg, l.keys.Up), key.Matches(msg, l.keys.PageUp):
+		return l.cursor > 0
+	case key.Matches(msg, l.keys.Down), key.Matches(msg, l.keys.PageDown):
+		return l.cursor < len(l.visible)-1
+	case key.Matches(msg, l.keys.Select):
+		_, ok := l.Selected()
+		return ok
+	case key.Matches(msg, l.keys.Filter):
+		return l.text != nil
+	case key.Matches(msg, l.keys.Clear):
+		return l.filter.Value() != ""
+	}
+	return false
+}
+
+// CapturesKeys reports whether a filter is being typed.
+func (l *List[T]) CapturesKeys() bool {
+	return l.filtering
+}
+
+func (l *List[T]) clearFilter() {
+	l.filtering = false
+	l.filter.Blur()
+	l.filter.SetValue("")
+	l.refilter(l.Index())
+}
+
+// move moves the cursor by delta items, stopping at either end.
+func (l *List[T]) move(delta int) {
+	l.cursor = max(0, min(l.cursor+delta, len(l.visible)-1))
+	l.scroll()
+}
+
+// click chooses the item on row y of the list.
+func (l *List[T]) click(y int) tea.Cmd {
+	row := y - l.headerHeight()
+	if row < 0 || row >= l.rows() || l.offset+row >= len(l.visible) {
+		return nil
+	}
+	l.cursor = l.offset + row
+	return l.choose()
+}
+
+// choose emits the item under the cursor.
+func (l *List[T]) choose() tea.Cmd {
+	item, ok := l.Selected()
+	if !ok {
+		return nil
+	}
+	msg := ListSelectedMsg[T]{ID: l.id, Index: l.Index(), Item: item}
+	return func() tea.Msg { return msg }
+}
+
+// refilter lists the items matching the filter, in their order, and puts
+// the cursor back on the item at index if it still matches. Otherwise the
+// cursor keeps its position as far as the items reach.
+func (l *List[T]) refilter(index int) {
+	query := l.filter.Value()
+	cursor := l.cursor
+	l.visible = make([]int, 0, len(l.items))
+	for i, item := range l.items {
+		if l.text != nil && query != "" {
+			if _, ok := FuzzyScore(query, l.text(item)); !ok {
+				continue
+			}
+		}
+		if i == index {
+			cursor = len(l.visible)
+		}
+		l.visible = append(l.visible, i)
+	}
+	l.cursor = max(0, min(cursor, len(l.visible)-1))
+	l.scroll()
+}
+
+// scroll keeps the cursor on screen.
+func (l *List[T]) scroll() {
+	rows := l.rows()
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+rows {
+		l.offset = l.cursor - rows + 1
+	}
+	l.offset = max(0, min(l.offset, len(l.visible)-rows))
+}
+
+// headerHeight is the rows taken by the filter above the items.
+func (l *List[T]) headerHeight() int {
+	if l.filtering || l.filter.Value() != "" {
+		return 1
+	}
+	return 0
+}
+
+// rows is the number of items shown at once.
+func (l *List[T]) rows() int {
+	return max(1, l.height-l.headerHeight())
+}
+
+func (l *List[T]) View() string {
+	var lines []string
+	if l.headerHeight() > 0 {
+		lines = append(lines, l.filter.View())
+	}
+
+	line := lipgloss.NewStyle().MaxWidth(max(0, l.width))
+	switch {
+	case l.err != nil:
+		lines = append(lines, line.Render(l.styles.ErrorText.Render("error: "+l.err.Error())))
+	case l.loading:
+		lines = append(lines, l.styles.DimText.Render("Loading…"))
+	case len(l.visible) == 0 && len(l.items) > 0:
+		lines = append(lines, l.styles.DimText.Render("No matches"))
+	case len(l.visible) == 0:
+		lines = append(lines, line.Render(l.styles.DimText.Render(l.empty)))
+	default:
+		end := min(len(l.visible), l.offset+l.rows())
+		for i := l.offset; i < end; i++ {
+			text := line.Render(l.render(l.items[l.visible[i]], l.width))
+			if l.focused && i == l.cursor {
+				text = lipgloss.NewStyle().Reverse(true).Render(text)
+			}
+			lines = append(lines, text)
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Width(max(0, l.width)).
+		Height(max(0, l.height)).
+		MaxHeight(max(0, l.height)).
+		Render(strings.Join(lines, "\n"))
+}
+
+func (l *List[T]) SetSize(width, height int) tea.Cmd {
+	l.width, l.height = width, height
+	l.filter.SetWidth(max(1, width-lipgloss.Width(l.filter.Prompt)-1))
+	l.scroll()
+	return nil
+}
+
+func (l *List[T]) GetSize() (int, int) { return l.width, l.height }
+
+func (l *List[T]) SetFocused(focused bool) (Focusable, tea.Cmd) {
+	l.focused = focused
+	if !focused && l.filtering {
+		l.filtering = false
+		l.filter.Blur()
+	}
+	return l, nil
+}
+
+func (l *List[T]) IsFocused() bool { return l.focused }
+
+func (l *List[T]) Clone() Focusable {
+	clone := *l
+	clone.items = append([]T(nil), l.items...)
+	clone.visible = append([]int(nil), l.visible...)
+	return &clone
+}
+
+func (l *List[T]) Bindings() []key.Binding {
+	if l.filtering {
+		return []key.Binding{l.keys.Accept, l.keys.Clear}
+	}
+	bindings := []key.Binding{l.keys.Up, l.keys.Down, l.keys.Select}
+	if l.text != nil {
+		bindings = append(bindings, l.keys.Filter)
+	}
+	if l.filter.Value() != "" {
+		bindings = append(bindings, l.keys.Clear)
+	}
+	return bindings
+}
@@ -0,0 +1,131 @@
+package layout
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newTestList(items ...string) *List[string] {
+	l := NewList("test", func(item string, _ int) string { return item })
+	l.WithFilter(func(item string) string { return item })
+	l.SetSize(20, 3)
+	l.SetFocused(true)
+	l.SetItems(items)
+	return &l
+}
+
+// pressKeys sends keys to l by name and returns the last command
+func pressKeys(l *List[string], keys ...string) tea.Cmd {
+	special := map[string]rune{"up": tea.KeyUp, "down": tea.KeyDown, "enter": tea.KeyEnter, "esc": tea.KeyEscape}
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyPressMsg{Code: []rune(k)[0], Text: k}
+		if code, ok := special[k]; ok {
+			msg = press(code)
+		}
+		_, cmd = l.Update(msg)
+	}
+	return cmd
+}
+
+func TestList_Navigation(t *testing.T) {
+	l := newTestList("alpha", "beta", "gamma", "delta", "epsilon")
+
+	if l.HandlesKey(press(tea.KeyUp)) {
+		t.Error("HandlesKey(up) = true on the first item, want the parent to move on")
+	}
+	pressKeys(l, "down", "down", "down")
+	if got, _ := l.Selected(); got != "delta" {
+		t.Errorf("Selected() = %q, want delta", got)
+	}
+	// The cursor stays on screen
+	if view := ansi.Strip(l.View()); !strings.Contains(view, "delta") || strings.Contains(view, "alpha") {
+		t.Errorf("View() =\n%s\nwant it scrolled to delta", view)
+	}
+
+	msg := pressKeys(l, "enter")()
+	if got, ok := msg.(ListSelectedMsg[string]); !ok || got.ID != "test" || got.Index != 3 || got.Item != "delta" {
+		t.Errorf("enter emitted %#v, want delta selected", msg)
+	}
+}
+
+func TestList_Filter(t *testing.T) {
+	l := newTestList("alpha", "beta", "gamma", "delta")
+	pressKeys(l, "down", "/")
+	if !l.CapturesKeys() {
+		t.Fatal("/ did not start filtering")
+	}
+	pressKeys(l, "e", "t")
+	if got := l.Visible(); len(got) != 2 || got[0] != "beta" || got[1] != "delta" {
+		t.Errorf("Visible() = %v, want beta and delta", got)
+	}
+	if got, _ := l.Selected(); got != "beta" {
+		t.Errorf("Selected() = %q, want the first match", got)
+	}
+
+	pressKeys(l, "enter", "down")
+	if l.CapturesKeys() {
+		t.Error("enter did not end filtering")
+	}
+	if got, _ := l.Selected(); got != "delta" {
+		t.Errorf("Selected() = %q, want delta", got)
+	}
+
+	// Clearing the filter keeps the cursor on the same item
+	pressKeys(l, "esc")
+	if got := len(l.Visible()); got != 4 {
+		t.Errorf("%d items visible after clearing the filter, want 4", got)
+	}
+	if got := l.Index(); got != 3 {
+		t.Errorf("Index() = %d after clearing the filter, want delta's", got)
+	}
+}
+
+func TestList_States(t *testing.T) {
+	l := newTestList()
+	l.WithEmpty("Nothing yet")
+	tests := []struct {
+		name string
+		set  func()
+		want string
+	}{
+		{"empty", func() {}, "Nothing yet"},
+		{"loading", func() { l.SetLoading(true) }, "Loading…"},
+		{"error", func() { l.SetError(errors.New("offline")) }, "error: offline"},
+		{"items", func() { l.SetItems([]string{"one"}) }, "one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.set()
+			if got := ansi.Strip(l.View()); !strings.Contains(got, tt.want) {
+				t.Errorf("View() =\n%s\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList_Mouse(t *testing.T) {
+	l := newTestList("alpha", "beta", "gamma", "delta")
+	l.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	l.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	l.Update(tea.MouseWheelMsg{Button: tea.MouseWheelDown})
+	if got := l.Index(); got != 3 {
+		t.Fatalf("Index() = %d after scrolling down, want 3", got)
+	}
+
+	// Rows show beta, gamma and delta now; a click chooses one
+	_, cmd := l.Update(tea.MouseClickMsg{X: 2, Y: 1, Button: tea.MouseLeft})
+	if cmd == nil {
+		t.Fatal("click on an item emitted nothing")
+	}
+	if got := cmd().(ListSelectedMsg[string]); got.Item != "gamma" {
+		t.Errorf("click chose %q, want gamma", got.Item)
+	}
+	if _, cmd := l.Update(tea.MouseClickMsg{X: 2, Y: 5, Button: tea.MouseLeft}); cmd != nil {
+		t.Error("click below the items chose one")
+	}
+}
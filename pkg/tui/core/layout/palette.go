@@ -0,0 +1,222 @@
+package layout
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// paletteRows is the number of actions the palette shows at once.
+const paletteRows = 12
+
+// PaletteItem is an action offered by the command palette
+type PaletteItem struct {
+	Title    string
+	Category string // e.g. "key", "command", "model"
+	Key      string // key binding that runs the action directly, if any
+	Msg      tea.Msg
+}
+
+// ShowPaletteMsg requests opening the command palette with Items
+type ShowPaletteMsg struct {
+	Items []PaletteItem
+}
+
+// PaletteItemsMsg adds Items to the open command palette, for actions
+// loaded after it opened.
+type PaletteItemsMsg struct {
+	Items []PaletteItem
+}
+
+// CommandPalette is a modal layer to fuzzy search and run any action.
+// Running an item emits a CommandMsg carrying the item's message.
+type CommandPalette struct {
+	focused  bool
+	width    int
+	height   int
+	items    []PaletteItem
+	filtered []int
+	selected int
+	input    textinput.Model
+	keys     PaletteKeyMap
+	styles   Styles
+}
+
+// NewCommandPalette creates a palette over items
+func NewCommandPalette(items []PaletteItem) *CommandPalette {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "Search actions..."
+	ti.Focus()
+
+	cp := &CommandPalette{items: items, input: ti, keys: DefaultPaletteKeyMap, styles: DefaultStyles}
+	cp.filter()
+	return cp
+}
+
+// WithKeys replaces the palette's key bindings.
+func (c *CommandPalette) WithKeys(keys PaletteKeyMap) *CommandPalette {
+	c.keys = keys
+	return c
+}
+
+// WithStyles replaces the styles the palette draws with.
+func (c *CommandPalette) WithStyles(styles Styles) *CommandPalette {
+	c.styles = styles
+	return c
+}
+
+func (c *CommandPalette) Init() tea.Cmd { return textinput.Blink }
+
+func (c *CommandPalette) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m, ok := msg.(PaletteItemsMsg); ok {
+		c.addItems(m.Items)
+		return c, nil
+	}
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch {
+		case key.Matches(m, c.keys.Up):
+			if c.selected > 0 {
+				c.selected--
+			}
+			return c, nil
+		case key.Matches(m, c.keys.Down):
+			if c.selected < len(c.filtered)-1 {
+				c.selected++
+			}
+			return c, nil
+		case key.Matches(m, c.keys.Run):
+			if len(c.filtered) == 0 {
+				return c, nil
+			}
+			item := c.items[c.filtered[c.selected]]
+			return c, func() tea.Msg { return CommandMsg{Msg: item.Msg} }
+		case key.Matches(m, c.keys.Cancel):
+			return c, func() tea.Msg { return CancelledMsg{} }
+		}
+	}
+
+	var cmd tea.Cmd
+	before := c.input.Value()
+	c.input, cmd = c.input.Update(msg)
+	if c.input.Value() != before {
+		c.filter()
+	}
+	return c, cmd
+}
+
+// addItems appends items, keeping the selection on the same item.
+func (c *CommandPalette) addItems(items []PaletteItem) {
+	selected := -1
+	if c.selected < len(c.filtered) {
+		selected = c.filtered[c.selected]
+	}
+	c.items = append(c.items, items...)
+	c.filter()
+	for i, index := range c.filtered {
+		if index == selected {
+			c.selected = i
+		}
+	}
+}
+
+// filter ranks the items matching the query, best match first. Ties keep
+// the order the items were given in.
+func (c *CommandPalette) filter() {
+	type match struct {
+		index int
+		score int
+	}
+	query := c.input.Value()
+	var matches []match
+	for i, item := range c.items {
+		if score, ok := FuzzyScore(query, item.Category+" "+item.Title); ok {
+			// Prefer hits in the title over hits in the category
+			if titleScore, ok := FuzzyScore(query, item.Title); ok {
+				score = max(score, titleScore+1)
+			}
+			matches = append(matches, match{index: i, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	c.filtered = c.filtered[:0]
+	for _, m := range matches {
+		c.filtered = append(c.filtered, m.index)
+	}
+	c.selected = 0
+}
+
+func (c *CommandPalette) boxWidth() int {
+	return max(50, c.width/2)
+}
+
+func (c *CommandPalette) View() string {
+	style := c.styles.DialogBox.Padding(0, 1).Width(c.boxWidth())
+
+	innerW := c.boxWidth() - 4 // account for border and padding
+
+	// Keep the selection inside the visible window
+	start := 0
+	if c.selected >= paletteRows {
+		start = c.selected - paletteRows + 1
+	}
+	end := min(len(c.filtered), start+paletteRows)
+
+	rows := []string{c.input.View(), ""}
+	for i := start; i < end; i++ {
+		item := c.items[c.filtered[i]]
+		left := c.styles.DimText.Render(item.Category+" ") + item.Title
+		right := c.styles.DimText.Render(item.Key)
+		gap := max(1, innerW-lipgloss.Width(left)-lipgloss.Width(right))
+		row := lipgloss.NewStyle().MaxWidth(innerW).Render(left + strings.Repeat(" ", gap) + right)
+		if i == c.selected {
+			row = lipgloss.NewStyle().Reverse(true).Render(row)
+		}
+		rows = append(rows, row)
+	}
+	if len(c.filtered) == 0 {
+		rows = append(rows, c.styles.DimText.Render("No matching actions"))
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+func (c *CommandPalette) SetSize(width, height int) tea.Cmd {
+	c.width, c.height = width, height
+	c.input.SetWidth(c.boxWidth() - 6)
+	return nil
+}
+func (c *CommandPalette) GetSize() (int, int) { return c.width, c.height }
+func (c *CommandPalette) SetFocused(focused bool) (FocusScope, tea.Cmd) {
+	c.focused = focused
+	if focused {
+		return c, c.input.Focus()
+	}
+	c.input.Blur()
+	return c, nil
+}
+func (c *CommandPalette) IsFocused() bool { return c.focused }
+func (c *CommandPalette) Clone() FocusScope {
+	clone := *c
+	clone.filtered = append([]int(nil), c.filtered...)
+	return &clone
+}
+func (c *CommandPalette) Bindings() []key.Binding {
+	return []key.Binding{c.keys.Up, c.keys.Down, c.keys.Run, c.keys.Cancel}
+}
+func (c *CommandPalette) LayerMeta() LayerMeta {
+	return LayerMeta{
+		ID:          "palette",
+		Z:           200,
+		Modal:       true,
+		CaptureKeys: true,
+		DismissKeys: keyNames(c.keys.Cancel),
+		Scrim:       true,
+		Pos:         Position{Anchor: TopCenter, Y: 2},
+	}
+}
@@ -0,0 +1,112 @@
+package layout
+
+import (
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textarea"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// PromptCancelledMsg is emitted when the prompt is left without sending.
+// Draft holds the unsent text so it can be restored later.
+type PromptCancelledMsg struct {
+	Draft string
+}
+
+// PromptDialog is a modal layer to capture a prompt from the user.
+// Input starting with a slash is run as a command from the registry instead.
+type PromptDialog struct {
+	focused bool
+	width   int
+	height  int
+	input   PromptInput
+	keys    PromptKeyMap
+}
+
+func NewPromptDialog(initial string, opts PromptOptions) *PromptDialog {
+	input := NewPromptInput(initial, opts)
+	input.Focus()
+	return &PromptDialog{
+		input: input,
+		keys:  input.keys,
+	}
+}
+
+func (p *PromptDialog) Init() tea.Cmd { return textarea.Blink }
+
+func (p *PromptDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m, ok := msg.(tea.KeyPressMsg); ok && key.Matches(m, p.keys.Cancel) {
+		draft := p.input.Value()
+		return p, func() tea.Msg { return PromptCancelledMsg{Draft: draft} }
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+func (p *PromptDialog) View() string {
+	style := p.input.styles.DialogBox.Width(max(40, p.width/2)).Align(lipgloss.Left)
+
+	controls := p.input.styles.ConfirmKey.Render("["+p.input.SubmitHelp().Key+"]") +
+		" Send • " +
+		p.input.styles.InfoKey.Render("["+p.input.NewlineHelp().Key+"]") +
+		" Newline • " +
+		p.input.styles.CancelKey.Render("["+p.keys.Cancel.Help().Key+"]") +
+		" Cancel"
+
+	return style.Render(p.input.View() + "\n\n" + controls)
+}
+
+func (p *PromptDialog) SetSize(width, height int) tea.Cmd {
+	p.width, p.height = width, height
+	boxWidth := max(40, width/2)
+	inputWidth := boxWidth - 6 // account for border and padding
+	if inputWidth < 10 {
+		inputWidth = 10
+	}
+	p.input.SetWidth(inputWidth)
+	// Aim for a few visible lines; cap to dialog height if small
+	visibleLines := 6
+	if height > 0 {
+		// Roughly estimate available height inside the box (accounting for borders and controls)
+		boxHeight := max(8, height/3)
+		if boxHeight < 8 {
+			boxHeight = 8
+		}
+		// Reserve ~3 lines for controls and padding
+		if boxHeight-3 < visibleLines {
+			visibleLines = boxHeight - 3
+			if visibleLines < 3 {
+				visibleLines = 3
+			}
+		}
+	}
+	p.input.SetHeight(visibleLines)
+	return nil
+}
+func (p *PromptDialog) GetSize() (int, int) { return p.width, p.height }
+func (p *PromptDialog) SetFocused(focused bool) (FocusScope, tea.Cmd) {
+	p.focused = focused
+	if focused {
+		p.input.Focus()
+	} else {
+		p.input.Blur()
+	}
+	return p, nil
+}
+func (p *PromptDialog) IsFocused() bool   { return p.focused }
+func (p *PromptDialog) Clone() FocusScope { clone := *p; return &clone }
+func (p *PromptDialog) Bindings() []key.Binding {
+	return append(p.input.Bindings(), p.keys.Cancel)
+}
+func (p *PromptDialog) LayerMeta() LayerMeta {
+	// Cancel is handled by the dialog itself so the draft is not lost
+	return LayerMeta{
+		ID:          "prompt",
+		Z:           100,
+		Modal:       true,
+		CaptureKeys: true,
+		Scrim:       true,
+		Pos:         Position{Anchor: Center},
+	}
+}
@@ -0,0 +1,327 @@
+package layout
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textarea"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/tui/core/commands"
+)
+
+// maxSuggestions is the number of slash command completions shown at once.
+const maxSuggestions = 5
+
+// editorFinishedMsg carries the prompt back from the external editor
+type editorFinishedMsg struct {
+	text string
+	err  error
+}
+
+// PromptOptions configures a PromptInput
+type PromptOptions struct {
+	Commands *commands.Registry
+	History  []string // previously sent prompts, oldest first
+
+	// Keys replaces DefaultPromptKeyMap when set
+	Keys *PromptKeyMap
+	// Styles replaces DefaultStyles when set
+	Styles *Styles
+
+	// SubmitKeys, NewlineKeys and EditorKeys rebind single actions
+	SubmitKeys  []string
+	NewlineKeys []string
+	EditorKeys  []string
+}
+
+// PromptInput is a multiline prompt editor with slash command completion,
+// history recall and $EDITOR handoff. It is shared by the prompt dialog and
+// the inline chat input. Submitting emits PromptSubmittedMsg, or CommandMsg
+// for input starting with a slash.
+type PromptInput struct {
+	input    textarea.Model
+	commands *commands.Registry
+	keys     PromptKeyMap
+	styles   Styles
+
+	suggestions []commands.Suggestion
+	suggestion  int
+	err         error
+
+	// history is browsed with up/down; historyIndex == len(history) is the
+	// text being written, which is kept in pending while browsing.
+	history      []string
+	historyIndex int
+	pending      string
+}
+
+func NewPromptInput(initial string, opts PromptOptions) PromptInput {
+	keys := DefaultPromptKeyMap
+	if opts.Keys != nil {
+		keys = *opts.Keys
+	}
+	if len(opts.SubmitKeys) > 0 {
+		keys.Submit = key.NewBinding(key.WithKeys(opts.SubmitKeys...), key.WithHelp(opts.SubmitKeys[0], "send"))
+	}
+	if len(opts.NewlineKeys) > 0 {
+		keys.Newline = key.NewBinding(key.WithKeys(opts.NewlineKeys...), key.WithHelp(opts.NewlineKeys[0], "newline"))
+	}
+	if len(opts.EditorKeys) > 0 {
+		keys.Editor = key.NewBinding(key.WithKeys(opts.EditorKeys...), key.WithHelp(opts.EditorKeys[0], "$EDITOR"))
+	}
+
+	styles := DefaultStyles
+	if opts.Styles != nil {
+		styles = *opts.Styles
+	}
+
+	ti := textarea.New()
+	ti.Placeholder = "Type your message, or / for commands..."
+	ti.ShowLineNumbers = false
+	ti.KeyMap.InsertNewline = keys.Newline
+	ti.SetValue(initial)
+
+	p := PromptInput{
+		input:        ti,
+		commands:     opts.Commands,
+		history:      append([]string(nil), opts.History...),
+		historyIndex: len(opts.History),
+		keys:         keys,
+		styles:       styles,
+	}
+	p.updateSuggestions()
+	return p
+}
+
+func (p PromptInput) Update(msg tea.Msg) (PromptInput, tea.Cmd) {
+	switch m := msg.(type) {
+	case editorFinishedMsg:
+		if m.err != nil {
+			p.err = m.err
+			return p, nil
+		}
+		p.err = nil
+		p.input.SetValue(strings.TrimRight(m.text, "\n"))
+		p.updateSuggestions()
+		return p, nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(m, p.keys.Submit):
+			return p.submit()
+		case key.Matches(m, p.keys.Editor):
+			return p, openEditor(p.input.Value())
+		case key.Matches(m, p.keys.Complete) && len(p.suggestions) > 0:
+			p.input.SetValue(p.suggestions[p.suggestion].Value)
+			p.input.CursorEnd()
+			p.updateSuggestions()
+			return p, nil
+		case key.Matches(m, p.keys.NextSuggestion) && len(p.suggestions) > 0:
+			p.suggestion = (p.suggestion + 1) % len(p.suggestions)
+			return p, nil
+		case key.Matches(m, p.keys.PrevSuggestion) && len(p.suggestions) > 0:
+			p.suggestion = (p.suggestion - 1 + len(p.suggestions)) % len(p.suggestions)
+			return p, nil
+		case key.Matches(m, p.keys.HistoryPrev) && p.input.Line() == 0 && p.historyIndex > 0:
+			p.recall(p.historyIndex - 1)
+			return p, nil
+		case key.Matches(m, p.keys.HistoryNext) && p.input.Line() == p.input.LineCount()-1 && p.historyIndex < len(p.history):
+			p.recall(p.historyIndex + 1)
+			return p, nil
+		}
+	}
+	var cmd tea.Cmd
+	before := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.err = nil
+		p.updateSuggestions()
+	}
+	return p, cmd
+}
+
+// submit sends the prompt, or runs it as a slash command, and clears the
+// input. A failed command keeps the text so it can be corrected. A doubled
+// slash sends the text with a single one.
+func (p PromptInput) submit() (PromptInput, tea.Cmd) {
+	text := p.input.Value()
+	var cmd tea.Cmd
+	if literal, ok := commands.Literal(text); p.commands != nil && ok {
+		cmd = func() tea.Msg { return PromptSubmittedMsg{Text: literal} }
+	} else if p.commands != nil && commands.IsCommand(text) {
+		result, err := p.commands.Execute(text)
+		if err != nil {
+			p.err = err
+			return p, nil
+		}
+		cmd = func() tea.Msg { return CommandMsg{Msg: result} }
+	} else {
+		cmd = func() tea.Msg { return PromptSubmittedMsg{Text: text} }
+	}
+
+	if strings.TrimSpace(text) != "" {
+		p.history = append(p.history, text)
+	}
+	p.historyIndex = len(p.history)
+	p.pending = ""
+	p.err = nil
+	p.input.Reset()
+	p.updateSuggestions()
+	return p, cmd
+}
+
+// recall replaces the input with the history entry at index, stashing the
+// text being written when leaving it.
+func (p *PromptInput) recall(index int) {
+	if p.historyIndex == len(p.history) {
+		p.pending = p.input.Value()
+	}
+	p.historyIndex = index
+	if index == len(p.history) {
+		p.input.SetValue(p.pending)
+	} else {
+		p.input.SetValue(p.history[index])
+	}
+	p.updateSuggestions()
+}
+
+// openEditor suspends the program and edits text in $VISUAL or $EDITOR.
+func openEditor(text string) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+
+	f, err := os.CreateTemp("", "mana-prompt-*.md")
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer func() { _ = os.Remove(path) }()
+		if err != nil {
+			return editorFinishedMsg{err: fmt.Errorf("editor failed: %w", err)}
+		}
+		data, err := os.ReadFile(path)
+		return editorFinishedMsg{text: string(data), err: err}
+	})
+}
+
+func (p *PromptInput) updateSuggestions() {
+	p.suggestions = nil
+	p.suggestion = 0
+	if p.commands == nil {
+		return
+	}
+	p.suggestions = p.commands.Suggest(p.input.Value())
+}
+
+// suggestionsView renders completions for the command being typed, or the
+// usage of the command once its name is complete.
+func (p PromptInput) suggestionsView() string {
+	if len(p.suggestions) == 0 {
+		if p.commands == nil || !commands.IsCommand(p.input.Value()) {
+			return ""
+		}
+		name, _ := commands.Split(p.input.Value())
+		if c, ok := p.commands.Lookup(name); ok {
+			return p.styles.DimText.Render(c.Usage() + "  " + c.Description)
+		}
+		return ""
+	}
+
+	// Keep the selected suggestion inside the visible window
+	start := 0
+	if p.suggestion >= maxSuggestions {
+		start = p.suggestion - maxSuggestions + 1
+	}
+	end := min(len(p.suggestions), start+maxSuggestions)
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		if i == p.suggestion {
+			lines = append(lines, p.styles.ChosenItem.Render("> "+p.suggestions[i].Hint))
+		} else {
+			lines = append(lines, p.styles.DimText.Render("  "+p.suggestions[i].Hint))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// View renders the editor followed by command suggestions and the last error.
+func (p PromptInput) View() string {
+	content := p.input.View()
+	if suggestions := p.suggestionsView(); suggestions != "" {
+		content += "\n" + suggestions
+	}
+	if p.err != nil {
+		content += "\n" + p.styles.ErrorText.Render(p.err.Error())
+	}
+	return content
+}
+
+// ExtraLines is the number of rows View adds below the editor for
+// suggestions and errors.
+func (p PromptInput) ExtraLines() int {
+	n := 0
+	if s := p.suggestionsView(); s != "" {
+		n += strings.Count(s, "\n") + 1
+	}
+	if p.err != nil {
+		n++
+	}
+	return n
+}
+
+// VisualLines is the number of rows the text takes up at the current width,
+// counting soft-wrapped lines.
+func (p PromptInput) VisualLines() int {
+	width := max(1, p.input.Width())
+	n := 0
+	for _, line := range strings.Split(p.input.Value(), "\n") {
+		n += max(1, (lipgloss.Width(line)+width)/width)
+	}
+	return n
+}
+
+func (p *PromptInput) SetWidth(w int)     { p.input.SetWidth(w) }
+func (p *PromptInput) SetHeight(h int)    { p.input.SetHeight(h) }
+func (p *PromptInput) SetStyles(s Styles) { p.styles = s }
+func (p *PromptInput) Focus() tea.Cmd     { return p.input.Focus() }
+func (p *PromptInput) Blur()              { p.input.Blur() }
+func (p PromptInput) Value() string       { return p.input.Value() }
+
+// SetValue replaces the text and moves the cursor to its end.
+func (p *PromptInput) SetValue(s string) {
+	p.input.SetValue(s)
+	p.updateSuggestions()
+}
+
+// HasSuggestions reports whether tab would complete a command.
+func (p PromptInput) HasSuggestions() bool { return len(p.suggestions) > 0 }
+
+// SubmitHelp and NewlineHelp describe the configured keys for control hints.
+func (p PromptInput) SubmitHelp() key.Help  { return p.keys.Submit.Help() }
+func (p PromptInput) NewlineHelp() key.Help { return p.keys.Newline.Help() }
+
+func (p PromptInput) Bindings() []key.Binding {
+	return []key.Binding{p.keys.Submit, p.keys.Newline, p.keys.Editor, p.keys.Complete}
+}
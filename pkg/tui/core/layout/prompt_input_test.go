@@ -0,0 +1,94 @@
+package layout
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/tui/core/commands"
+)
+
+func press(code rune) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: code}
+}
+
+func TestPromptInput_Submit(t *testing.T) {
+	p := NewPromptInput("hello", PromptOptions{})
+	p.Focus()
+
+	p, cmd := p.Update(press(tea.KeyEnter))
+	if cmd == nil {
+		t.Fatal("submit returned no command")
+	}
+	msg, ok := cmd().(PromptSubmittedMsg)
+	if !ok || msg.Text != "hello" {
+		t.Fatalf("submit msg = %#v, want PromptSubmittedMsg{Text: hello}", cmd())
+	}
+	if p.Value() != "" {
+		t.Errorf("Value() after submit = %q, want empty", p.Value())
+	}
+
+	// The sent prompt can be recalled right away
+	p, _ = p.Update(press(tea.KeyUp))
+	if p.Value() != "hello" {
+		t.Errorf("Value() after up = %q, want hello", p.Value())
+	}
+}
+
+func TestPromptInput_SubmitLiteralSlash(t *testing.T) {
+	p := NewPromptInput("//etc/hosts is empty", PromptOptions{Commands: commands.NewRegistry()})
+	p.Focus()
+
+	_, cmd := p.Update(press(tea.KeyEnter))
+	if cmd == nil {
+		t.Fatal("submit returned no command")
+	}
+	if msg, ok := cmd().(PromptSubmittedMsg); !ok || msg.Text != "/etc/hosts is empty" {
+		t.Errorf("submit msg = %#v, want the text with a single slash", cmd())
+	}
+}
+
+func TestPromptInput_History(t *testing.T) {
+	p := NewPromptInput("draft", PromptOptions{History: []string{"first", "second"}})
+	p.Focus()
+
+	steps := []struct {
+		key  rune
+		want string
+	}{
+		{tea.KeyUp, "second"},
+		{tea.KeyUp, "first"},
+		{tea.KeyUp, "first"},
+		{tea.KeyDown, "second"},
+		{tea.KeyDown, "draft"},
+	}
+	for i, step := range steps {
+		p, _ = p.Update(press(step.key))
+		if p.Value() != step.want {
+			t.Fatalf("step %d: Value() = %q, want %q", i, p.Value(), step.want)
+		}
+	}
+}
+
+func TestPromptInput_VisualLines(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		want  int
+	}{
+		{value: "", width: 10, want: 1},
+		{value: "short", width: 10, want: 1},
+		{value: "one\ntwo\nthree", width: 10, want: 3},
+		// the textarea prompt takes two columns, leaving eight for text
+		{value: "this line is longer than ten", width: 10, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			p := NewPromptInput(tt.value, PromptOptions{})
+			p.SetWidth(tt.width)
+			if got := p.VisualLines(); got != tt.want {
+				t.Errorf("VisualLines() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package layout
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// ShowSelectDialogMsg requests opening a dialog to pick one of Options.
+// ID is echoed back in the resulting SelectedMsg so the requester can tell
+// its selections apart.
+type ShowSelectDialogMsg struct {
+	ID      string
+	Title   string
+	Options []string
+}
+
+// SelectedMsg is emitted by the select dialog when the user picks an option
+type SelectedMsg struct {
+	ID    string
+	Index int
+	Value string
+}
+
+// SelectDialog is a modal layer presenting a list of options
+type SelectDialog struct {
+	focused  bool
+	width    int
+	height   int
+	id       string
+	title    string
+	options  []string
+	selected int
+	keys     SelectKeyMap
+	styles   Styles
+}
+
+// NewSelectDialog creates a new select dialog
+func NewSelectDialog(id, title string, options []string) *SelectDialog {
+	return &SelectDialog{
+		id:      id,
+		title:   title,
+		options: options,
+		keys:    DefaultSelectKeyMap,
+		styles:  DefaultStyles,
+	}
+}
+
+// WithKeys replaces the dialog's key bindings.
+func (s *SelectDialog) WithKeys(keys SelectKeyMap) *SelectDialog {
+	s.keys = keys
+	return s
+}
+
+// WithStyles replaces the styles the dialog draws with.
+func (s *SelectDialog) WithStyles(styles Styles) *SelectDialog {
+	s.styles = styles
+	return s
+}
+
+func (s *SelectDialog) Init() tea.Cmd { return nil }
+
+func (s *SelectDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			if s.selected > 0 {
+				s.selected--
+			}
+		case key.Matches(msg, s.keys.Down):
+			if s.selected < len(s.options)-1 {
+				s.selected++
+			}
+		case key.Matches(msg, s.keys.Select):
+			if len(s.options) == 0 {
+				return s, func() tea.Msg { return CancelledMsg{} }
+			}
+			selected := SelectedMsg{ID: s.id, Index: s.selected, Value: s.options[s.selected]}
+			return s, func() tea.Msg { return selected }
+		case key.Matches(msg, s.keys.Cancel):
+			return s, func() tea.Msg { return CancelledMsg{} }
+		}
+	}
+	return s, nil
+}
+
+func (s *SelectDialog) View() string {
+	style := s.styles.DialogBox.Width(40)
+
+	var b strings.Builder
+	if s.title != "" {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render(s.title))
+		b.WriteString("\n\n")
+	}
+	for i, option := range s.options {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if i == s.selected {
+			b.WriteString(s.styles.ChosenItem.Render("> " + option))
+		} else {
+			b.WriteString("  " + option)
+		}
+	}
+	if len(s.options) == 0 {
+		b.WriteString("Nothing to select")
+	}
+
+	return style.Render(b.String())
+}
+
+func (s *SelectDialog) SetSize(width, height int) tea.Cmd {
+	s.width, s.height = width, height
+	return nil
+}
+func (s *SelectDialog) GetSize() (int, int) { return s.width, s.height }
+func (s *SelectDialog) SetFocused(focused bool) (FocusScope, tea.Cmd) {
+	s.focused = focused
+	return s, nil
+}
+func (s *SelectDialog) IsFocused() bool   { return s.focused }
+func (s *SelectDialog) Clone() FocusScope { clone := *s; return &clone }
+func (s *SelectDialog) Bindings() []key.Binding {
+	return []key.Binding{s.keys.Up, s.keys.Down, s.keys.Select, s.keys.Cancel}
+}
+func (s *SelectDialog) LayerMeta() LayerMeta {
+	return LayerMeta{
+		ID:          "select",
+		Z:           100,
+		Modal:       true,
+		CaptureKeys: true,
+		DismissKeys: keyNames(s.keys.Cancel),
+		Scrim:       true,
+		Pos:         Position{Anchor: Center},
+	}
+}
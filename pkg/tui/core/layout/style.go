@@ -0,0 +1,58 @@
+package layout
+
+import (
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/tui/core/theme"
+)
+
+// Styles are what the dialogs and lists of this package draw with. They are
+// made from a theme by NewStyles; each component holds its own copy.
+type Styles struct {
+	// Frame of every dialog; each sets its own width
+	DialogBox lipgloss.Style
+
+	// Key hints under dialogs: the confirming key, the cancelling key and others
+	ConfirmKey lipgloss.Style
+	CancelKey  lipgloss.Style
+	InfoKey    lipgloss.Style
+
+	// The option or suggestion under the cursor
+	ChosenItem lipgloss.Style
+
+	DimText   lipgloss.Style
+	ErrorText lipgloss.Style
+
+	// What lies beneath a layer with a scrim
+	ScrimText lipgloss.Style
+
+	// Toasts by level
+	ToastInfo  lipgloss.Style
+	ToastWarn  lipgloss.Style
+	ToastError lipgloss.Style
+}
+
+// DefaultStyles draw with the dark theme.
+var DefaultStyles = NewStyles(theme.Dark)
+
+// NewStyles returns the styles drawing with the colors of t.
+func NewStyles(t theme.Theme) Styles {
+	toast := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Foreground(t.Text)
+	return Styles{
+		DialogBox: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Border).
+			Padding(1, 2).
+			Foreground(t.Text),
+		ConfirmKey: lipgloss.NewStyle().Foreground(t.Success),
+		CancelKey:  lipgloss.NewStyle().Foreground(t.Error),
+		InfoKey:    lipgloss.NewStyle().Foreground(t.Info),
+		ChosenItem: lipgloss.NewStyle().Foreground(t.Success),
+		DimText:    lipgloss.NewStyle().Foreground(t.Subtle),
+		ErrorText:  lipgloss.NewStyle().Foreground(t.Error),
+		ScrimText:  lipgloss.NewStyle().Foreground(t.Subtle).Faint(true),
+		ToastInfo:  toast.BorderForeground(t.Info),
+		ToastWarn:  toast.BorderForeground(t.Warning),
+		ToastError: toast.BorderForeground(t.Error),
+	}
+}
@@ -0,0 +1,107 @@
+package layout
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// ToastLevel sets how a toast is drawn
+type ToastLevel int
+
+const (
+	ToastInfo ToastLevel = iota
+	ToastWarn
+	ToastError
+)
+
+const (
+	// DefaultToastTTL is how long a toast without a TTL is shown
+	DefaultToastTTL = 3 * time.Second
+	// maxToasts is the number of toasts shown at once; older ones make room
+	maxToasts = 4
+	// toastWidth is the width toasts wrap at
+	toastWidth = 40
+)
+
+// ShowToastMsg shows a notification for TTL, or DefaultToastTTL when zero.
+// Toasts are drawn above every layer and never take input.
+type ShowToastMsg struct {
+	Level ToastLevel
+	Text  string
+	TTL   time.Duration
+}
+
+// toastExpiredMsg removes the toast with ID once its TTL passed
+type toastExpiredMsg struct {
+	ID int
+}
+
+// Toast returns a command showing text at level for DefaultToastTTL
+func Toast(level ToastLevel, text string) tea.Cmd {
+	return func() tea.Msg { return ShowToastMsg{Level: level, Text: text} }
+}
+
+type toast struct {
+	id    int
+	level ToastLevel
+	text  string
+}
+
+// toastStack holds the toasts shown, oldest first
+type toastStack struct {
+	toasts []toast
+	nextID int
+}
+
+// show adds a toast and returns the timer that expires it
+func (s *toastStack) show(msg ShowToastMsg) tea.Cmd {
+	s.nextID++
+	id := s.nextID
+	s.toasts = append(s.toasts, toast{id: id, level: msg.Level, text: msg.Text})
+	if len(s.toasts) > maxToasts {
+		s.toasts = s.toasts[len(s.toasts)-maxToasts:]
+	}
+
+	ttl := msg.TTL
+	if ttl <= 0 {
+		ttl = DefaultToastTTL
+	}
+	return tea.Tick(ttl, func(time.Time) tea.Msg { return toastExpiredMsg{ID: id} })
+}
+
+// expire removes the toast with id, if it is still shown
+func (s *toastStack) expire(id int) {
+	for i, t := range s.toasts {
+		if t.id == id {
+			s.toasts = append(s.toasts[:i:i], s.toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+// View stacks the toasts, newest at the bottom, wrapped to fit maxWidth
+func (s *toastStack) View(maxWidth int, styles Styles) string {
+	if len(s.toasts) == 0 {
+		return ""
+	}
+	width := min(toastWidth, maxWidth)
+	boxes := make([]string, len(s.toasts))
+	for i, t := range s.toasts {
+		style := styles.ToastInfo
+		switch t.level {
+		case ToastWarn:
+			style = styles.ToastWarn
+		case ToastError:
+			style = styles.ToastError
+		}
+		// Width includes the border; the text wraps inside
+		boxes[i] = style.Width(width).Render(strings.TrimSpace(t.text))
+	}
+	return lipgloss.JoinVertical(lipgloss.Right, boxes...)
+}
+
+// toastPos anchors toasts to the bottom right, above the status bar
+var toastPos = Position{Anchor: BottomRight, X: -1, Y: -1}
@@ -0,0 +1,76 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/tui/tuitest"
+)
+
+// toastTexts returns the texts of the toasts shown, oldest first
+func toastTexts(lm *LayerManager) []string {
+	var texts []string
+	for _, t := range lm.toasts.toasts {
+		texts = append(texts, t.text)
+	}
+	return texts
+}
+
+func TestLayerManager_Toasts(t *testing.T) {
+	lm := NewLayerManager()
+	lm.SetSize(30, 10)
+
+	var expire []tea.Cmd
+	for i := range maxToasts + 1 {
+		_, cmd, handled := lm.Update(ShowToastMsg{Text: fmt.Sprint(i), TTL: time.Millisecond})
+		if !handled || cmd == nil {
+			t.Fatalf("toast %d: handled = %v, cmd = %v; want a handled timer", i, handled, cmd)
+		}
+		expire = append(expire, cmd)
+	}
+	if got, want := strings.Join(toastTexts(lm), " "), "1 2 3 4"; got != want {
+		t.Errorf("toasts = %q, want the newest %q", got, want)
+	}
+
+	// The timer of a toast that made room expires nothing
+	lm.Update(expire[0]())
+	lm.Update(expire[2]())
+	if got, want := strings.Join(toastTexts(lm), " "), "1 3 4"; got != want {
+		t.Errorf("toasts after expiry = %q, want %q", got, want)
+	}
+}
+
+func TestLayerManager_ToastsTakeNoInput(t *testing.T) {
+	lm := NewLayerManager()
+	lm.SetSize(30, 10)
+	lm.Update(ShowToastMsg{Text: "hello"})
+
+	if _, _, handled := lm.Update(tea.KeyPressMsg{Code: 'x', Text: "x"}); handled {
+		t.Error("a key was handled with only a toast shown")
+	}
+	if _, _, handled := lm.Update(tea.MouseClickMsg{X: 29, Y: 8, Button: tea.MouseLeft}); handled {
+		t.Error("a click on a toast was handled")
+	}
+
+	layer := &testLayer{meta: LayerMeta{ID: "a", CaptureKeys: true}}
+	lm.Push(layer)
+	key := tea.KeyPressMsg{Code: 'x', Text: "x"}
+	lm.Update(key)
+	if len(layer.msgs) != 1 || layer.msgs[0] != key {
+		t.Errorf("layer got %v, want the key", layer.msgs)
+	}
+}
+
+func TestLayerManager_RenderToasts(t *testing.T) {
+	base := strings.TrimSuffix(strings.Repeat(strings.Repeat(".", 30)+"\n", 10), "\n")
+	lm := NewLayerManager()
+	lm.SetSize(30, 10)
+	lm.Push(&testLayer{meta: LayerMeta{ID: "a", Scrim: true, Pos: Position{Anchor: TopLeft}}, text: testBox})
+	lm.Update(ShowToastMsg{Level: ToastInfo, Text: "saved"})
+	lm.Update(ShowToastMsg{Level: ToastError, Text: "failed to export: permission denied"})
+	tuitest.AssertGolden(t, "toasts", lm.RenderOver(base))
+}
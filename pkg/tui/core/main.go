@@ -2,8 +2,12 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/charmbracelet/bubbles/v2/key"
@@ -12,41 +16,186 @@ import (
 	"github.com/charmbracelet/glamour/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 
+	"github.com/darling/mana/pkg/config"
 	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/markdown"
+	"github.com/darling/mana/pkg/persona"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/templates"
 	"github.com/darling/mana/pkg/tui/core/layout"
 )
 
+// compactKeep is how many trailing messages a compaction leaves untouched.
+const compactKeep = 2
+
+// headerHeight is the number of rows the conversation header takes up.
+const headerHeight = 1
+
+// Select dialog IDs used by the main view.
+const (
+	selectNewConversation = "main.new-conversation"
+	selectPersona         = "main.persona"
+	selectTemplate        = "main.template"
+	selectModel           = "main.model"
+)
+
+// maxAttachmentSize is the largest file /attach accepts.
+const maxAttachmentSize = 256 << 10
+
+// formTemplatePrefix prefixes the form dialog ID used to fill in a template.
+const formTemplatePrefix = "main.template:"
+
+// formCompare is the form dialog ID asking which models to compare.
+const formCompare = "main.compare"
+
 type MainCmp struct {
-	focused    bool
-	width      int
-	height     int
-	vp         viewport.Model
+	focused bool
+	width   int
+	height  int
+	vp      viewport.Model
+	// tree holds every message and branch; messages is the branch shown,
+	// kept in sync by setTree.
+	tree       llm.Tree
 	messages   []llm.Message
+	archived   []llm.Message
 	llmManager *llm.Manager
 	keys       mainKeyMap
+	selectKeys selectKeyMap
+	searchKeys searchKeyMap
 	renderer   *glamour.TermRenderer
+	rendered   *renderCache
+	styles     styles
+
+	store        *store.Store
+	conversation store.Conversation
+
+	personas  *persona.Library
+	persona   persona.Persona
+	templates *templates.Library
+
+	// model overrides the persona's model for this conversation when set.
+	model string
+	// attachments are files prepended to the next submitted prompt.
+	attachments []attachment
+
+	// err is the last error, shown in the header until the next action succeeds.
+	err error
+
+	// compactThreshold is the estimated token count above which the
+	// conversation is compacted after a response; zero never compacts.
+	compactThreshold int
+	compacting       bool
+
+	// selecting is set while a cursor moves over messages and code blocks;
+	// selection indexes selectionTargets.
+	selecting bool
+	selection int
+	// yanking is set after the yank key while the block number is typed.
+	yanking bool
+	yank    string
+	// editing is the ID of the user message being rewritten in the prompt.
+	editing string
+	// compareModels are the models last compared, offered again next time.
+	compareModels []string
+
+	// searching is set while the search query is typed. The matches of
+	// search stay highlighted until it is cleared; match is the current one
+	// and searchOrigin the scroll position the search started from.
+	searching    bool
+	search       string
+	match        int
+	searchOrigin int
+	// transcript is the rendered conversation without highlights, and
+	// matches the search's occurrences in it; both are kept by redraw.
+	transcript string
+	matches    []searchMatch
+
+	// streaming is the part of an answer received so far, shown below
+	// the messages until the answer is complete.
+	streaming string
+
+	// titles configures naming conversations; titling is set while a title
+	// is being written, and titleFailed is the conversation naming last
+	// failed for, which is not tried again while it stays open.
+	titles      config.TitleConfig
+	titling     bool
+	titleFailed string
 }
 
-// ChatResponseMsg is delivered when the LLM returns a response
+type attachment struct {
+	Path    string
+	Content string
+}
+
+// ChatResponseMsg is delivered when the LLM returns a response.
+// ParentID is the message it answers, which stays its parent even if the
+// branch shown changed in the meantime. Responses arriving after another
+// conversation was opened are dropped.
 type ChatResponseMsg struct {
-	Message llm.Message
-	Err     error
+	ConversationID string
+	Message        llm.Message
+	ParentID       string
+	Err            error
+}
+
+// ChatChunkMsg is delivered for each part of an answer the provider
+// streams, before the ChatResponseMsg with the whole answer.
+type ChatChunkMsg struct {
+	ConversationID string
+	ParentID       string
+	Chunk          string
+
+	stream chan tea.Msg
+}
+
+// CompactMsg requests that everything except the last Keep messages is
+// replaced by a model-written summary.
+type CompactMsg struct {
+	Keep int
+}
+
+// CompactedMsg is delivered when a compaction summary has been generated.
+// LeafID is the last message summarized: the summary only replaces the
+// first Before messages while that one is still last among them in the
+// conversation it was written for.
+type CompactedMsg struct {
+	ConversationID string
+	LeafID         string
+	Summary        llm.Message
+	Before         int
+	Err            error
+}
+
+// ConversationSavedMsg is delivered after the conversation was written to the store.
+type ConversationSavedMsg struct {
+	ID           string
+	Conversation store.Conversation
+	Err          error
 }
 
-func NewMainCmp(manager *llm.Manager) MainCmp {
+func NewMainCmp(manager *llm.Manager, st *store.Store, personas *persona.Library, tmpls *templates.Library) MainCmp {
 	// Initialize with a sane default renderer; will be resized on first ComponentSizeMsg
-	var r *glamour.TermRenderer
-	if tmp, err := glamour.NewTermRenderer(
-		glamour.WithEnvironmentConfig(),
-		glamour.WithStandardStyle("dark"),
-		glamour.WithWordWrap(80),
-	); err == nil {
-		r = tmp
+	r := newMarkdownRenderer(defaultStyles.Markdown, 80)
+	if personas == nil {
+		personas = persona.NewLibrary()
+	}
+	if tmpls == nil {
+		tmpls, _ = templates.Load()
 	}
+	defaultPersona, _ := personas.Get(persona.DefaultName)
 	return MainCmp{
-		keys:       DefaultMainKeyMap,
-		llmManager: manager,
-		renderer:   r,
+		keys:         DefaultMainKeyMap,
+		selectKeys:   DefaultSelectKeyMap,
+		searchKeys:   DefaultSearchKeyMap,
+		llmManager:   manager,
+		renderer:     r,
+		rendered:     newRenderCache(),
+		styles:       defaultStyles,
+		store:        st,
+		conversation: store.NewConversation(),
+		personas:     personas,
+		persona:      defaultPersona,
+		templates:    tmpls,
 	}
 }
 
@@ -57,70 +206,232 @@ func (m MainCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case layout.ComponentSizeMsg:
-		sidebarWidth := msg.Width / 4
-		newM.width = msg.Width - sidebarWidth
+		newM.width = msg.Width
 		newM.height = msg.Height
 		innerW, innerH := newM.innerDimensions()
 		newM.vp = viewport.New(
 			viewport.WithWidth(innerW),
-			viewport.WithHeight(innerH),
+			viewport.WithHeight(max(1, innerH-headerHeight)),
 		)
 		// (Re)create markdown renderer to match inner width
-		if r, err := glamour.NewTermRenderer(
-			glamour.WithEnvironmentConfig(),
-			glamour.WithStandardStyle("dark"),
-			glamour.WithWordWrap(innerW),
-		); err == nil {
-			newM.renderer = r
-		} else {
-			newM.renderer = nil
-		}
-		newM.vp.SetContent(newM.renderMessages(innerW))
+		newM.renderer = newMarkdownRenderer(newM.styles.Markdown, innerW)
+		newM = newM.redraw(innerW)
+	case ThemeChangedMsg:
+		newM = newM.setStyles(newStyles(msg.Theme))
 	case layout.ConfirmedMsg:
 		// no-op in chat view
 	case layout.CancelledMsg:
 		// no-op in chat view
 	case layout.PromptSubmittedMsg:
-		text := strings.TrimSpace(msg.Text)
-		if text == "" {
-			return newM, nil
+		if newM.editing != "" {
+			return newM.resend(newM.editing, msg.Text)
 		}
-		// Append user message
-		newM.messages = append(newM.messages, llm.Message{Role: "user", Content: text})
-		innerW, _ := newM.innerDimensions()
-		newM.vp.SetContent(newM.renderMessages(innerW))
-		newM.vp.GotoBottom()
-
-		// If we have an LLM, fire off generation
-		if newM.llmManager != nil {
-			history := append([]llm.Message(nil), newM.messages...)
-			cmd := func() tea.Msg {
-				resp, err := newM.llmManager.Generate(context.Background(), history)
-				return ChatResponseMsg{Message: resp, Err: err}
-			}
-			return newM, cmd
+		return newM.submit(msg.Text)
+	case layout.PromptCancelledMsg:
+		newM.editing = ""
+	case ChatChunkMsg:
+		// Chunks of an answer to another conversation are still read, so
+		// that the request can finish
+		if msg.ConversationID == newM.conversation.ID && msg.ParentID == newM.tree.Leaf {
+			newM.streaming += msg.Chunk
+			innerW, _ := newM.innerDimensions()
+			newM = newM.redraw(innerW)
+			newM.vp.GotoBottom()
 		}
+		return newM, nextChunk(msg.stream)
 	case ChatResponseMsg:
+		if msg.ConversationID != newM.conversation.ID {
+			return newM, nil
+		}
+		newM.streaming = ""
+		newM.err = msg.Err
 		if msg.Err == nil && msg.Message.Content != "" {
-			newM.messages = append(newM.messages, llm.Message{Role: "assistant", Content: msg.Message.Content, Provider: msg.Message.Provider, ID: msg.Message.ID})
-			innerW, _ := newM.innerDimensions()
-			newM.vp.SetContent(newM.renderMessages(innerW))
+			tree := newM.tree.Clone()
+			if _, err := tree.AddTo(msg.ParentID, llm.Message{Role: "assistant", Content: msg.Message.Content, Provider: msg.Message.Provider, ID: msg.Message.ID, Model: msg.Message.Model, Usage: msg.Message.Usage}); err != nil {
+				newM.err = err
+				return newM, nil
+			}
+			newM = newM.setTree(tree)
 			newM.vp.GotoBottom()
+
+			cmds := []tea.Cmd{newM.saveCmd()}
+			if cmd := newM.titleCmd(); cmd != nil {
+				newM.titling = true
+				cmds = append(cmds, cmd)
+			}
+			if newM.compactThreshold > 0 && llm.EstimateTokens(newM.messages) > newM.compactThreshold {
+				var cmd tea.Cmd
+				newM, cmd = newM.compact(compactKeep)
+				cmds = append(cmds, cmd)
+			}
+			return newM, tea.Batch(cmds...)
+		}
+	case CompactMsg:
+		return newM.compact(msg.Keep)
+	case CopiedMsg:
+		if msg.Err != nil {
+			return newM, layout.Toast(layout.ToastError, "failed to copy: "+msg.Err.Error())
+		}
+		return newM, layout.Toast(layout.ToastInfo, "copied "+msg.What)
+	case layout.SelectedMsg:
+		switch msg.ID {
+		case selectNewConversation:
+			return newM.newConversation(msg.Value)
+		case selectPersona:
+			return newM.setPersona(msg.Value)
+		case selectTemplate:
+			return newM.openTemplate(msg.Value)
+		case selectModel:
+			return newM.setModel(msg.Value)
+		}
+	case SetModelMsg:
+		return newM.setModel(msg.Model)
+	case SetPersonaMsg:
+		return newM.setPersona(msg.Name)
+	case SetSystemPromptMsg:
+		return newM.setSystemPrompt(msg.Prompt)
+	case NewConversationMsg:
+		name := msg.Persona
+		if name == "" {
+			name = newM.persona.Name
+		}
+		return newM.newConversation(name)
+	case AttachFileMsg:
+		return newM.attach(msg.Path)
+	case RetryMsg:
+		return newM.retry()
+	case OpenTemplateMsg:
+		return newM.openTemplate(msg.Name)
+	case OpenConversationMsg:
+		return newM.openConversation(msg.ID, msg.MessageID)
+	case CompareMsg:
+		return newM.compare(msg.Models)
+	case ExportMsg:
+		return newM.export(msg.Format, msg.Path)
+	case ExportedMsg:
+		if msg.Err != nil {
+			return newM, layout.Toast(layout.ToastError, "failed to export: "+msg.Err.Error())
+		}
+		return newM, layout.Toast(layout.ToastInfo, "exported to "+msg.Path)
+	case ComparePickedMsg:
+		return newM.pickAnswer(msg)
+	case ConversationTitledMsg:
+		return newM.setTitle(msg)
+	case ConversationSavedMsg:
+		if msg.Err != nil {
+			return newM, layout.Toast(layout.ToastError, "failed to save conversation: "+msg.Err.Error())
+		}
+	case ConversationUpdatedMsg:
+		if msg.Err == nil {
+			newM = newM.syncConversation(msg.Conversation)
+		}
+	case ConversationDeletedMsg:
+		if msg.Err == nil && msg.ID == newM.conversation.ID {
+			return newM.newConversation(newM.persona.Name)
 		}
+	case layout.FormSubmittedMsg:
+		if name, ok := strings.CutPrefix(msg.ID, formTemplatePrefix); ok {
+			return newM.runTemplate(name, msg.Values)
+		}
+		if msg.ID == formCompare {
+			return newM.compare(strings.Fields(msg.Values["models"]))
+		}
+	case CompactedMsg:
+		if msg.ConversationID != newM.conversation.ID {
+			return newM, nil
+		}
+		newM.compacting = false
+		if msg.Before < 1 || msg.Before > len(newM.messages) || newM.messages[msg.Before-1].ID != msg.LeafID {
+			// The branch changed while the summary was written
+			return newM, nil
+		}
+		newM.err = msg.Err
+		if msg.Err != nil {
+			return newM, nil
+		}
+		tree := newM.tree.Clone()
+		archived, dropped := tree.Compact(msg.Before, msg.Summary)
+		newM.archived = append(append(newM.archived, archived...), dropped...)
+		newM = newM.setTree(tree)
+		if len(dropped) > 0 {
+			notice := fmt.Sprintf("archived %d messages on branches of the summarized turns", len(dropped))
+			return newM, tea.Batch(newM.saveCmd(), layout.Toast(layout.ToastInfo, notice))
+		}
+		return newM, newM.saveCmd()
 	case tea.KeyPressMsg:
 		if !m.focused {
 			return newM, nil
 		}
+		if m.searching {
+			return newM.updateSearch(msg)
+		}
+		if m.yanking {
+			return newM.updateYank(msg)
+		}
+		if m.selecting {
+			return newM.updateSelection(msg)
+		}
 
 		switch {
+		case m.search != "" && key.Matches(msg, m.searchKeys.Next):
+			return newM.moveMatch(1), nil
+		case m.search != "" && key.Matches(msg, m.searchKeys.Prev):
+			return newM.moveMatch(-1), nil
+		case m.search != "" && key.Matches(msg, m.searchKeys.Clear):
+			return newM.clearSearch(), nil
+		case key.Matches(msg, m.keys.Search):
+			return newM.startSearch()
 		case key.Matches(msg, m.keys.Redraw):
 			// force refresh
 			innerW, _ := newM.innerDimensions()
-			newM.vp.SetContent(newM.renderMessages(innerW))
+			newM = newM.redraw(innerW)
 		case key.Matches(msg, m.keys.Create):
 			return newM, func() tea.Msg { return layout.ShowPromptDialogMsg{} }
 		case key.Matches(msg, m.keys.ShowDialog):
 			return newM, func() tea.Msg { return layout.ShowPromptDialogMsg{} }
+		case key.Matches(msg, m.keys.Compact):
+			return newM.compact(compactKeep)
+		case key.Matches(msg, m.keys.Select):
+			return newM.startSelection()
+		case key.Matches(msg, m.keys.Regenerate):
+			return newM.retry()
+		case key.Matches(msg, m.keys.Edit):
+			for i := len(newM.messages) - 1; i >= 0; i-- {
+				if newM.messages[i].Role == "user" {
+					return newM.edit(newM.messages[i].ID)
+				}
+			}
+			return newM, nil
+		case key.Matches(msg, m.keys.PrevBranch):
+			return newM.switchBranch(newM.lastFork(), -1)
+		case key.Matches(msg, m.keys.NextBranch):
+			return newM.switchBranch(newM.lastFork(), 1)
+		case key.Matches(msg, m.keys.Compare):
+			models := strings.Join(newM.compareModels, " ")
+			if models == "" {
+				models = newM.currentModel()
+			}
+			return newM, func() tea.Msg {
+				return layout.ShowFormDialogMsg{ID: formCompare, Title: "Compare models", Fields: []layout.FormField{
+					{Name: "models", Value: models, Placeholder: "space separated"},
+				}}
+			}
+		case key.Matches(msg, m.keys.Yank):
+			if len(newM.codeBlocks()) == 0 {
+				newM.err = errors.New("no code blocks to yank")
+				return newM, nil
+			}
+			newM.yanking = true
+			return newM, nil
+		case key.Matches(msg, m.keys.NewConversation):
+			return newM, newM.selectPersonaCmd(selectNewConversation, "New conversation with persona")
+		case key.Matches(msg, m.keys.Persona):
+			return newM, newM.selectPersonaCmd(selectPersona, "Switch persona")
+		case key.Matches(msg, m.keys.Template):
+			options := newM.templates.Names()
+			return newM, func() tea.Msg {
+				return layout.ShowSelectDialogMsg{ID: selectTemplate, Title: "Prompt template", Options: options}
+			}
 		}
 
 		// Pass other keypresses to viewport for scrolling
@@ -143,20 +454,21 @@ func (m MainCmp) View() string {
 
 	var boxStyle lipgloss.Style
 	if m.focused {
-		boxStyle = FocusedBox
+		boxStyle = m.styles.FocusedBox
 	} else {
-		boxStyle = BlurredBox
+		boxStyle = m.styles.BlurredBox
 	}
 
 	// Render within a fixed-size box, clip and nowrap to avoid layout push
 	innerW, innerH := m.innerDimensions()
+	header := m.styles.ConversationHeader.Width(innerW).MaxWidth(innerW).MaxHeight(headerHeight).Render(m.headerText())
 	clipped := lipgloss.NewStyle().
-		Width(innerW).Height(innerH).
-		MaxWidth(innerW).MaxHeight(innerH).
+		Width(innerW).Height(max(1, innerH-headerHeight)).
+		MaxWidth(innerW).MaxHeight(max(1, innerH-headerHeight)).
 		// ensure no extra newlines/padding sneak in
 		Align(lipgloss.Left).
 		Render(content)
-	return boxStyle.Width(m.width).Height(m.height).Render(clipped)
+	return boxStyle.Width(m.width).Height(m.height).Render(lipgloss.JoinVertical(lipgloss.Left, header, clipped))
 }
 
 func (m MainCmp) SetFocused(focused bool) (layout.Focusable, tea.Cmd) {
@@ -173,49 +485,709 @@ func (m MainCmp) Clone() layout.Focusable {
 		width:      m.width,
 		height:     m.height,
 		vp:         m.vp,
+		tree:       m.tree.Clone(),
 		messages:   append([]llm.Message(nil), m.messages...),
+		archived:   append([]llm.Message(nil), m.archived...),
 		llmManager: m.llmManager,
 		keys:       m.keys,
+		selectKeys: m.selectKeys,
+		searchKeys: m.searchKeys,
 		renderer:   m.renderer,
+		rendered:   m.rendered,
+		styles:     m.styles,
+
+		store:        m.store,
+		conversation: m.conversation,
+
+		personas:  m.personas,
+		persona:   m.persona,
+		templates: m.templates,
+
+		model:       m.model,
+		attachments: append([]attachment(nil), m.attachments...),
+		err:         m.err,
+
+		compactThreshold: m.compactThreshold,
+		compacting:       m.compacting,
+
+		selecting: m.selecting,
+		selection: m.selection,
+		yanking:   m.yanking,
+		yank:      m.yank,
+		editing:   m.editing,
+
+		compareModels: append([]string(nil), m.compareModels...),
+
+		searching:    m.searching,
+		search:       m.search,
+		match:        m.match,
+		searchOrigin: m.searchOrigin,
+		transcript:   m.transcript,
+		matches:      m.matches,
+
+		streaming: m.streaming,
+
+		titles:      m.titles,
+		titling:     m.titling,
+		titleFailed: m.titleFailed,
 	}
 }
 
 func (m MainCmp) Bindings() []key.Binding {
-	return []key.Binding{m.keys.Redraw, m.keys.Create, m.keys.ShowDialog}
+	if m.searching {
+		return []key.Binding{m.searchKeys.Confirm, m.searchKeys.Clear}
+	}
+	if m.search != "" {
+		return []key.Binding{m.searchKeys.Next, m.searchKeys.Prev, m.searchKeys.Clear, m.keys.Search}
+	}
+	if m.selecting {
+		return []key.Binding{m.selectKeys.Up, m.selectKeys.Down, m.selectKeys.PrevBranch, m.selectKeys.NextBranch, m.selectKeys.Copy, m.selectKeys.CopyCode, m.selectKeys.CopyRaw, m.selectKeys.Edit, m.selectKeys.Compact, m.selectKeys.Exit}
+	}
+	return m.mainBindings()
+}
+
+// mainBindings are the keys of the main view outside its modes.
+func (m MainCmp) mainBindings() []key.Binding {
+	return []key.Binding{m.keys.Redraw, m.keys.Create, m.keys.ShowDialog, m.keys.Compact, m.keys.NewConversation, m.keys.Persona, m.keys.Template, m.keys.Select, m.keys.Yank, m.keys.Regenerate, m.keys.Edit, m.keys.PrevBranch, m.keys.NextBranch, m.keys.Compare, m.keys.Search}
+}
+
+// HandlesKey reports whether the main view takes msg: one of its bindings,
+// moving between the matches of a search or a key scrolling the
+// conversation. Other keys bubble up to the root.
+func (m MainCmp) HandlesKey(msg tea.KeyPressMsg) bool {
+	if m.CapturesKeys() {
+		return true
+	}
+	if m.search != "" && key.Matches(msg, m.searchKeys.Next, m.searchKeys.Prev, m.searchKeys.Clear) {
+		return true
+	}
+	for _, b := range m.mainBindings() {
+		if key.Matches(msg, b) {
+			return true
+		}
+	}
+	vk := m.vp.KeyMap
+	return key.Matches(msg, vk.PageDown, vk.PageUp, vk.HalfPageUp, vk.HalfPageDown, vk.Down, vk.Up, vk.Left, vk.Right)
+}
+
+// CapturesKeys reports whether a mode that takes every key, like selection,
+// typing a block number to yank or a search query, is active.
+func (m MainCmp) CapturesKeys() bool {
+	return m.focused && (m.selecting || m.yanking || m.searching)
+}
+
+// headerText describes the active persona and model.
+func (m MainCmp) headerText() string {
+	parts := []string{"persona: " + m.persona.Name}
+	if model := m.currentModel(); model != "" {
+		parts = append(parts, "model: "+model)
+	}
+	if len(m.attachments) > 0 {
+		paths := make([]string, len(m.attachments))
+		for i, a := range m.attachments {
+			paths[i] = filepath.Base(a.Path)
+		}
+		parts = append(parts, "attached: "+strings.Join(paths, ", "))
+	}
+	header := strings.Join(parts, " · ")
+	switch {
+	case m.editing != "":
+		header += " · editing message"
+	case m.yanking:
+		header += " · yank code block: " + m.yank
+	case m.selecting:
+		header += " · select"
+	case m.searching || m.search != "":
+		header += " · " + m.searchStatus()
+	}
+	if m.err != nil {
+		header += " · " + m.styles.ErrorText.Render("error: "+m.err.Error())
+	}
+	return header
 }
 
-func (m MainCmp) renderMessages(innerWidth int) string {
+// submit appends a user message and requests a response from the model.
+func (m MainCmp) submit(text string) (MainCmp, tea.Cmd) {
+	return m.submitTo(m.tree.Leaf, text)
+}
+
+// submitTo sends text as a reply to parentID. When parentID already has
+// replies, the message starts a new branch.
+func (m MainCmp) submitTo(parentID, text string) (MainCmp, tea.Cmd) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return m, nil
+	}
+	if len(m.attachments) > 0 {
+		var b strings.Builder
+		for _, a := range m.attachments {
+			fmt.Fprintf(&b, "`%s`:\n```\n%s\n```\n\n", a.Path, strings.TrimRight(a.Content, "\n"))
+		}
+		text = b.String() + text
+	}
+	// Append user message
+	tree := m.tree.Clone()
+	if _, err := tree.AddTo(parentID, llm.Message{Role: "user", Content: text}); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+	m.attachments = nil
+	m = m.setTree(tree)
+	m.vp.GotoBottom()
+
+	return m, tea.Batch(m.generate(), m.saveCmd())
+}
+
+// setTree replaces the message tree and redraws the branch it shows.
+func (m MainCmp) setTree(tree llm.Tree) MainCmp {
+	m.tree = tree
+	m.messages = tree.Path()
+	if m.selecting {
+		m.selection = min(m.selection, len(m.selectionTargets())-1)
+		m.selecting = m.selection >= 0
+	}
+	innerW, _ := m.innerDimensions()
+	m = m.redraw(innerW)
+	return m
+}
+
+// generate requests a response to the current history.
+func (m MainCmp) generate() tea.Cmd {
+	if m.llmManager == nil {
+		return nil
+	}
+	manager := m.llmManager
+	history := m.persona.Apply(append([]llm.Message(nil), m.messages...))
+	conversationID, parentID := m.conversation.ID, m.tree.Leaf
+	stream := make(chan tea.Msg)
+	opts := append(m.requestOptions(), llm.WithOnChunk(func(chunk string) {
+		stream <- ChatChunkMsg{ConversationID: conversationID, ParentID: parentID, Chunk: chunk, stream: stream}
+	}))
+	return func() tea.Msg {
+		go func() {
+			resp, err := manager.Generate(context.Background(), history, opts...)
+			stream <- ChatResponseMsg{ConversationID: conversationID, Message: resp, ParentID: parentID, Err: err}
+		}()
+		return <-stream
+	}
+}
+
+// nextChunk waits for the next part of a streamed answer, or its end.
+func nextChunk(stream chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-stream
+	}
+}
+
+// requestOptions returns the generation options for the conversation.
+func (m MainCmp) requestOptions() []llm.GenerateOption {
+	opts := m.persona.Options()
+	if m.model != "" {
+		opts = append(opts, llm.WithModel(m.model))
+	}
+	return opts
+}
+
+// currentModel returns the model the next request will use.
+func (m MainCmp) currentModel() string {
+	switch {
+	case m.model != "":
+		return m.model
+	case m.persona.Model != "":
+		return m.persona.Model
+	case m.llmManager != nil:
+		return m.llmManager.Model()
+	}
+	return ""
+}
+
+// retry asks for a new response to the last user message. The previous
+// response is kept as a sibling branch.
+func (m MainCmp) retry() (MainCmp, tea.Cmd) {
+	tree := m.tree.Clone()
+	if n := len(m.messages); n > 0 && m.messages[n-1].Role == "assistant" {
+		tree.SetLeaf(m.messages[n-1].ParentID)
+	}
+	if leaf, ok := tree.Get(tree.Leaf); !ok || leaf.Role != "user" {
+		m.err = errors.New("nothing to retry")
+		return m, nil
+	}
+	m.err = nil
+	m = m.setTree(tree)
+	m.vp.GotoBottom()
+	return m, m.generate()
+}
+
+// edit opens the prompt with the text of a user message. Sending it starts
+// a new branch next to the original; see resend.
+func (m MainCmp) edit(id string) (MainCmp, tea.Cmd) {
+	msg, ok := m.tree.Get(id)
+	if !ok || msg.Role != "user" {
+		m.err = errors.New("only your own messages can be edited")
+		return m, nil
+	}
+	m.err = nil
+	m.editing = id
+	m.selecting = false
+	text := msg.Content
+	return m, func() tea.Msg { return layout.ShowPromptDialogMsg{Text: text} }
+}
+
+// resend sends text in place of the user message id, keeping the original
+// and everything after it as a sibling branch.
+func (m MainCmp) resend(id, text string) (MainCmp, tea.Cmd) {
+	m.editing = ""
+	msg, ok := m.tree.Get(id)
+	if !ok {
+		return m.submit(text)
+	}
+	return m.submitTo(msg.ParentID, text)
+}
+
+// compare asks every model for an answer to the last user message and
+// opens the side-by-side view. A shown answer is compared too, as a branch.
+func (m MainCmp) compare(models []string) (MainCmp, tea.Cmd) {
+	if len(models) < 2 {
+		m.err = errors.New("give at least two models to compare")
+		return m, nil
+	}
+	history := append([]llm.Message(nil), m.messages...)
+	if n := len(history); n > 0 && history[n-1].Role == "assistant" {
+		history = history[:n-1]
+	}
+	if n := len(history); n == 0 || history[n-1].Role != "user" {
+		m.err = errors.New("nothing to compare, send a message first")
+		return m, nil
+	}
+	m.err = nil
+	m.compareModels = models
+	show := ShowCompareMsg{
+		Models:   models,
+		History:  m.persona.Apply(history),
+		ParentID: history[len(history)-1].ID,
+		Options:  m.requestOptions(),
+	}
+	return m, func() tea.Msg { return show }
+}
+
+// pickAnswer adds the compared answers as branches and continues with the
+// picked one.
+func (m MainCmp) pickAnswer(msg ComparePickedMsg) (MainCmp, tea.Cmd) {
+	if msg.Picked >= len(msg.Answers) {
+		return m, nil
+	}
+	tree := m.tree.Clone()
+	var picked string
+	for i, answer := range msg.Answers {
+		answer.Role = "assistant"
+		added, err := tree.AddTo(msg.ParentID, answer)
+		if err != nil {
+			// The conversation changed since the comparison started
+			return m, nil
+		}
+		if i == msg.Picked {
+			picked = added.ID
+		}
+	}
+	tree.SetLeaf(picked)
+	m = m.setTree(tree)
+	m.vp.GotoBottom()
+	return m, m.saveCmd()
+}
+
+// lastFork returns the ID of the last message on the branch shown that has
+// siblings, or "".
+func (m MainCmp) lastFork() string {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if siblings, _ := m.tree.Siblings(m.messages[i].ID); len(siblings) > 1 {
+			return m.messages[i].ID
+		}
+	}
+	return ""
+}
+
+// switchBranch shows the sibling delta positions away from message id.
+func (m MainCmp) switchBranch(id string, delta int) (MainCmp, tea.Cmd) {
+	siblings, index := m.tree.Siblings(id)
+	if len(siblings) < 2 {
+		return m, nil
+	}
+	next := (index + delta + len(siblings)) % len(siblings)
+	tree := m.tree.Clone()
+	tree.SwitchTo(siblings[next].ID)
+	m = m.setTree(tree)
+	return m, m.saveCmd()
+}
+
+func (m MainCmp) setModel(model string) (MainCmp, tea.Cmd) {
+	m.model = model
+	m.conversation.Model = model
+	if len(m.messages) == 0 {
+		return m, nil
+	}
+	return m, m.saveCmd()
+}
+
+// setSystemPrompt overrides the system prompt, or restores the persona's
+// own prompt when prompt is empty.
+func (m MainCmp) setSystemPrompt(prompt string) (MainCmp, tea.Cmd) {
+	if prompt == "" {
+		if p, ok := m.personas.Get(m.persona.Name); ok {
+			prompt = p.System
+		}
+		m.conversation.System = ""
+	} else {
+		m.conversation.System = prompt
+	}
+	m.persona.System = prompt
+	if len(m.messages) == 0 {
+		return m, nil
+	}
+	return m, m.saveCmd()
+}
+
+// attach reads a file to include with the next prompt.
+func (m MainCmp) attach(path string) (MainCmp, tea.Cmd) {
+	info, err := os.Stat(path)
+	if err == nil && info.Size() > maxAttachmentSize {
+		err = fmt.Errorf("%s is larger than %d KiB", path, maxAttachmentSize>>10)
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.err = nil
+	m.attachments = append(m.attachments, attachment{Path: path, Content: string(data)})
+	return m, nil
+}
+
+// openTemplate asks for the template's variables, or runs it right away
+// when it has none.
+func (m MainCmp) openTemplate(name string) (MainCmp, tea.Cmd) {
+	t, ok := m.templates.Get(name)
+	if !ok {
+		m.err = fmt.Errorf("unknown template %q", name)
+		return m, nil
+	}
+	if len(t.Vars) == 0 {
+		return m.runTemplate(name, nil)
+	}
+
+	fields := make([]layout.FormField, len(t.Vars))
+	for i, v := range t.Vars {
+		fields[i] = layout.FormField{Name: v.Name, Value: v.Default}
+		switch {
+		case v.Input == templates.InputFile:
+			fields[i].Placeholder = "path to file"
+		case v.Required:
+			fields[i].Placeholder = "required"
+		}
+	}
+	title := "Template: " + t.Name
+	if t.Description != "" {
+		title += " - " + t.Description
+	}
+	return m, func() tea.Msg {
+		return layout.ShowFormDialogMsg{ID: formTemplatePrefix + name, Title: title, Fields: fields}
+	}
+}
+
+// runTemplate renders a template and submits the result as a prompt.
+func (m MainCmp) runTemplate(name string, values map[string]string) (MainCmp, tea.Cmd) {
+	t, ok := m.templates.Get(name)
+	if !ok {
+		m.err = fmt.Errorf("unknown template %q", name)
+		return m, nil
+	}
+	resolved, err := t.Resolve(values, nil)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	text, err := t.Execute(resolved)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m.submit(text)
+}
+
+func (m MainCmp) selectPersonaCmd(id, title string) tea.Cmd {
+	options := m.personas.Names()
+	return func() tea.Msg {
+		return layout.ShowSelectDialogMsg{ID: id, Title: title, Options: options}
+	}
+}
+
+// setPersona switches the persona of the current conversation.
+func (m MainCmp) setPersona(name string) (MainCmp, tea.Cmd) {
+	p, ok := m.personas.Get(name)
+	if !ok {
+		return m, nil
+	}
+	m.persona = p
+	m.conversation.Persona = p.Name
+	m.conversation.System = ""
+	if len(m.messages) == 0 {
+		return m, nil
+	}
+	return m, m.saveCmd()
+}
+
+// openConversation replaces the current conversation with a stored one.
+// When messageID is set, the branch holding that message is shown and
+// scrolled to it.
+func (m MainCmp) openConversation(id, messageID string) (MainCmp, tea.Cmd) {
+	if m.store == nil {
+		return m, nil
+	}
+	c, err := m.store.Load(id)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m, _ = m.newConversation(c.Persona)
+	m.conversation = c
+	m.archived = c.Archived
+	m.model = c.Model
+	if c.System != "" {
+		m.persona.System = c.System
+	}
+	tree := llm.NewTree(c.Messages, c.Leaf)
+	if _, ok := tree.Get(messageID); ok {
+		tree.SwitchTo(messageID)
+	}
+	m = m.setTree(tree)
+	m.vp.GotoBottom()
+	if messageID != "" {
+		m = m.scrollToMessage(messageID)
+	}
+	return m, nil
+}
+
+// scrollToMessage scrolls the transcript so the message with id is at the top.
+func (m MainCmp) scrollToMessage(id string) MainCmp {
+	innerW, _ := m.innerDimensions()
+	_, offsets := m.renderTranscript(innerW)
+	for i, target := range m.selectionTargets() {
+		if target.Block < 0 && m.messages[target.Message].ID == id && i < len(offsets) {
+			m.vp.SetYOffset(offsets[i])
+			break
+		}
+	}
+	return m
+}
+
+// newConversation starts an empty conversation using the named persona.
+func (m MainCmp) newConversation(name string) (MainCmp, tea.Cmd) {
+	m.conversation = store.NewConversation()
+	m.tree = llm.Tree{}
+	m.messages = nil
+	m.archived = nil
+	m.editing = ""
+	m.selecting = false
+	m.attachments = nil
+	m.model = ""
+	m.compacting = false
+	m.titling = false
+	m.titleFailed = ""
+	m.streaming = ""
+	m.searching, m.search, m.matches = false, "", nil
+	m, cmd := m.setPersona(name)
+	innerW, _ := m.innerDimensions()
+	m = m.redraw(innerW)
+	return m, cmd
+}
+
+// compact starts summarizing everything except the last keep messages.
+func (m MainCmp) compact(keep int) (MainCmp, tea.Cmd) {
+	before := len(m.messages) - keep
+	if m.llmManager == nil || m.compacting || before < 1 {
+		return m, nil
+	}
+	// A lone summary at the top has nothing new to fold in.
+	if before == 1 && m.messages[0].Summarizes > 0 {
+		return m, nil
+	}
+
+	m.compacting = true
+	manager := m.llmManager
+	history := append([]llm.Message(nil), m.messages[:before]...)
+	conversationID, leafID := m.conversation.ID, history[before-1].ID
+	opts := m.requestOptions()
+	return m, func() tea.Msg {
+		summary, err := manager.Summarize(context.Background(), history, opts...)
+		return CompactedMsg{ConversationID: conversationID, LeafID: leafID, Summary: summary, Before: before, Err: err}
+	}
+}
+
+// snapshot returns the conversation as it would be stored.
+func (m MainCmp) snapshot() store.Conversation {
+	c := m.conversation
+	c.Messages = append([]llm.Message(nil), m.tree.Nodes...)
+	c.Leaf = m.tree.Leaf
+	c.Archived = append([]llm.Message(nil), m.archived...)
+	c.UpdatedAt = time.Now()
+	return c
+}
+
+// saveCmd persists the current conversation in the background.
+func (m MainCmp) saveCmd() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	c := m.snapshot()
+	st := m.store
+	return func() tea.Msg {
+		return ConversationSavedMsg{ID: c.ID, Conversation: c, Err: st.Save(c)}
+	}
+}
+
+func (m MainCmp) redraw(innerWidth int) MainCmp {
+	m, _ = m.redrawTargets(innerWidth)
+	return m
+}
+
+// redrawTargets renders the transcript into the viewport and finds the
+// search matches in it once, for the header and the highlights to reuse.
+// It returns the first row of every selection target.
+func (m MainCmp) redrawTargets(innerWidth int) (MainCmp, []int) {
+	var offsets []int
+	m.transcript, offsets = m.renderTranscript(innerWidth)
+	m.matches = findMatches(m.transcript, m.search)
+	m.vp.SetContent(highlightMatches(m.styles, m.transcript, m.matches, m.match))
+	return m, offsets
+}
+
+// renderTranscript renders the messages with numbered code blocks. It also
+// returns the first row of every selection target, in selectionTargets order.
+func (m MainCmp) renderTranscript(innerWidth int) (string, []int) {
 	if len(m.messages) == 0 {
-		return ""
+		return "", nil
+	}
+	selected, _ := m.selectedTarget()
+	if !m.selecting {
+		selected = selectTarget{Message: -1, Block: -1}
 	}
+
+	// lines counts the rows written so far, for the offsets
 	var b strings.Builder
+	lines := 0
+	write := func(s string) {
+		b.WriteString(s)
+		lines += strings.Count(s, "\n")
+	}
+
+	var offsets []int
+	branches := branchPositions(m.tree)
+	block := 1
 	for i, msg := range m.messages {
 		if i > 0 {
-			b.WriteString("\n\n")
-		}
-		// role header
-		role := msg.Role
-		if role == "" {
-			role = "assistant"
-		}
-		b.WriteString(fmt.Sprintf("%s:\n", role))
-		if m.renderer != nil {
-			if out, err := m.renderer.Render(msg.Content); err == nil {
-				b.WriteString(out)
-			} else {
-				b.WriteString(hardWrap(msg.Content, innerWidth))
-			}
+			write("\n\n")
+		}
+		offsets = append(offsets, lines)
+
+		count := len(markdown.CodeBlocks(msg.Content))
+		if msg.Summarizes > 0 {
+			write(m.styles.CompactionDivider.Render(fmt.Sprintf("── %d earlier messages compacted ──", msg.Summarizes)))
+			write("\n")
+			write(m.roleHeader("summary", i == selected.Message && selected.Block < 0))
 		} else {
-			b.WriteString(hardWrap(msg.Content, innerWidth))
+			// role header
+			role := msg.Role
+			if role == "" {
+				role = "assistant"
+			}
+			if branch := branches[msg.ID]; branch.count > 1 {
+				role += fmt.Sprintf(" (%d/%d)", branch.index+1, branch.count)
+			}
+			write(m.roleHeader(role, i == selected.Message && selected.Block < 0))
+		}
+
+		key := renderKey{id: msg.ID, width: innerWidth, first: block}
+		if selected.Block+1 >= block && selected.Block+1 < block+count {
+			key.selected = selected.Block + 1
 		}
+		rendered := m.rendered.render(key, msg.Content, func() string {
+			return m.renderContent(labelCodeBlocks(msg.Content, key.first, key.selected), innerWidth)
+		})
+		for _, row := range blockLines(rendered, block, count) {
+			offsets = append(offsets, lines+row)
+		}
+		write(rendered)
+		if msg.Summarizes > 0 {
+			write(m.styles.CompactionDivider.Render("── end of summary ──"))
+		}
+		block += count
 	}
-	return b.String()
+	m.rendered.sweep()
+	if m.streaming != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.roleHeader("assistant", false))
+		b.WriteString(m.renderContent(m.streaming, innerWidth))
+	}
+	return b.String(), offsets
+}
+
+// branch is the position of a message among its siblings and their number.
+type branch struct {
+	index, count int
+}
+
+// branchPositions returns the branch of every message of tree at once,
+// which Tree.Siblings would find one message at a time.
+func branchPositions(tree llm.Tree) map[string]branch {
+	counts := make(map[string]int)
+	branches := make(map[string]branch, len(tree.Nodes))
+	for _, msg := range tree.Nodes {
+		branches[msg.ID] = branch{index: counts[msg.ParentID]}
+		counts[msg.ParentID]++
+	}
+	for _, msg := range tree.Nodes {
+		b := branches[msg.ID]
+		b.count = counts[msg.ParentID]
+		branches[msg.ID] = b
+	}
+	return branches
+}
+
+// roleHeader renders the line introducing a message.
+func (m MainCmp) roleHeader(role string, selected bool) string {
+	if selected {
+		return m.styles.SelectedMessage.Render("▶ "+role+":") + "\n"
+	}
+	return role + ":\n"
+}
+
+// setStyles switches the styles and redraws the transcript with a markdown
+// renderer of the new glamour style.
+func (m MainCmp) setStyles(s styles) MainCmp {
+	m.styles = s
+	width := 80
+	if m.width > 0 {
+		width, _ = m.innerDimensions()
+	}
+	m.renderer = newMarkdownRenderer(s.Markdown, width)
+	m.rendered = newRenderCache()
+	return m.redraw(width)
+}
+
+// renderContent renders message markdown, falling back to plain wrapped text.
+func (m MainCmp) renderContent(content string, innerWidth int) string {
+	return renderMarkdown(m.renderer, content, innerWidth)
 }
 
 func (m MainCmp) innerDimensions() (int, int) {
 	// Compute inner dimensions based on the outer box style chrome.
 	// Focused and blurred styles currently share the same padding/frame sizes.
-	s := FocusedBox
+	s := m.styles.FocusedBox
 	innerW := m.width - s.GetHorizontalPadding() - s.GetHorizontalFrameSize()
 	innerH := m.height - s.GetVerticalPadding() - s.GetVerticalFrameSize()
 	if innerW < 1 {
@@ -227,6 +1199,24 @@ func (m MainCmp) innerDimensions() (int, int) {
 	return innerW, innerH
 }
 
+// conversationTitle returns title, or a snippet of the first user message
+// for conversations that have not been named yet.
+func conversationTitle(title string, messages []llm.Message) string {
+	if title != "" {
+		return title
+	}
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			snippet := strings.Join(strings.Fields(msg.Content), " ")
+			if r := []rune(snippet); len(r) > 60 {
+				snippet = string(r[:59]) + "…"
+			}
+			return snippet
+		}
+	}
+	return "Untitled conversation"
+}
+
 // hardWrap wraps a string to the given width by rune, avoiding control runes.
 func hardWrap(s string, width int) string {
 	if width <= 0 || s == "" {
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/llm/providers/fake"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// newMainTestCmp returns a focused main view with four messages and a
+// fake model to summarize them.
+func newMainTestCmp(t *testing.T) MainCmp {
+	t.Helper()
+	t.Setenv(fake.FixtureEnv, "testdata/fake.json")
+	manager, err := llm.NewManager("fake", llm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMainCmp(manager, nil, nil, nil)
+	updated, _ := m.SetFocused(true)
+	model, _ := updated.(MainCmp).Update(layout.ComponentSizeMsg{Width: 80, Height: 20})
+	return model.(MainCmp).setTree(llm.NewTree([]llm.Message{
+		{ID: "q1", Role: "user", Content: "first question"},
+		{ID: "a1", Role: "assistant", Content: "first answer"},
+		{ID: "q2", Role: "user", Content: "second question"},
+		{ID: "a2", Role: "assistant", Content: "second answer"},
+	}, ""))
+}
+
+func updateMain(t *testing.T, m MainCmp, msg tea.Msg) (MainCmp, tea.Cmd) {
+	t.Helper()
+	model, cmd := m.Update(msg)
+	return model.(MainCmp), cmd
+}
+
+func TestMainCmp_Streaming(t *testing.T) {
+	m := newMainTestCmp(t)
+	stream := make(chan tea.Msg, 1)
+	m, cmd := updateMain(t, m, ChatChunkMsg{ConversationID: m.conversation.ID, ParentID: "a2", Chunk: "partial ans", stream: stream})
+	if !strings.Contains(ansi.Strip(m.View()), "partial ans") {
+		t.Errorf("the partial answer is not shown:\n%s", ansi.Strip(m.View()))
+	}
+	// Chunks of another conversation are read but not shown
+	stream <- ChatChunkMsg{ConversationID: "other", ParentID: "a2", Chunk: "wer", stream: stream}
+	m, _ = updateMain(t, m, cmd())
+	if m.streaming != "partial ans" {
+		t.Errorf("streaming = %q after a chunk of another conversation", m.streaming)
+	}
+
+	m, _ = updateMain(t, m, ChatResponseMsg{ConversationID: m.conversation.ID, ParentID: "a2", Message: llm.Message{Content: "partial answer"}})
+	if m.streaming != "" || m.messages[len(m.messages)-1].Content != "partial answer" {
+		t.Errorf("the complete answer did not replace the partial one: %q", m.streaming)
+	}
+}
+
+func TestMainCmp_StreamingReusesRenders(t *testing.T) {
+	m := newMainTestCmp(t)
+	if len(m.rendered.entries) != len(m.messages) {
+		t.Fatalf("%d cached renders, want one per message", len(m.rendered.entries))
+	}
+	for key, entry := range m.rendered.entries {
+		entry.rendered = "cached " + key.id + "\n"
+		m.rendered.entries[key] = entry
+	}
+
+	stream := make(chan tea.Msg, 1)
+	m, _ = updateMain(t, m, ChatChunkMsg{ConversationID: m.conversation.ID, ParentID: "a2", Chunk: "partial ans", stream: stream})
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "cached a2") || !strings.Contains(view, "partial ans") {
+		t.Errorf("a chunk rendered the finished messages again:\n%s", view)
+	}
+
+	m, _ = updateMain(t, m, layout.ComponentSizeMsg{Width: 60, Height: 20})
+	if view := ansi.Strip(m.View()); strings.Contains(view, "cached") {
+		t.Errorf("renders of another width were reused:\n%s", view)
+	}
+	if len(m.rendered.entries) != len(m.messages) {
+		t.Errorf("%d cached renders after resizing, want one per message", len(m.rendered.entries))
+	}
+}
+
+func TestMainCmp_TitleFailed(t *testing.T) {
+	m := newMainTestCmp(t)
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.store = st
+	if m.titleCmd() == nil {
+		t.Fatal("no title requested for an untitled conversation")
+	}
+
+	m, _ = updateMain(t, m, ConversationTitledMsg{ID: m.conversation.ID, Err: errors.New("unknown model")})
+	if m.titleCmd() != nil {
+		t.Error("a failed title is requested again")
+	}
+	m, _ = m.newConversation(m.persona.Name)
+	m = m.setTree(llm.NewTree([]llm.Message{{Role: "user", Content: "q"}, {Role: "assistant", Content: "a"}}, ""))
+	if m.titleCmd() == nil {
+		t.Error("a failed title stops other conversations from being named")
+	}
+}
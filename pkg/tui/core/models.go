@@ -0,0 +1,122 @@
+package core
+
+import (
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// listModels is the ID of the list in the Models pane.
+const listModels = "models"
+
+// ModelsCmp is the sidebar pane listing the models of the provider.
+// Choosing one switches the conversation to it.
+type ModelsCmp struct {
+	focused bool
+	width   int
+	height  int
+
+	list   layout.List[string]
+	keys   modelsKeyMap
+	styles styles
+}
+
+func NewModelsCmp() ModelsCmp {
+	p := ModelsCmp{list: layout.NewList(listModels, func(model string, _ int) string { return model })}
+	p.list.WithFilter(func(model string) string { return model })
+	p.list.WithEmpty("No models")
+	p.list.SetLoading(true)
+	return p.setKeys(DefaultModelsKeyMap).setStyles(defaultStyles)
+}
+
+// setKeys sets the bindings of the pane's list.
+func (p ModelsCmp) setKeys(keys modelsKeyMap) ModelsCmp {
+	p.keys = keys
+	listKeys := layout.DefaultListKeyMap
+	listKeys.Up, listKeys.Down, listKeys.Select, listKeys.Filter = keys.Up, keys.Down, keys.Select, keys.Filter
+	p.list.WithKeys(listKeys)
+	return p
+}
+
+// setStyles sets the styles of the pane and of its list.
+func (p ModelsCmp) setStyles(s styles) ModelsCmp {
+	p.styles = s
+	p.list.WithStyles(s.Layout)
+	return p
+}
+
+func (p ModelsCmp) Init() tea.Cmd { return nil }
+
+func (p ModelsCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case layout.ComponentSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+		p.list.SetSize(paneListSize(p.width, p.height))
+	case ThemeChangedMsg:
+		p = p.setStyles(newStyles(msg.Theme))
+	case ModelsLoadedMsg:
+		if msg.Err != nil {
+			p.list.SetError(msg.Err)
+			return p, nil
+		}
+		p.list.SetItems(msg.Models)
+	case layout.ListSelectedMsg[string]:
+		if msg.ID == listModels {
+			return p, func() tea.Msg { return SetModelMsg{Model: msg.Item} }
+		}
+	case tea.KeyPressMsg:
+		if !p.focused {
+			return p, nil
+		}
+		_, cmd := p.list.Update(msg)
+		return p, cmd
+	case tea.MouseMsg:
+		x, y := paneListOffset()
+		_, cmd := p.list.Update(layout.TranslateMouse(msg, -x, -y))
+		return p, cmd
+	}
+	return p, nil
+}
+
+// HandlesKey reports whether the pane takes msg rather than the sidebar.
+func (p ModelsCmp) HandlesKey(msg tea.KeyPressMsg) bool {
+	return p.list.HandlesKey(msg)
+}
+
+// CapturesKeys reports whether the list filter is being typed.
+func (p ModelsCmp) CapturesKeys() bool {
+	return p.list.CapturesKeys()
+}
+
+func (p ModelsCmp) View() string {
+	boxStyle := p.styles.BlurredBox
+	if p.focused {
+		boxStyle = p.styles.FocusedBox
+	}
+	header := lipgloss.NewStyle().Bold(true).Render("Models")
+	view := lipgloss.JoinVertical(lipgloss.Top, header, p.list.View())
+	return boxStyle.Width(p.width).Height(p.height).Render(view)
+}
+
+func (p ModelsCmp) SetFocused(focused bool) (layout.Focusable, tea.Cmd) {
+	p.focused = focused
+	_, cmd := p.list.SetFocused(focused)
+	return p, cmd
+}
+
+func (p ModelsCmp) IsFocused() bool {
+	return p.focused
+}
+
+func (p ModelsCmp) Clone() layout.Focusable {
+	clone := p
+	clone.list = *p.list.Clone().(*layout.List[string])
+	return clone
+}
+
+func (p ModelsCmp) Bindings() []key.Binding {
+	return p.list.Bindings()
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/commands"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+// runKeyMsg replays a key binding chosen from the palette. When Target is
+// set the pane with that ID is focused first.
+type runKeyMsg struct {
+	Target string
+	Key    tea.KeyPressMsg
+}
+
+// OpenConversationMsg loads a stored conversation into the main view,
+// scrolled to the message with MessageID if set
+type OpenConversationMsg struct {
+	ID        string
+	MessageID string
+}
+
+// namedKeys maps key names used in bindings to their key codes.
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"tab":       tea.KeyTab,
+	"esc":       tea.KeyEscape,
+	"space":     tea.KeySpace,
+	"backspace": tea.KeyBackspace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"delete":    tea.KeyDelete,
+}
+
+// keyPress builds the key press that matches a binding key such as "ctrl+c".
+func keyPress(s string) (tea.KeyPressMsg, bool) {
+	var k tea.Key
+	parts := strings.Split(s, "+")
+	for _, mod := range parts[:len(parts)-1] {
+		switch mod {
+		case "ctrl":
+			k.Mod |= tea.ModCtrl
+		case "alt":
+			k.Mod |= tea.ModAlt
+		case "shift":
+			k.Mod |= tea.ModShift
+		default:
+			return tea.KeyPressMsg{}, false
+		}
+	}
+
+	name := parts[len(parts)-1]
+	if code, ok := namedKeys[name]; ok {
+		k.Code = code
+	} else if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError {
+		k.Code = r
+		if k.Mod == 0 {
+			k.Text = name
+		}
+	} else {
+		return tea.KeyPressMsg{}, false
+	}
+	return tea.KeyPressMsg(k), true
+}
+
+// bindingItems turns key bindings into palette items replayed on target.
+func bindingItems(category, target string, bindings []key.Binding) []layout.PaletteItem {
+	var items []layout.PaletteItem
+	for _, b := range bindings {
+		if !b.Enabled() || len(b.Keys()) == 0 {
+			continue
+		}
+		press, ok := keyPress(b.Keys()[0])
+		if !ok {
+			continue
+		}
+		items = append(items, layout.PaletteItem{
+			Title:    b.Help().Desc,
+			Category: category,
+			Key:      b.Help().Key,
+			Msg:      runKeyMsg{Target: target, Key: press},
+		})
+	}
+	return items
+}
+
+// paletteItems collects the actions the palette offers when it opens.
+// Stored conversations are added by paletteConversationsCmd.
+func (m rootCmp) paletteItems() []layout.PaletteItem {
+	var items []layout.PaletteItem
+
+	// Key bindings of every pane, then the global ones
+	for _, id := range m.focusManager.IDs() {
+		c, _ := m.focusManager.Get(id)
+		if h, ok := c.(layout.Help); ok {
+			items = append(items, bindingItems(id, id, h.Bindings())...)
+		}
+	}
+	items = append(items, bindingItems("global", "", []key.Binding{
+		m.keys.FocusNext, m.keys.FocusLeft, m.keys.FocusDown, m.keys.FocusUp, m.keys.FocusRight, m.keys.ToggleSidebar, m.keys.Maximize, m.keys.SidebarNarrow, m.keys.SidebarWiden, m.keys.Search, m.keys.Quit,
+	})...)
+
+	// Slash commands run directly when they take no required argument,
+	// otherwise they open the prompt ready for the argument.
+	for _, c := range m.commands.All() {
+		item := layout.PaletteItem{Title: c.Usage() + "  " + c.Description, Category: "command"}
+		if strings.HasPrefix(c.Args, "<") {
+			item.Msg = layout.ShowPromptDialogMsg{Text: commands.Prefix + c.Name + " "}
+		} else if msg, err := c.Run(""); err == nil {
+			item.Msg = msg
+		} else {
+			item.Msg = layout.ShowPromptDialogMsg{Text: commands.Prefix + c.Name + " "}
+		}
+		items = append(items, item)
+	}
+
+	for _, model := range m.models.models {
+		items = append(items, layout.PaletteItem{Title: model, Category: "model", Msg: SetModelMsg{Model: model}})
+	}
+	for _, name := range m.personas.Names() {
+		items = append(items, layout.PaletteItem{Title: name, Category: "persona", Msg: SetPersonaMsg{Name: name}})
+	}
+	for _, name := range m.templates.Names() {
+		items = append(items, layout.PaletteItem{Title: name, Category: "template", Msg: OpenTemplateMsg{Name: name}})
+	}
+	return items
+}
+
+// paletteConversationsCmd lists the stored conversations in the background
+// and adds those not archived to the open palette. When some files cannot
+// be read the others are still offered.
+func (m rootCmp) paletteConversationsCmd() tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+	st := m.store
+	return func() tea.Msg {
+		conversations, err := st.List()
+		var skipped *store.SkippedError
+		if err != nil && !errors.As(err, &skipped) {
+			return layout.ShowToastMsg{Level: layout.ToastError, Text: "failed to list conversations: " + err.Error()}
+		}
+		var items []layout.PaletteItem
+		for _, c := range conversations {
+			if !c.ArchivedAt.IsZero() {
+				continue
+			}
+			items = append(items, layout.PaletteItem{
+				Title:    conversationTitle(c.Title, c.Messages),
+				Category: "conversation",
+				Key:      c.UpdatedAt.Format("Jan 2 15:04"),
+				Msg:      OpenConversationMsg{ID: c.ID},
+			})
+		}
+		return layout.PaletteItemsMsg{Items: items}
+	}
+}
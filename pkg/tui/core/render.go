@@ -0,0 +1,82 @@
+package core
+
+import "github.com/charmbracelet/glamour/v2"
+
+// newMarkdownRenderer returns a renderer in the glamour style wrapping at
+// width, or nil when glamour cannot be set up; renderMarkdown falls back to
+// plain text then.
+func newMarkdownRenderer(style string, width int) *glamour.TermRenderer {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithEnvironmentConfig(),
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// renderMarkdown renders message markdown, falling back to plain wrapped text.
+func renderMarkdown(r *glamour.TermRenderer, content string, width int) string {
+	if r != nil {
+		if out, err := r.Render(content); err == nil {
+			return out
+		}
+	}
+	return hardWrap(content, width)
+}
+
+// renderCache keeps the rendered markdown of finished messages, so that a
+// redraw while an answer streams in only renders the new text. It belongs to
+// one renderer; replace it along with the renderer.
+type renderCache struct {
+	entries map[renderKey]renderedContent
+	// used collects the entries of the current redraw; sweep keeps only
+	// those.
+	used map[renderKey]renderedContent
+}
+
+// renderKey identifies the rendering of a message. first and selected are
+// the numbers its code blocks are labelled with: that of its first block
+// and that of the selected one if it is among them, or zero.
+type renderKey struct {
+	id              string
+	width           int
+	first, selected int
+}
+
+type renderedContent struct {
+	content  string
+	rendered string
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{
+		entries: make(map[renderKey]renderedContent),
+		used:    make(map[renderKey]renderedContent),
+	}
+}
+
+// render returns the rendering of content under key, calling render only
+// when it is not cached. Without a cache it always renders.
+func (c *renderCache) render(key renderKey, content string, render func() string) string {
+	if c == nil {
+		return render()
+	}
+	entry, ok := c.entries[key]
+	if !ok || entry.content != content {
+		entry = renderedContent{content: content, rendered: render()}
+	}
+	c.used[key] = entry
+	return entry.rendered
+}
+
+// sweep drops the entries the last redraw did not use, like those of
+// messages rendered at another width.
+func (c *renderCache) sweep() {
+	if c == nil {
+		return
+	}
+	c.entries, c.used = c.used, make(map[renderKey]renderedContent, len(c.used))
+}
@@ -1,14 +1,51 @@
 package core
 
 import (
+	"context"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/darling/mana/pkg/config"
+	"github.com/darling/mana/pkg/history"
 	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/panes"
+	"github.com/darling/mana/pkg/persona"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/templates"
+	"github.com/darling/mana/pkg/tui/core/commands"
 	"github.com/darling/mana/pkg/tui/core/components"
 	"github.com/darling/mana/pkg/tui/core/layout"
+	"github.com/darling/mana/pkg/tui/core/theme"
+)
+
+// IDs of the panes in the root focus manager. The input pane only exists
+// when the prompt is docked inline.
+const (
+	paneSidebar = panes.Sidebar
+	paneMain    = panes.Main
+	paneInput   = "input"
+	paneStatus  = "statusbar"
 )
 
+// minMainHeight is the fewest rows the inline prompt leaves the main view.
+const minMainHeight = 5
+
+// Bounds of the split between the sidebar and the main view. Windows too
+// narrow for both show only the main view.
+const (
+	minSidebarWidth = 16
+	minMainWidth    = 40
+	sidebarStep     = 2
+)
+
+// keyCapture is implemented by panes that take every key while focused, such
+// as text inputs or modal modes inside a pane.
+type keyCapture interface {
+	CapturesKeys() bool
+}
+
 type RootCmp interface {
 	components.Component
 }
@@ -16,38 +53,169 @@ type RootCmp interface {
 type rootCmp struct {
 	statusbar components.Component
 
-	keys keyMap
+	keys    keyMap
+	keyMaps KeyMaps
+	styles  styles
 
 	focusManager layout.FocusManager
 	layerManager *layout.LayerManager
 
 	width, height int
 
+	// layout is the arrangement of the panes; dragging is set while the
+	// divider between the sidebar and the main view is dragged
+	layout   *panes.Layout
+	dragging bool
+
 	llmManager *llm.Manager
+	store      *store.Store
+	personas   *persona.Library
+	templates  *templates.Library
+	commands   *commands.Registry
+	models     *modelCatalog
+	config     *config.Config
+	history    *history.History
+}
+
+// Options holds the dependencies of the TUI. Nil fields fall back to
+// in-memory defaults; nil Keys are loaded from Config.
+type Options struct {
+	Manager   *llm.Manager
+	Store     *store.Store
+	Personas  *persona.Library
+	Templates *templates.Library
+	Config    *config.Config
+	History   *history.History
+	Keys      *KeyMaps
+	Layout    *panes.Layout
 }
 
-func NewRootCmp(manager *llm.Manager) RootCmp {
-	sidebar := NewSidebarCmp()
-	main := NewMainCmp(manager)
+func NewRootCmp(opts Options) RootCmp {
+	personas, tmpls := opts.Personas, opts.Templates
+	cfg := opts.Config
+	if cfg == nil {
+		def := config.Default()
+		cfg = &def
+	}
+	// Until the terminal reports its background, auto means dark
+	s := defaultStyles
+	if t, err := theme.Load(*cfg, true); err == nil {
+		s = newStyles(t)
+	}
+	keys := DefaultKeyMaps()
+	if opts.Keys != nil {
+		keys = *opts.Keys
+	} else {
+		// Mistakes in the keys config are reported before the TUI starts
+		keys, _ = LoadKeyMaps(*cfg)
+	}
+
+	paneLayout := opts.Layout
+	if paneLayout == nil {
+		paneLayout, _ = panes.Load("")
+	}
+	sidebar := NewSidebarCmp(opts.Store, keys, s)
+	sidebar.SetWeights(paneLayout.SidebarWeights)
+	main := NewMainCmp(opts.Manager, opts.Store, personas, tmpls)
+	main.keys, main.selectKeys, main.searchKeys = keys.Main, keys.Select, keys.Search
+	main = main.setStyles(s)
 	statusbar := NewStatusBarCmp("v0.1.0")
 
-	focusables := []layout.Focusable{sidebar.Clone(), main.Clone()}
+	if personas == nil {
+		personas = persona.NewLibrary()
+	}
+	if tmpls == nil {
+		tmpls, _ = templates.Load()
+	}
+	main.titles = cfg.Titles
+	main.compactThreshold = cfg.Compact.Threshold
+	hist := opts.History
+	if hist == nil {
+		hist, _ = history.Load("", cfg.Prompt.HistorySize)
+	}
+	models := &modelCatalog{}
+	registry := newCommandRegistry(personas, tmpls, models)
+
+	fm := layout.NewFocusManager("", true)
+	fm, _ = fm.Add(paneSidebar, sidebar)
+	fm, _ = fm.Add(paneMain, main)
+	focus := paneMain
+	if cfg.Prompt.Inline {
+		input := NewInputCmp(hist.Draft(), cfg.Prompt.InlineMaxLines, layout.PromptOptions{
+			Commands: registry,
+			History:  hist.Prompts(),
+			Keys:     &keys.Prompt,
+		})
+		input.keys = keys.Input
+		input = input.setStyles(s)
+		fm, _ = fm.Add(paneInput, input)
+		focus = paneInput
+	}
+
+	// Focus the main panel (or the inline prompt) by default before first render
+	fm, _, _ = fm.Focus(focus)
 
-	fm := layout.NewFocusManager(focusables, true)
-	// Focus the main panel (index 1) by default before first render
-	fm, _, _ = fm.Focus(1)
+	layers := layout.NewLayerManager()
+	layers.SetStyles(s.Layout)
 
 	return rootCmp{
+		commands:     registry,
+		models:       models,
 		statusbar:    statusbar,
-		keys:         DefaultKeyMap,
+		keys:         keys.Global,
+		keyMaps:      keys,
+		styles:       s,
 		focusManager: fm,
-		layerManager: layout.NewLayerManager(),
-		llmManager:   manager,
+		layerManager: layers,
+		llmManager:   opts.Manager,
+		store:        opts.Store,
+		personas:     personas,
+		templates:    tmpls,
+		config:       cfg,
+		history:      hist,
+		layout:       paneLayout,
 	}
 }
 
 func (m rootCmp) Init() tea.Cmd {
-	return m.getHelpCmd()
+	cmds := []tea.Cmd{m.getHelpCmd(), m.loadModelsCmd(), tea.RequestBackgroundColor}
+	if sidebar, err := m.focusManager.Get(paneSidebar); err == nil {
+		cmds = append(cmds, sidebar.Init())
+	}
+	return tea.Batch(cmds...)
+}
+
+// saveLayoutCmd persists the arrangement of the panes in the background.
+// A copy is saved, so that later changes do not race with the write.
+func (m rootCmp) saveLayoutCmd() tea.Cmd {
+	l := *m.layout
+	return func() tea.Msg {
+		_ = l.Save()
+		return nil
+	}
+}
+
+// saveHistoryCmd persists prompt history in the background. Failures are
+// not fatal; the history is kept in memory either way.
+func (m rootCmp) saveHistoryCmd() tea.Cmd {
+	h := m.history
+	return func() tea.Msg {
+		_ = h.Save()
+		return nil
+	}
+}
+
+// loadModelsCmd fetches the provider's models for command completion.
+func (m rootCmp) loadModelsCmd() tea.Cmd {
+	if m.llmManager == nil {
+		// Without a provider there are no models to wait for
+		return func() tea.Msg { return ModelsLoadedMsg{} }
+	}
+	manager := m.llmManager
+	return func() tea.Msg {
+		models, err := manager.ListModels(context.Background())
+		return ModelsLoadedMsg{Models: models, Err: err}
+	}
 }
 
 func (m rootCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -55,21 +223,42 @@ func (m rootCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.BackgroundColorMsg:
+		// The auto theme follows the terminal background
+		if t, err := theme.Load(*m.config, msg.IsDark()); err == nil {
+			m.styles = newStyles(t)
+			m.layerManager.SetStyles(m.styles.Layout)
+			changed := ThemeChangedMsg{Theme: t}
+			m.focusManager, cmd = m.focusManager.UpdateAll(changed)
+			cmds = append(cmds, cmd, m.layerManager.UpdateAll(changed))
+		}
+
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		m.layerManager.SetSize(msg.Width, msg.Height)
-		m.focusManager, cmd = m.focusManager.UpdateAll(layout.ComponentSizeMsg{
-			Width:  msg.Width,
-			Height: msg.Height - 1, // Account for status bar
-		})
-		cmds = append(cmds, cmd)
-		newStatusBar, cmd := m.statusbar.Update(layout.ComponentSizeMsg{Width: msg.Width})
-		m.statusbar = newStatusBar.(components.Component)
+		m, cmd = m.resize()
 		cmds = append(cmds, cmd)
 
 	case layout.FocusChangedMsg:
 		return m, m.getHelpCmd()
 
+	case inputResizedMsg:
+		return m.resize()
+
+	case focusPaneMsg:
+		// Focus skips a hidden sidebar; the main view is shown whenever a
+		// pane below it asks
+		id := msg.ID
+		if !m.visible(id) {
+			id = paneMain
+		}
+		m.focusManager, cmd, _ = m.focusManager.Focus(id)
+		cmds = append(cmds, cmd)
+
+	case sidebarWeightsMsg:
+		m.layout.SidebarWeights = msg.Weights
+		cmds = append(cmds, m.saveLayoutCmd())
+
 	case layout.OpenLayerMsg:
 		cmd = m.layerManager.Push(msg.Layer)
 		cmds = append(cmds, cmd, m.getHelpCmd())
@@ -83,28 +272,161 @@ func (m rootCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd, m.getHelpCmd())
 
 	case layout.ShowConfirmDialogMsg:
-		dialog := layout.NewConfirmDialog(msg.Text)
+		dialog := layout.NewConfirmDialog(msg.ID, msg.Text).WithKeys(m.keyMaps.Confirm).WithStyles(m.styles.Layout)
 		cmd = m.layerManager.Push(dialog)
 		cmds = append(cmds, cmd, m.getHelpCmd())
 
 	case layout.ShowPromptDialogMsg:
-		dialog := layout.NewPromptDialog("")
+		if m.inline() {
+			// The docked input replaces the dialog
+			if msg.Text != "" {
+				m = m.setInputText(msg.Text)
+			}
+			m.focusManager, cmd, _ = m.focusManager.Focus(paneInput)
+			cmds = append(cmds, cmd)
+			break
+		}
+		text := msg.Text
+		if text == "" {
+			text = m.history.Draft()
+		}
+		dialog := layout.NewPromptDialog(text, layout.PromptOptions{
+			Commands: m.commands,
+			History:  m.history.Prompts(),
+			Keys:     &m.keyMaps.Prompt,
+			Styles:   &m.styles.Layout,
+		})
+		cmd = m.layerManager.Push(dialog)
+		cmds = append(cmds, cmd, m.getHelpCmd())
+
+	case layout.ShowSelectDialogMsg:
+		dialog := layout.NewSelectDialog(msg.ID, msg.Title, msg.Options).WithKeys(m.keyMaps.SelectDialog).WithStyles(m.styles.Layout)
 		cmd = m.layerManager.Push(dialog)
 		cmds = append(cmds, cmd, m.getHelpCmd())
 
+	case layout.SelectedMsg:
+		// Dismiss the select layer and forward the choice to the main view, which asked for it
+		cmd = m.layerManager.Pop()
+		cmds = append(cmds, cmd, m.getHelpCmd())
+		m, cmd = m.updateMain(msg)
+		cmds = append(cmds, cmd)
+
+	case layout.ShowFormDialogMsg:
+		dialog := layout.NewFormDialog(msg.ID, msg.Title, msg.Fields).WithKeys(m.keyMaps.Form).WithStyles(m.styles.Layout)
+		cmd = m.layerManager.Push(dialog)
+		cmds = append(cmds, cmd, dialog.Init(), m.getHelpCmd())
+
+	case layout.FormSubmittedMsg:
+		cmd = m.layerManager.Pop()
+		cmds = append(cmds, cmd, m.getHelpCmd())
+		m, cmd = m.updatePane(dialogTarget(msg.ID), msg)
+		cmds = append(cmds, cmd)
+
+	case layout.CommandMsg:
+		// Close the prompt and dispatch the command's message as a new event.
+		// Commands from the inline input have no layer to close.
+		if top := m.layerManager.Top(); top != nil && top.LayerMeta().CaptureKeys {
+			cmd = m.layerManager.Pop()
+		}
+		result := msg.Msg
+		cmds = append(cmds, cmd, m.getHelpCmd(), func() tea.Msg { return result })
+
+	case ModelsLoadedMsg:
+		if msg.Err == nil {
+			m.models.models = msg.Models
+		}
+		m, cmd = m.updatePane(paneSidebar, msg)
+		cmds = append(cmds, cmd)
+
+	case layout.ShowPaletteMsg:
+		palette := layout.NewCommandPalette(msg.Items).WithKeys(m.keyMaps.Palette).WithStyles(m.styles.Layout)
+		cmd = m.layerManager.Push(palette)
+		cmds = append(cmds, cmd, palette.Init(), m.getHelpCmd())
+
+	case layout.PaletteItemsMsg:
+		// Dropped when the palette was closed in the meantime
+		if top := m.layerManager.Top(); top != nil && top.LayerMeta().ID == "palette" {
+			m.layerManager, cmd, _ = m.layerManager.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case runKeyMsg:
+		if msg.Target != "" && !m.visible(msg.Target) {
+			// Running an action of a hidden pane shows it again
+			m.layout.Maximized, m.layout.SidebarCollapsed = "", false
+			m, cmd = m.layoutChanged()
+			cmds = append(cmds, cmd)
+		}
+		if msg.Target != "" {
+			m.focusManager, cmd, _ = m.focusManager.Focus(msg.Target)
+			cmds = append(cmds, cmd)
+		}
+		m, cmd = m.handleKeyPress(msg.Key)
+		cmds = append(cmds, cmd)
+
+	case ShowSearchMsg:
+		if m.store == nil {
+			break
+		}
+		search := NewSearchCmp(m.store, msg.Query)
+		search.keys, search.styles = m.keyMaps.GlobalSearch, m.styles
+		cmd = m.layerManager.Push(search)
+		cmds = append(cmds, cmd, search.Init(), m.getHelpCmd())
+
+	case ShowCompareMsg:
+		compare := NewCompareCmp(m.llmManager, msg)
+		compare.keys, compare.styles = m.keyMaps.Compare, m.styles
+		cmd = m.layerManager.Push(compare)
+		cmds = append(cmds, cmd, compare.Init(), m.getHelpCmd())
+
+	case ComparePickedMsg:
+		cmd = m.layerManager.PopByID("compare")
+		cmds = append(cmds, cmd, m.getHelpCmd())
+		m, cmd = m.updateMain(msg)
+		cmds = append(cmds, cmd)
+
+	case SetModelMsg, SetPersonaMsg, SetSystemPromptMsg, NewConversationMsg,
+		AttachFileMsg, RetryMsg, OpenTemplateMsg, CompactMsg, OpenConversationMsg,
+		CompareMsg, ExportMsg, ExportedMsg, ChatChunkMsg, ChatResponseMsg, CompactedMsg, ConversationTitledMsg, CopiedMsg:
+		// Command and request results always target the main view, whichever pane has focus
+		m, cmd = m.updateMain(msg)
+		cmds = append(cmds, cmd)
+
+	case ConversationSavedMsg, ConversationUpdatedMsg, ConversationDeletedMsg:
+		// The main view and the Conversations pane both show stored conversations
+		m, cmd = m.updateMain(msg)
+		cmds = append(cmds, cmd)
+		m, cmd = m.updatePane(paneSidebar, msg)
+		cmds = append(cmds, cmd)
+
+	case conversationsLoadedMsg:
+		m, cmd = m.updatePane(paneSidebar, msg)
+		cmds = append(cmds, cmd)
+
 	case layout.ConfirmedMsg:
 		cmd = m.layerManager.Pop()
 		cmds = append(cmds, cmd, m.getHelpCmd())
+		m, cmd = m.updatePane(dialogTarget(msg.ID), msg)
+		cmds = append(cmds, cmd)
 
 	case layout.CancelledMsg:
 		cmd = m.layerManager.Pop()
 		cmds = append(cmds, cmd, m.getHelpCmd())
 
 	case layout.PromptSubmittedMsg:
-		// Dismiss the prompt layer and forward the message to the focused component (main view)
-		cmd = m.layerManager.Pop()
-		cmds = append(cmds, cmd, m.getHelpCmd())
-		m.focusManager, cmd = m.focusManager.UpdateFocused(msg)
+		// Dismiss the prompt layer, if any, and forward the message to the main view
+		cmd = m.layerManager.PopByID("prompt")
+		m.history.Add(msg.Text)
+		cmds = append(cmds, cmd, m.getHelpCmd(), m.saveHistoryCmd())
+		m, cmd = m.updateMain(msg)
+		cmds = append(cmds, cmd)
+
+	case layout.PromptCancelledMsg:
+		// Keep the unsent text so the next prompt starts from it
+		cmd = m.layerManager.PopByID("prompt")
+		m.history.SetDraft(msg.Draft)
+		cmds = append(cmds, cmd, m.getHelpCmd(), m.saveHistoryCmd())
+		m, cmd = m.updateMain(msg)
 		cmds = append(cmds, cmd)
 
 	case tea.KeyPressMsg:
@@ -121,6 +443,19 @@ func (m rootCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tea.MouseMsg:
+		// Layers are hit-tested first; a click outside one may dismiss it
+		var handled bool
+		m.layerManager, cmd, handled = m.layerManager.Update(msg)
+		if handled {
+			cmds = append(cmds, cmd, m.getHelpCmd())
+		} else if m, cmd, handled = m.dragDivider(msg); handled {
+			cmds = append(cmds, cmd)
+		} else {
+			m, cmd = m.updateMouse(msg)
+			cmds = append(cmds, cmd)
+		}
+
 	// NOTE: Unhandled types get passed to layers first, then focused component
 	default:
 		// Try layers first
@@ -145,21 +480,30 @@ func (m rootCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m rootCmp) View() string {
-	sidebar, err := m.focusManager.Get(0)
+	sidebar, err := m.focusManager.Get(paneSidebar)
 	if err != nil {
 		return "Error retrieving sidebar: " + err.Error()
 	}
-	main, err := m.focusManager.Get(1)
+	main, err := m.focusManager.Get(paneMain)
 	if err != nil {
 		return "Error retrieving main view: " + err.Error()
 	}
 
-	// First row: sidebar + main
-	top := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		sidebar.View(),
-		main.View(),
-	)
+	// First row: sidebar + main, leaving out hidden panes
+	var columns []string
+	sidebarWidth, mainWidth := m.paneWidths()
+	if sidebarWidth > 0 {
+		columns = append(columns, sidebar.View())
+	}
+	if mainWidth > 0 {
+		// The inline prompt is docked below the main view
+		column := main.View()
+		if input, err := m.focusManager.Get(paneInput); err == nil {
+			column = lipgloss.JoinVertical(lipgloss.Left, column, input.View())
+		}
+		columns = append(columns, column)
+	}
+	top := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
 
 	// Second row: status bar. Force a single-line status regardless of content above.
 	status := lipgloss.NewStyle().Width(m.width).MaxWidth(m.width).Height(1).MaxHeight(1).Render(m.statusbar.View())
@@ -173,19 +517,319 @@ func (m rootCmp) View() string {
 	return m.layerManager.RenderOver(base)
 }
 
-func (m rootCmp) handleKeyPress(msg tea.KeyPressMsg) (rootCmp, tea.Cmd) {
-	var cmd tea.Cmd
+// dialogTarget returns the pane that opened the dialog with id.
+func dialogTarget(id string) string {
+	if strings.HasPrefix(id, sidebarDialogPrefix) {
+		return paneSidebar
+	}
+	return paneMain
+}
+
+// updateMain forwards msg to the main view regardless of focus.
+func (m rootCmp) updateMain(msg tea.Msg) (rootCmp, tea.Cmd) {
+	return m.updatePane(paneMain, msg)
+}
+
+// updatePane forwards msg to the pane with id regardless of focus.
+func (m rootCmp) updatePane(id string, msg tea.Msg) (rootCmp, tea.Cmd) {
+	pane, err := m.focusManager.Get(id)
+	if err != nil {
+		return m, nil
+	}
+	updated, cmd := pane.Update(msg)
+	if focusable, ok := updated.(layout.Focusable); ok {
+		m.focusManager, _ = m.focusManager.Set(id, focusable)
+	}
+	return m, cmd
+}
+
+// quit exits the program, keeping unsent inline text as the next draft.
+func (m rootCmp) quit() (rootCmp, tea.Cmd) {
+	if pane, err := m.focusManager.Get(paneInput); err == nil {
+		if input, ok := pane.(InputCmp); ok {
+			m.history.SetDraft(input.Value())
+			_ = m.history.Save()
+		}
+	}
+	return m, tea.Quit
+}
+
+// inline reports whether the prompt is docked below the main view.
+func (m rootCmp) inline() bool {
+	_, err := m.focusManager.Get(paneInput)
+	return err == nil
+}
+
+// inputHeight is the number of rows taken by the inline prompt.
+func (m rootCmp) inputHeight() int {
+	pane, err := m.focusManager.Get(paneInput)
+	if err != nil {
+		return 0
+	}
+	input, ok := pane.(InputCmp)
+	if !ok {
+		return 0
+	}
+	// Always leave the main view a few rows
+	return min(input.Height(), max(0, m.height-1-minMainHeight))
+}
+
+// setInputText replaces the text of the inline prompt.
+func (m rootCmp) setInputText(text string) rootCmp {
+	pane, err := m.focusManager.Get(paneInput)
+	if err != nil {
+		return m
+	}
+	if input, ok := pane.(InputCmp); ok {
+		m.focusManager, _ = m.focusManager.Set(paneInput, input.SetValue(text))
+	}
+	return m
+}
+
+// resize distributes the window between the panes, giving the inline
+// prompt the rows it asks for. A focused pane that was hidden gives its
+// focus to one that is shown.
+func (m rootCmp) resize() (rootCmp, tea.Cmd) {
+	sidebarWidth, _ := m.paneWidths()
+	tree := layout.Column(layout.Flex(1),
+		layout.Row(layout.Flex(1),
+			layout.Item(paneSidebar, layout.Fixed(sidebarWidth)),
+			layout.Column(layout.Flex(1),
+				layout.Item(paneMain, layout.Flex(1)),
+				layout.Item(paneInput, layout.Fixed(m.inputHeight())),
+			),
+		),
+		layout.Item(paneStatus, layout.Fixed(1)),
+	)
+	rects, cmd := tree.Apply(layout.Rect{Width: m.width, Height: m.height}, func(id string, msg layout.ComponentSizeMsg) tea.Cmd {
+		var cmd tea.Cmd
+		if id == paneStatus {
+			var statusbar tea.Model
+			statusbar, cmd = m.statusbar.Update(msg)
+			m.statusbar = statusbar.(components.Component)
+			return cmd
+		}
+		m, cmd = m.updatePane(id, msg)
+		return cmd
+	})
+	cmds := []tea.Cmd{cmd}
+
+	// Hidden panes get empty bounds, which directional focus skips
+	for _, id := range m.focusManager.IDs() {
+		m.focusManager = m.focusManager.SetBounds(id, rects[id])
+	}
+	if focused := m.focusManager.FocusedID(); focused != "" && !m.visible(focused) {
+		visible := paneMain
+		if !m.visible(paneMain) {
+			visible = paneSidebar
+		}
+		m.focusManager, cmd, _ = m.focusManager.Focus(visible)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
 
+// paneWidths splits the window between the sidebar and the main view. A
+// width of zero hides the pane.
+func (m rootCmp) paneWidths() (sidebar, main int) {
 	switch {
-	case key.Matches(msg, m.keys.Quit):
-		return m, tea.Quit
-	case key.Matches(msg, m.keys.FocusNext):
+	case m.layout.Maximized == panes.Sidebar:
+		return m.width, 0
+	case m.layout.Maximized == panes.Main, m.layout.SidebarCollapsed,
+		m.width < minSidebarWidth+minMainWidth:
+		return 0, m.width
+	}
+	sidebarSize := layout.Percent(25)
+	if m.layout.SidebarWidth > 0 {
+		sidebarSize = layout.Fixed(m.layout.SidebarWidth)
+	}
+	widths := layout.Split(m.width, sidebarSize.Min(minSidebarWidth).Max(m.width-minMainWidth), layout.Flex(1))
+	return widths[0], widths[1]
+}
+
+// visible reports whether the pane with id is shown.
+func (m rootCmp) visible(id string) bool {
+	sidebar, main := m.paneWidths()
+	if id == paneSidebar {
+		return sidebar > 0
+	}
+	return main > 0
+}
+
+// focusNext moves the focus to the next pane that is shown.
+func (m rootCmp) focusNext() (rootCmp, tea.Cmd) {
+	var cmd tea.Cmd
+	for range m.focusManager.IDs() {
 		m.focusManager, cmd = m.focusManager.FocusNext()
-		return m, cmd
+		if focused := m.focusManager.FocusedID(); focused == "" || m.visible(focused) {
+			break
+		}
+	}
+	return m, cmd
+}
+
+// toggleSidebar collapses the sidebar, or shows it when it is hidden.
+func (m rootCmp) toggleSidebar() (rootCmp, tea.Cmd) {
+	sidebar, _ := m.paneWidths()
+	m.layout.SidebarCollapsed = sidebar > 0
+	m.layout.Maximized = ""
+	return m.layoutChanged()
+}
+
+// toggleMaximize lets the focused pane take the whole window, or restores
+// the split.
+func (m rootCmp) toggleMaximize() (rootCmp, tea.Cmd) {
+	if m.layout.Maximized != "" {
+		m.layout.Maximized = ""
+		return m.layoutChanged()
+	}
+	m.layout.Maximized = panes.Main
+	if m.focusManager.FocusedID() == paneSidebar {
+		m.layout.Maximized = panes.Sidebar
+	}
+	return m.layoutChanged()
+}
+
+// resizeSidebar sets the width of the sidebar, within the bounds of the
+// window.
+func (m rootCmp) resizeSidebar(width int) (rootCmp, tea.Cmd) {
+	if sidebar, _ := m.paneWidths(); sidebar == 0 {
+		return m, nil
+	}
+	m.layout.SidebarWidth = max(minSidebarWidth, min(width, m.width-minMainWidth))
+	return m.resize()
+}
+
+// layoutChanged resizes the panes to the layout and saves it.
+func (m rootCmp) layoutChanged() (rootCmp, tea.Cmd) {
+	var cmd tea.Cmd
+	m, cmd = m.resize()
+	return m, tea.Batch(cmd, m.saveLayoutCmd(), m.getHelpCmd())
+}
+
+// updateMouse gives clicks and the wheel to the pane under the pointer,
+// relative to its top left corner; a click also focuses it. Other mouse
+// events go to the focused pane.
+func (m rootCmp) updateMouse(msg tea.MouseMsg) (rootCmp, tea.Cmd) {
+	switch msg.(type) {
+	case tea.MouseClickMsg, tea.MouseWheelMsg:
 	default:
+		var cmd tea.Cmd
 		m.focusManager, cmd = m.focusManager.UpdateFocused(msg)
 		return m, cmd
 	}
+
+	sidebar, _ := m.paneWidths()
+	mouse := msg.Mouse()
+	id, dx, dy := paneSidebar, 0, 0
+	if mouse.X >= sidebar {
+		id, dx = paneMain, sidebar
+		if mainHeight := m.height - 1 - m.inputHeight(); m.inline() && mouse.Y >= mainHeight {
+			id, dy = paneInput, mainHeight
+		}
+	}
+
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+	if _, click := msg.(tea.MouseClickMsg); click {
+		m.focusManager, cmd, _ = m.focusManager.Focus(id)
+		cmds = append(cmds, cmd)
+	}
+	m, cmd = m.updatePane(id, layout.TranslateMouse(msg, -dx, -dy))
+	return m, tea.Batch(append(cmds, cmd)...)
+}
+
+// dragDivider moves the divider between the sidebar and the main view with
+// the mouse. A click on either border next to it starts dragging; the
+// layout is saved on release.
+func (m rootCmp) dragDivider(msg tea.MouseMsg) (rootCmp, tea.Cmd, bool) {
+	sidebar, main := m.paneWidths()
+	mouse := msg.Mouse()
+	switch msg.(type) {
+	case tea.MouseClickMsg:
+		onDivider := mouse.X == sidebar-1 || mouse.X == sidebar
+		if sidebar > 0 && main > 0 && mouse.Button == tea.MouseLeft && onDivider {
+			m.dragging = true
+			return m, nil, true
+		}
+	case tea.MouseMotionMsg:
+		if m.dragging {
+			m, cmd := m.resizeSidebar(mouse.X + 1)
+			return m, cmd, true
+		}
+	case tea.MouseReleaseMsg:
+		if m.dragging {
+			m.dragging = false
+			return m, m.saveLayoutCmd(), true
+		}
+	}
+	return m, nil, false
+}
+
+func (m rootCmp) handleKeyPress(msg tea.KeyPressMsg) (rootCmp, tea.Cmd) {
+	var cmd tea.Cmd
+
+	// A pane capturing keys gets every key except quit
+	if focused, err := m.focusManager.GetFocused(); err == nil {
+		if capture, ok := focused.(keyCapture); ok && capture.CapturesKeys() {
+			if key.Matches(msg, m.keys.Quit) {
+				return m.quit()
+			}
+			m.focusManager, cmd = m.focusManager.UpdateFocused(msg)
+			return m, cmd
+		}
+	}
+
+	if key.Matches(msg, m.keys.Quit) {
+		return m.quit()
+	}
+
+	// Keys go to the focused pane first and bubble up to here when it
+	// leaves them
+	var handled bool
+	if m.focusManager, cmd, handled = m.focusManager.UpdateKey(msg); handled {
+		return m, cmd
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.FocusLeft):
+		return m.moveFocus(layout.Left)
+	case key.Matches(msg, m.keys.FocusDown):
+		return m.moveFocus(layout.Down)
+	case key.Matches(msg, m.keys.FocusUp):
+		return m.moveFocus(layout.Up)
+	case key.Matches(msg, m.keys.FocusRight):
+		return m.moveFocus(layout.Right)
+	case key.Matches(msg, m.keys.FocusNext):
+		return m.focusNext()
+	case key.Matches(msg, m.keys.ToggleSidebar):
+		return m.toggleSidebar()
+	case key.Matches(msg, m.keys.Maximize):
+		return m.toggleMaximize()
+	case key.Matches(msg, m.keys.SidebarNarrow), key.Matches(msg, m.keys.SidebarWiden):
+		sidebar, _ := m.paneWidths()
+		step := sidebarStep
+		if key.Matches(msg, m.keys.SidebarNarrow) {
+			step = -step
+		}
+		m, cmd = m.resizeSidebar(sidebar + step)
+		return m, tea.Batch(cmd, m.saveLayoutCmd())
+	case key.Matches(msg, m.keys.Palette):
+		items := m.paletteItems()
+		// The palette opens before the conversations are listed
+		return m, tea.Sequence(func() tea.Msg { return layout.ShowPaletteMsg{Items: items} }, m.paletteConversationsCmd())
+	case key.Matches(msg, m.keys.Search):
+		return m, func() tea.Msg { return ShowSearchMsg{} }
+	}
+	return m, nil
+}
+
+// moveFocus moves the focus to the nearest pane in dir, or within the
+// focused pane when it has panes of its own.
+func (m rootCmp) moveFocus(dir layout.Direction) (rootCmp, tea.Cmd) {
+	var cmd tea.Cmd
+	m.focusManager, cmd, _ = m.focusManager.Move(dir)
+	return m, cmd
 }
 
 func (m rootCmp) getHelpCmd() tea.Cmd {
@@ -206,7 +850,7 @@ func (m rootCmp) getHelpCmd() tea.Cmd {
 
 	// Add global key bindings (unless a modal layer is active)
 	if top := m.layerManager.Top(); top == nil || !top.LayerMeta().Modal {
-		bindings = append(bindings, m.keys.FocusNext, m.keys.Quit)
+		bindings = append(bindings, m.keys.FocusNext, m.keys.ToggleSidebar, m.keys.Maximize, m.keys.Palette, m.keys.Search, m.keys.Quit)
 	}
 
 	return func() tea.Msg {
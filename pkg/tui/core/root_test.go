@@ -0,0 +1,304 @@
+package core
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/llm/providers/fake"
+	"github.com/darling/mana/pkg/panes"
+	"github.com/darling/mana/pkg/store"
+	"github.com/darling/mana/pkg/tui/core/layout"
+	"github.com/darling/mana/pkg/tui/core/theme"
+	"github.com/darling/mana/pkg/tui/tuitest"
+)
+
+func TestPaneHeights(t *testing.T) {
+	tests := []struct {
+		height  int
+		weights []int
+		want    []int
+	}{
+		{30, []int{1, 1, 1}, []int{10, 10, 10}},
+		{31, []int{1, 1, 1}, []int{11, 10, 10}},
+		{30, []int{4, 1, 1}, []int{20, 5, 5}},
+		{10, []int{2, 1, 1}, []int{6, 2, 2}},
+	}
+	for _, tt := range tests {
+		if got := paneHeights(tt.height, tt.weights); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paneHeights(%d, %v) = %v, want %v", tt.height, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestRootCmp_Layout(t *testing.T) {
+	t.Setenv("MANA_CONFIG_DIR", t.TempDir())
+	newRoot := func(l panes.Layout, width int) rootCmp {
+		m := NewRootCmp(Options{Layout: &l}).(rootCmp)
+		model, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 30})
+		return model.(rootCmp)
+	}
+	key := func(m rootCmp, k tea.KeyPressMsg) rootCmp {
+		model, _ := m.Update(k)
+		return model.(rootCmp)
+	}
+	widths := func(m rootCmp) [2]int {
+		sidebar, main := m.paneWidths()
+		return [2]int{sidebar, main}
+	}
+
+	tests := []struct {
+		name string
+		root rootCmp
+		want [2]int
+	}{
+		{"default", newRoot(panes.Layout{}, 100), [2]int{25, 75}},
+		{"saved width", newRoot(panes.Layout{SidebarWidth: 30}, 100), [2]int{30, 70}},
+		{"width clamped", newRoot(panes.Layout{SidebarWidth: 90}, 100), [2]int{60, 40}},
+		{"narrow window", newRoot(panes.Layout{}, 50), [2]int{0, 50}},
+		{"collapsed", newRoot(panes.Layout{SidebarCollapsed: true}, 100), [2]int{0, 100}},
+		{"maximized sidebar", newRoot(panes.Layout{Maximized: panes.Sidebar}, 100), [2]int{100, 0}},
+		{"toggled", key(newRoot(panes.Layout{}, 100), tea.KeyPressMsg{Code: 'b', Mod: tea.ModCtrl}), [2]int{0, 100}},
+		{"widened", key(newRoot(panes.Layout{}, 100), tea.KeyPressMsg{Code: tea.KeyRight, Mod: tea.ModCtrl}), [2]int{27, 73}},
+		{"maximized", key(newRoot(panes.Layout{}, 100), tea.KeyPressMsg{Code: 'o', Mod: tea.ModCtrl}), [2]int{0, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := widths(tt.root); got != tt.want {
+				t.Errorf("pane widths = %v, want %v", got, tt.want)
+			}
+			if focused := tt.root.focusManager.FocusedID(); !tt.root.visible(focused) {
+				t.Errorf("hidden pane %q is focused", focused)
+			}
+		})
+	}
+}
+
+func TestRootCmp_DragDivider(t *testing.T) {
+	t.Setenv("MANA_CONFIG_DIR", t.TempDir())
+	l := panes.Layout{}
+	m := NewRootCmp(Options{Layout: &l}).(rootCmp)
+	for _, msg := range []tea.Msg{
+		tea.WindowSizeMsg{Width: 100, Height: 30},
+		tea.MouseClickMsg{X: 25, Y: 5, Button: tea.MouseLeft},
+		tea.MouseMotionMsg{X: 34, Y: 5, Button: tea.MouseLeft},
+		tea.MouseReleaseMsg{X: 34, Y: 5, Button: tea.MouseLeft},
+		tea.MouseMotionMsg{X: 50, Y: 5},
+	} {
+		model, _ := m.Update(msg)
+		m = model.(rootCmp)
+	}
+	if sidebar, _ := m.paneWidths(); sidebar != 35 || l.SidebarWidth != 35 {
+		t.Errorf("sidebar width = %d (saved %d) after dragging, want 35", sidebar, l.SidebarWidth)
+	}
+}
+
+func TestRootCmp_MoveFocus(t *testing.T) {
+	t.Setenv("MANA_CONFIG_DIR", t.TempDir())
+	l := panes.Layout{}
+	m := NewRootCmp(Options{Layout: &l}).(rootCmp)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = model.(rootCmp)
+
+	steps := []struct {
+		key  rune
+		want string
+	}{
+		{'h', "sidebar/conversations"},
+		{'j', "sidebar/models"},
+		{'j', "sidebar/settings"},
+		{'j', "sidebar/settings"},
+		{'k', "sidebar/models"},
+		{'l', "main"},
+		{'l', "main"},
+		{'h', "sidebar/models"},
+	}
+	for i, step := range steps {
+		model, _ = m.Update(tea.KeyPressMsg{Code: step.key, Mod: tea.ModAlt})
+		m = model.(rootCmp)
+		if got := m.focusManager.FocusedPath(); got != step.want {
+			t.Fatalf("step %d: alt+%c focused %q, want %q", i, step.key, got, step.want)
+		}
+	}
+}
+
+// newTestHarness drives a root with opts and otherwise default settings,
+// kept away from the user's config directory.
+func newTestHarness(t *testing.T, opts Options) *tuitest.Harness {
+	t.Helper()
+	t.Setenv("MANA_CONFIG_DIR", t.TempDir())
+	if opts.Layout == nil {
+		opts.Layout = &panes.Layout{}
+	}
+	return tuitest.New(t, NewRootCmp(opts), 80, 20)
+}
+
+func focusedPath(h *tuitest.Harness) string {
+	return h.Model().(rootCmp).focusManager.FocusedPath()
+}
+
+func TestRootCmp_FocusCycle(t *testing.T) {
+	h := newTestHarness(t, Options{}).Snapshot("root_main")
+	steps := []struct {
+		want, golden string
+	}{
+		{"sidebar/conversations", "root_sidebar"},
+		{"main", "root_main"},
+	}
+	for _, step := range steps {
+		h.Press("tab")
+		if got := focusedPath(h); got != step.want {
+			t.Fatalf("tab focused %q, want %q", got, step.want)
+		}
+		// The help line follows the focus
+		h.Snapshot(step.golden)
+	}
+}
+
+// A confirmed search keeps n, N and esc but lets global keys through.
+func TestRootCmp_ConfirmedSearch(t *testing.T) {
+	h := newTestHarness(t, Options{})
+	h.Press("/").Type("x").Press("enter", "tab")
+	if got := focusedPath(h); got != "sidebar/conversations" {
+		t.Fatalf("tab after a search focused %q, want the sidebar", got)
+	}
+	h.Press("tab", "esc")
+	if m, _ := h.Model().(rootCmp).focusManager.Get(paneMain); m.(MainCmp).search != "" {
+		t.Error("esc did not clear the search")
+	}
+}
+
+func TestRootCmp_Layers(t *testing.T) {
+	h := newTestHarness(t, Options{})
+	h.Press("ctrl+p").Snapshot("root_palette")
+	if !h.Model().(rootCmp).layerManager.HasLayers() {
+		t.Fatal("ctrl+p opened no layer")
+	}
+	h.Press("esc").Snapshot("root_main")
+	if h.Model().(rootCmp).layerManager.HasLayers() {
+		t.Error("esc left the palette open")
+	}
+}
+
+func TestRootCmp_PaletteConversations(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := store.NewConversation()
+	c.Title = "Readable talk"
+	if err := st.Save(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHarness(t, Options{Store: st})
+	// The palette opens first, then the conversations fill in
+	var opened, added bool
+	for _, msg := range h.Press("ctrl+p").Msgs() {
+		switch msg := msg.(type) {
+		case layout.ShowPaletteMsg:
+			opened = true
+		case layout.PaletteItemsMsg:
+			added = opened && len(msg.Items) == 1 && msg.Items[0].Title == "Readable talk"
+		}
+	}
+	if !added {
+		t.Fatal("the palette was not given the readable conversation after opening")
+	}
+	if view := h.Type("readable").View(); !strings.Contains(view, "Readable talk") {
+		t.Errorf("palette does not offer the conversation:\n%s", view)
+	}
+}
+
+func TestRootCmp_BackgroundColor(t *testing.T) {
+	h := newTestHarness(t, Options{})
+	h.Send(tea.BackgroundColorMsg{Color: color.White})
+
+	root := h.Model().(rootCmp)
+	main, _ := root.focusManager.Get(paneMain)
+	sidebar, _ := root.focusManager.Get(paneSidebar)
+	conversations, _ := sidebar.(SidebarCmp).focusManager.Get(paneConversations)
+	for name, s := range map[string]styles{
+		"root":          root.styles,
+		"main":          main.(MainCmp).styles,
+		"conversations": conversations.(ConversationsCmp).styles,
+	} {
+		if s.Markdown != theme.Light.Markdown {
+			t.Errorf("%s draws with markdown style %q on a light background", name, s.Markdown)
+		}
+	}
+
+	// Styles belong to the model; another one still starts dark
+	other := newTestHarness(t, Options{}).Model().(rootCmp)
+	if other.styles.Markdown != theme.Dark.Markdown {
+		t.Errorf("a new model starts with markdown style %q", other.styles.Markdown)
+	}
+}
+
+func TestRootCmp_PromptSubmit(t *testing.T) {
+	h := newTestHarness(t, Options{})
+	h.Press("c").Type("hello").Snapshot("root_prompt")
+	h.Press("enter").Snapshot("root_submitted")
+
+	var submitted bool
+	for _, msg := range h.Msgs() {
+		if msg, ok := msg.(layout.PromptSubmittedMsg); ok && msg.Text == "hello" {
+			submitted = true
+		}
+	}
+	if !submitted {
+		t.Error("enter did not submit the prompt")
+	}
+	if got := h.Model().(rootCmp).history.Prompts(); len(got) != 1 || got[0] != "hello" {
+		t.Errorf("history = %q, want the submitted prompt", got)
+	}
+}
+
+func TestRootCmp_FakeProvider(t *testing.T) {
+	t.Setenv(fake.FixtureEnv, "testdata/fake.json")
+	manager, err := llm.NewManager("fake", llm.Config{Model: "fake/test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHarness(t, Options{Manager: manager})
+
+	h.Press("c").Type("hello").Press("enter").Snapshot("root_answered")
+	var chunks []string
+	for _, msg := range h.Msgs() {
+		if chunk, ok := msg.(ChatChunkMsg); ok {
+			chunks = append(chunks, chunk.Chunk)
+		}
+	}
+	if want := []string{"Hi! ", "How can I help?"}; !reflect.DeepEqual(chunks, want) {
+		t.Errorf("streamed %q, want %q", chunks, want)
+	}
+	h.Press("c").Type("busy?").Press("enter").Snapshot("root_rate_limited")
+}
+
+// An answer arriving after another conversation was started is dropped.
+func TestRootCmp_StaleResponse(t *testing.T) {
+	h := newTestHarness(t, Options{})
+	main := func() MainCmp {
+		m, _ := h.Model().(rootCmp).focusManager.Get(paneMain)
+		return m.(MainCmp)
+	}
+	old := main().conversation.ID
+	h.Send(NewConversationMsg{})
+	if main().conversation.ID == old {
+		t.Fatal("NewConversationMsg kept the conversation")
+	}
+	h.Send(ChatResponseMsg{ConversationID: old, Message: llm.Message{Content: "late answer"}})
+	if n := len(main().tree.Nodes); n != 0 {
+		t.Errorf("the stale answer was added: %d messages", n)
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// searchMatch is an occurrence of the search query in the rendered
+// transcript, in terminal cells.
+type searchMatch struct {
+	Line       int
+	Start, End int
+}
+
+// findMatches finds the query, ignoring case, in every line of rendered
+// output.
+func findMatches(rendered, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	var matches []searchMatch
+	for i, line := range strings.Split(rendered, "\n") {
+		plain := ansi.Strip(line)
+		for _, loc := range pattern.FindAllStringIndex(plain, -1) {
+			start := ansi.StringWidth(plain[:loc[0]])
+			matches = append(matches, searchMatch{Line: i, Start: start, End: start + ansi.StringWidth(plain[loc[0]:loc[1]])})
+		}
+	}
+	return matches
+}
+
+// highlightMatches styles the matches in rendered output with s, the
+// current one standing out from the others.
+func highlightMatches(s styles, rendered string, matches []searchMatch, current int) string {
+	if len(matches) == 0 {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	ranges := make(map[int][]lipgloss.Range)
+	for i, m := range matches {
+		style := s.SearchMatch
+		if i == current {
+			style = s.CurrentSearchMatch
+		}
+		ranges[m.Line] = append(ranges[m.Line], lipgloss.NewRange(m.Start, m.End, style))
+	}
+	for line, r := range ranges {
+		lines[line] = lipgloss.StyleRanges(lines[line], r...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// startSearch begins typing a query; matches are shown as it is typed.
+func (m MainCmp) startSearch() (MainCmp, tea.Cmd) {
+	m.searching = true
+	m.search = ""
+	m.match = 0
+	m.searchOrigin = m.vp.YOffset
+	return m.refreshSearch(), nil
+}
+
+// updateSearch edits the query while it is being typed.
+func (m MainCmp) updateSearch(msg tea.KeyPressMsg) (MainCmp, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.searchKeys.Confirm):
+		m.searching = false
+		return m, nil
+	case key.Matches(msg, m.searchKeys.Clear):
+		return m.clearSearch(), nil
+	case msg.String() == "backspace":
+		if r := []rune(m.search); len(r) > 0 {
+			m.search = string(r[:len(r)-1])
+		}
+	case msg.Text != "":
+		m.search += msg.Text
+	default:
+		return m, nil
+	}
+	m.matches = findMatches(m.transcript, m.search)
+	// Jump to the first match below where the search started
+	m.match = 0
+	for i, match := range m.matches {
+		if match.Line >= m.searchOrigin {
+			m.match = i
+			break
+		}
+	}
+	return m.refreshSearch(), nil
+}
+
+// moveMatch jumps delta matches forward or back, wrapping around.
+func (m MainCmp) moveMatch(delta int) MainCmp {
+	if n := len(m.matches); n > 0 {
+		m.match = (m.match + delta + n) % n
+	}
+	return m.refreshSearch()
+}
+
+// clearSearch ends the search and removes the highlights.
+func (m MainCmp) clearSearch() MainCmp {
+	m.searching = false
+	m.search = ""
+	m.matches = nil
+	m.match = 0
+	m.vp.SetContent(m.transcript)
+	return m
+}
+
+// refreshSearch redraws the highlights and scrolls the current match into view.
+func (m MainCmp) refreshSearch() MainCmp {
+	m.vp.SetContent(highlightMatches(m.styles, m.transcript, m.matches, m.match))
+	if m.match < len(m.matches) {
+		m.vp.EnsureVisible(m.matches[m.match].Line, 0, 0)
+	}
+	return m
+}
+
+// searchStatus describes the search for the header.
+func (m MainCmp) searchStatus() string {
+	status := "/" + m.search
+	if m.searching {
+		status += "█"
+	}
+	if m.search == "" {
+		return status
+	}
+	if n := len(m.matches); n > 0 {
+		return status + fmt.Sprintf(" (%d/%d)", min(m.match+1, n), n)
+	}
+	return status + " (no matches)"
+}
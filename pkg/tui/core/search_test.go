@@ -0,0 +1,96 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/tui/core/layout"
+)
+
+func TestFindMatches(t *testing.T) {
+	rendered := "  \x1b[1mGo\x1b[0m is fun\nlet's go, GO!\nnothing"
+	want := []searchMatch{
+		{Line: 0, Start: 2, End: 4},
+		{Line: 1, Start: 6, End: 8},
+		{Line: 1, Start: 10, End: 12},
+	}
+	if got := findMatches(rendered, "go"); !reflect.DeepEqual(got, want) {
+		t.Errorf("findMatches() = %+v, want %+v", got, want)
+	}
+	if got := findMatches(rendered, ""); got != nil {
+		t.Errorf("findMatches(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	rendered := "one two\nthree"
+	out := highlightMatches(defaultStyles, rendered, findMatches(rendered, "t"), 1)
+	if got := ansi.Strip(out); got != rendered {
+		t.Errorf("highlighting changed the text: %q", got)
+	}
+}
+
+func TestMainCmp_Search(t *testing.T) {
+	m := NewMainCmp(nil, nil, nil, nil)
+	updated, _ := m.SetFocused(true)
+	m = updated.(MainCmp)
+	model, _ := m.Update(layout.ComponentSizeMsg{Width: 80, Height: 20})
+	m = model.(MainCmp)
+	m = m.setTree(llm.NewTree([]llm.Message{
+		{Role: "user", Content: "needle one"},
+		{Role: "assistant", Content: "haystack"},
+		{Role: "user", Content: "needle two"},
+	}, ""))
+
+	press := func(keys ...tea.KeyPressMsg) {
+		for _, k := range keys {
+			model, _ := m.Update(k)
+			m = model.(MainCmp)
+		}
+	}
+	press(tea.KeyPressMsg{Code: '/', Text: "/"})
+	if !m.searching || !m.CapturesKeys() {
+		t.Fatal("/ did not start a search")
+	}
+	for _, r := range "needle" {
+		press(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	press(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.searching || m.search != "needle" {
+		t.Fatalf("searching = %v, search = %q after enter", m.searching, m.search)
+	}
+	if n := len(m.matches); n != 2 {
+		t.Fatalf("got %d matches, want 2", n)
+	}
+	if m.CapturesKeys() {
+		t.Error("a confirmed search still captures every key")
+	}
+	for _, k := range []tea.KeyPressMsg{{Code: 'n', Text: "n"}, {Code: 'N', Text: "N"}, {Code: tea.KeyEscape}} {
+		if !m.HandlesKey(k) {
+			t.Errorf("a confirmed search does not handle %s", k)
+		}
+	}
+	if m.HandlesKey(tea.KeyPressMsg{Code: tea.KeyTab}) {
+		t.Error("a confirmed search handles tab instead of letting it bubble up")
+	}
+
+	// n moves to the next match instead of starting a new conversation
+	first := m.match
+	press(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if m.match == first || len(m.messages) != 3 {
+		t.Errorf("n: match = %d (was %d), %d messages", m.match, first, len(m.messages))
+	}
+	press(tea.KeyPressMsg{Code: 'N', Text: "N"})
+	if m.match != first {
+		t.Errorf("N: match = %d, want %d", m.match, first)
+	}
+
+	press(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.search != "" || m.CapturesKeys() {
+		t.Error("esc did not clear the search")
+	}
+}
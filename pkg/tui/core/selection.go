@@ -0,0 +1,272 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/darling/mana/pkg/markdown"
+)
+
+// codeBlock is a fenced code block in the transcript. Blocks are numbered
+// from 1 across the whole conversation so they can be yanked by number.
+type codeBlock struct {
+	markdown.CodeBlock
+	Message int
+}
+
+// selectTarget is something the selection cursor can rest on: a message,
+// or one of the code blocks inside it.
+type selectTarget struct {
+	Message int
+	Block   int // index into codeBlocks, or -1 for the whole message
+}
+
+// codeBlocks returns every code block in the visible messages in order.
+func (m MainCmp) codeBlocks() []codeBlock {
+	var blocks []codeBlock
+	for i, msg := range m.messages {
+		for _, b := range markdown.CodeBlocks(msg.Content) {
+			blocks = append(blocks, codeBlock{CodeBlock: b, Message: i})
+		}
+	}
+	return blocks
+}
+
+// selectionTargets lists the selectable items top to bottom: each message
+// followed by its code blocks.
+func (m MainCmp) selectionTargets() []selectTarget {
+	blocks := m.codeBlocks()
+	targets := make([]selectTarget, 0, len(m.messages)+len(blocks))
+	next := 0
+	for i := range m.messages {
+		targets = append(targets, selectTarget{Message: i, Block: -1})
+		for next < len(blocks) && blocks[next].Message == i {
+			targets = append(targets, selectTarget{Message: i, Block: next})
+			next++
+		}
+	}
+	return targets
+}
+
+// selectedTarget returns the target under the cursor.
+func (m MainCmp) selectedTarget() (selectTarget, bool) {
+	if !m.selecting {
+		return selectTarget{}, false
+	}
+	targets := m.selectionTargets()
+	if m.selection < 0 || m.selection >= len(targets) {
+		return selectTarget{}, false
+	}
+	return targets[m.selection], true
+}
+
+// startSelection enters selection mode on the last message.
+func (m MainCmp) startSelection() (MainCmp, tea.Cmd) {
+	if len(m.messages) == 0 {
+		return m, nil
+	}
+	m.selecting = true
+	targets := m.selectionTargets()
+	m.selection = len(targets) - 1
+	for m.selection > 0 && targets[m.selection].Block >= 0 {
+		m.selection--
+	}
+	return m.refreshSelection(), nil
+}
+
+// moveSelection moves the cursor by delta, clamped to the transcript.
+func (m MainCmp) moveSelection(delta int) MainCmp {
+	m.selection = min(max(0, m.selection+delta), len(m.selectionTargets())-1)
+	return m.refreshSelection()
+}
+
+// refreshSelection redraws the transcript and scrolls the cursor into view.
+func (m MainCmp) refreshSelection() MainCmp {
+	innerW, _ := m.innerDimensions()
+	m, offsets := m.redrawTargets(innerW)
+	if m.selecting && m.selection < len(offsets) {
+		m.vp.EnsureVisible(offsets[m.selection], 0, 0)
+	}
+	return m
+}
+
+func (m MainCmp) updateSelection(msg tea.KeyPressMsg) (MainCmp, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.selectKeys.Up):
+		return m.moveSelection(-1), nil
+	case key.Matches(msg, m.selectKeys.Down):
+		return m.moveSelection(1), nil
+	case key.Matches(msg, m.selectKeys.Exit):
+		m.selecting = false
+		return m.refreshSelection(), nil
+	case key.Matches(msg, m.selectKeys.Copy):
+		return m.copySelection(copyPlain)
+	case key.Matches(msg, m.selectKeys.CopyCode):
+		return m.copySelection(copyCode)
+	case key.Matches(msg, m.selectKeys.CopyRaw):
+		return m.copySelection(copyRaw)
+	case key.Matches(msg, m.selectKeys.Edit):
+		if target, ok := m.selectedTarget(); ok {
+			return m.edit(m.messages[target.Message].ID)
+		}
+	case key.Matches(msg, m.selectKeys.Compact):
+		// Summarize up to and including the selected message
+		if target, ok := m.selectedTarget(); ok {
+			m.selecting = false
+			m = m.refreshSelection()
+			return m.compact(len(m.messages) - target.Message - 1)
+		}
+	case key.Matches(msg, m.selectKeys.PrevBranch), key.Matches(msg, m.selectKeys.NextBranch):
+		target, ok := m.selectedTarget()
+		if !ok {
+			return m, nil
+		}
+		delta := 1
+		if key.Matches(msg, m.selectKeys.PrevBranch) {
+			delta = -1
+		}
+		// Keep the cursor on the message whose branch changed
+		var cmd tea.Cmd
+		m, cmd = m.switchBranch(m.messages[target.Message].ID, delta)
+		for i, t := range m.selectionTargets() {
+			if t.Message == target.Message && t.Block < 0 {
+				m.selection = i
+				break
+			}
+		}
+		return m.refreshSelection(), cmd
+	}
+	return m, nil
+}
+
+type copyMode int
+
+const (
+	copyPlain copyMode = iota // the message as displayed, or a block's code
+	copyCode                  // only code
+	copyRaw                   // markdown source
+)
+
+// copySelection copies the selected message or code block.
+func (m MainCmp) copySelection(mode copyMode) (MainCmp, tea.Cmd) {
+	target, ok := m.selectedTarget()
+	if !ok {
+		return m, nil
+	}
+	content := m.messages[target.Message].Content
+
+	if target.Block >= 0 {
+		b := m.codeBlocks()[target.Block]
+		what := fmt.Sprintf("code block %d", target.Block+1)
+		if mode == copyRaw {
+			return m, copyCmd(content[b.Start:b.End], what+" markdown")
+		}
+		return m, copyCmd(b.Code, what)
+	}
+
+	switch mode {
+	case copyCode:
+		var code []string
+		for _, b := range markdown.CodeBlocks(content) {
+			code = append(code, b.Code)
+		}
+		if len(code) == 0 {
+			m.err = errors.New("message has no code blocks")
+			return m, nil
+		}
+		return m, copyCmd(strings.Join(code, "\n"), "code")
+	case copyRaw:
+		return m, copyCmd(content, "message markdown")
+	}
+	innerW, _ := m.innerDimensions()
+	return m, copyCmd(plainText(m.renderContent(content, innerW)), "message")
+}
+
+// updateYank collects the number typed after the yank key. The block is
+// copied as soon as another digit could not make a valid number, or on enter.
+func (m MainCmp) updateYank(msg tea.KeyPressMsg) (MainCmp, tea.Cmd) {
+	switch s := msg.String(); {
+	case s == "enter":
+		return m.finishYank()
+	case s == "esc":
+		m.yanking, m.yank = false, ""
+		return m, nil
+	case s == "backspace":
+		if m.yank != "" {
+			m.yank = m.yank[:len(m.yank)-1]
+		}
+		return m, nil
+	case len(s) == 1 && s[0] >= '0' && s[0] <= '9':
+		m.yank += s
+		n, _ := strconv.Atoi(m.yank)
+		if n*10 > len(m.codeBlocks()) {
+			return m.finishYank()
+		}
+		return m, nil
+	}
+	// Any other key abandons the yank
+	m.yanking, m.yank = false, ""
+	return m, nil
+}
+
+func (m MainCmp) finishYank() (MainCmp, tea.Cmd) {
+	n, err := strconv.Atoi(m.yank)
+	m.yanking, m.yank = false, ""
+	if err != nil {
+		return m, nil
+	}
+	blocks := m.codeBlocks()
+	if n < 1 || n > len(blocks) {
+		m.err = fmt.Errorf("no code block %d", n)
+		return m, nil
+	}
+	m.err = nil
+	return m, copyCmd(blocks[n-1].Code, fmt.Sprintf("code block %d", n))
+}
+
+// labelCodeBlocks puts a numbered label above each code block of a message.
+// first is the number of the message's first block; selected is the
+// number of the selected block, or 0.
+func labelCodeBlocks(content string, first, selected int) string {
+	blocks := markdown.CodeBlocks(content)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		n := first + i
+		label := fmt.Sprintf("[%d]", n)
+		if b.Lang != "" {
+			label += " " + b.Lang
+		}
+		if n == selected {
+			label = "**▶ " + label + "**"
+		} else {
+			label = "*" + label + "*"
+		}
+		// Indent the label like the fence, so that a block nested in a list
+		// item stays in it
+		fence := content[b.Start:]
+		indent := fence[:len(fence)-len(strings.TrimLeft(fence, " "))]
+		content = content[:b.Start] + indent + label + "\n\n" + content[b.Start:]
+	}
+	return content
+}
+
+// blockLines finds the rows of the numbered labels in rendered output.
+func blockLines(rendered string, first, count int) []int {
+	lines := strings.Split(ansi.Strip(rendered), "\n")
+	rows := make([]int, 0, count)
+	row := 0
+	for n := first; n < first+count; n++ {
+		label := fmt.Sprintf("[%d]", n)
+		for row < len(lines) && !strings.Contains(lines[row], label) {
+			row++
+		}
+		rows = append(rows, min(row, max(0, len(lines)-1)))
+	}
+	return rows
+}
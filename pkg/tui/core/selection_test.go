@@ -0,0 +1,170 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/tui/tuitest"
+)
+
+func TestLabelCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "top level",
+			content: "intro\n```go\nx := 1\n```\n```\nplain\n```\n",
+			want:    "intro\n*[3] go*\n\n```go\nx := 1\n```\n**▶ [4]**\n\n```\nplain\n```\n",
+		},
+		{
+			name:    "in a list item",
+			content: "1. run\n   ```sh\n   ls\n   ```\n",
+			want:    "1. run\n   *[3] sh*\n\n   ```sh\n   ls\n   ```\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelCodeBlocks(tt.content, 3, 4); got != tt.want {
+				t.Errorf("labelCodeBlocks() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	rendered := "\n  \x1b[1mHello\x1b[0m   \n\n    indented\n  \n"
+	want := "Hello\n\n  indented"
+	if got := plainText(rendered); got != want {
+		t.Errorf("plainText() = %q, want %q", got, want)
+	}
+}
+
+// pressMain sends key presses named like key bindings to the main view and
+// returns the command of the last one.
+func pressMain(t *testing.T, m MainCmp, keys ...string) (MainCmp, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg, ok := tuitest.Key(k)
+		if !ok {
+			t.Fatalf("unknown key %q", k)
+		}
+		m, cmd = updateMain(t, m, msg)
+	}
+	return m, cmd
+}
+
+// clipboardText returns the text a copy command puts on the clipboard. Only
+// the OSC 52 part of the command runs, leaving the system clipboard alone.
+func clipboardText(t *testing.T, cmd tea.Cmd) string {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("nothing was copied")
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) == 0 {
+		t.Fatal("the command does not copy")
+	}
+	msg := reflect.ValueOf(batch[0]())
+	if msg.Kind() != reflect.String {
+		t.Fatalf("the command copies with %s", msg.Type())
+	}
+	return msg.String()
+}
+
+func TestMainCmp_CopySelection(t *testing.T) {
+	answer := "Here:\n```go\nx := 1\n```\nand\n```sh\nls\n```\n"
+	m := newMainTestCmp(t).setTree(llm.NewTree([]llm.Message{
+		{ID: "q", Role: "user", Content: "show me"},
+		{ID: "a", Role: "assistant", Content: answer},
+	}, ""))
+
+	tests := []struct {
+		name    string
+		keys    []string
+		want    string
+		wantErr string
+	}{
+		{name: "message", keys: []string{"y"}, want: "x := 1"},
+		{name: "code of a message", keys: []string{"c"}, want: "x := 1\n\nls\n"},
+		{name: "markdown of a message", keys: []string{"r"}, want: answer},
+		{name: "code block", keys: []string{"j", "j", "y"}, want: "ls\n"},
+		{name: "markdown of a code block", keys: []string{"j", "r"}, want: "```go\nx := 1\n```\n"},
+		{name: "no code", keys: []string{"k", "c"}, wantErr: "message has no code blocks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := pressMain(t, m, append([]string{"v"}, tt.keys...)...)
+			if tt.wantErr != "" {
+				if cmd != nil || m.err == nil || m.err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", m.err, tt.wantErr)
+				}
+				return
+			}
+			got := clipboardText(t, cmd)
+			if tt.name == "message" {
+				// The message is copied as displayed, without the fences
+				if !strings.Contains(got, "Here:") || !strings.Contains(got, tt.want) || strings.Contains(got, "```") {
+					t.Errorf("copied %q, want the rendered message", got)
+				}
+			} else if got != tt.want {
+				t.Errorf("copied %q, want %q", got, tt.want)
+			}
+			if !m.selecting {
+				t.Error("copying ended the selection")
+			}
+		})
+	}
+}
+
+func TestMainCmp_Yank(t *testing.T) {
+	var answer strings.Builder
+	for n := 1; n <= 12; n++ {
+		answer.WriteString("```\n" + strings.Repeat("x", n) + "\n```\n")
+	}
+	m := newMainTestCmp(t).setTree(llm.NewTree([]llm.Message{
+		{ID: "a", Role: "assistant", Content: answer.String()},
+	}, ""))
+
+	tests := []struct {
+		name    string
+		keys    []string
+		want    int // length of the copied block, or 0
+		wantErr string
+	}{
+		// 3 cannot start a two-digit block number, so it copies at once
+		{name: "one digit", keys: []string{"y", "3"}, want: 3},
+		{name: "two digits", keys: []string{"y", "1", "2"}, want: 12},
+		{name: "enter", keys: []string{"y", "1", "enter"}, want: 1},
+		{name: "backspace", keys: []string{"y", "1", "backspace", "5"}, want: 5},
+		{name: "out of range", keys: []string{"y", "0", "enter"}, wantErr: "no code block 0"},
+		{name: "esc", keys: []string{"y", "1", "esc"}},
+		{name: "other key", keys: []string{"y", "q"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := pressMain(t, m, tt.keys...)
+			if m.yanking || m.yank != "" {
+				t.Errorf("still yanking %q", m.yank)
+			}
+			switch {
+			case tt.wantErr != "":
+				if m.err == nil || m.err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", m.err, tt.wantErr)
+				}
+			case tt.want > 0:
+				if got := clipboardText(t, cmd); got != strings.Repeat("x", tt.want)+"\n" {
+					t.Errorf("copied %q, want block %d", got, tt.want)
+				}
+			case cmd != nil:
+				t.Error("an abandoned yank copied")
+			}
+		})
+	}
+}
@@ -4,12 +4,32 @@ import (
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/darling/mana/pkg/store"
 	"github.com/darling/mana/pkg/tui/core/layout"
 )
 
+// IDs of the sidebar panes, from the top
+const (
+	paneConversations = "conversations"
+	paneModels        = "models"
+	paneSettings      = "settings"
+)
+
+// maxPaneWeight bounds how much taller than the others a sidebar pane grows.
+const maxPaneWeight = 8
+
+// sidebarWeightsMsg is sent when the relative heights of the sidebar panes
+// changed, so that the layout is saved.
+type sidebarWeightsMsg struct {
+	Weights []int
+}
+
 type SidebarCmp struct {
 	focusManager layout.FocusManager
 
+	// weights are the relative heights of the panes
+	weights []int
+
 	keys sidebarKeyMap
 
 	focused bool
@@ -18,26 +38,110 @@ type SidebarCmp struct {
 	height int
 }
 
-func NewSidebarCmp() *SidebarCmp {
-	items := []layout.Focusable{
-		NewSidebarPaneCmp("Conversations"),
-		NewSidebarPaneCmp("Models"),
-		NewSidebarPaneCmp("Settings"),
-	}
-
-	fm := layout.NewFocusManager(items, false)
+func NewSidebarCmp(st *store.Store, keys KeyMaps, s styles) *SidebarCmp {
+	settings := NewSidebarPaneCmp("Settings")
+	settings.styles = s
+	fm := layout.NewFocusManager(paneSidebar, false)
+	fm, _ = fm.Add(paneConversations, NewConversationsCmp(st).setKeys(keys.Conversations).setStyles(s))
+	fm, _ = fm.Add(paneModels, NewModelsCmp().setKeys(keys.Models).setStyles(s))
+	fm, _ = fm.Add(paneSettings, settings)
 	// Focus the first pane by default within the sidebar
 	fm, _ = fm.FocusNext()
 
+	weights := make([]int, len(fm.IDs()))
+	for i := range weights {
+		weights[i] = 1
+	}
+
 	return &SidebarCmp{
 		focusManager: fm,
+		weights:      weights,
 		focused:      false,
-		keys:         DefaultSidebarKeyMap,
+		keys:         keys.Sidebar,
+	}
+}
+
+// SetWeights sets the relative heights of the panes, from the top. Missing
+// or invalid weights count as 1.
+func (s *SidebarCmp) SetWeights(weights []int) {
+	for i := range s.weights {
+		s.weights[i] = 1
+		if i < len(weights) && weights[i] > 0 {
+			s.weights[i] = min(weights[i], maxPaneWeight)
+		}
+	}
+}
+
+// paneHeights splits height between the panes by their weights. Rows left
+// over from rounding go to the top panes.
+func paneHeights(height int, weights []int) []int {
+	sizes := make([]layout.Size, len(weights))
+	for i, w := range weights {
+		sizes[i] = layout.Flex(w)
+	}
+	return layout.Split(height, sizes...)
+}
+
+// resize distributes the height of the sidebar among its panes.
+func (s SidebarCmp) resize() (SidebarCmp, tea.Cmd) {
+	ids := s.focusManager.IDs()
+	items := make([]layout.Node, len(ids))
+	for i, id := range ids {
+		items[i] = layout.Item(id, layout.Flex(s.weights[i]))
+	}
+	rects, cmd := layout.Column(layout.Flex(1), items...).Apply(
+		layout.Rect{Width: s.width, Height: s.height},
+		func(id string, msg layout.ComponentSizeMsg) tea.Cmd {
+			var cmd tea.Cmd
+			s, cmd = s.updatePane(id, msg)
+			return cmd
+		},
+	)
+	for _, id := range ids {
+		s.focusManager = s.focusManager.SetBounds(id, rects[id])
 	}
+	return s, cmd
+}
+
+// updatePane forwards msg to the pane with id regardless of focus.
+func (s SidebarCmp) updatePane(id string, msg tea.Msg) (SidebarCmp, tea.Cmd) {
+	pane, err := s.focusManager.Get(id)
+	if err != nil {
+		return s, nil
+	}
+	updated, cmd := pane.Update(msg)
+	if focusable, ok := updated.(layout.Focusable); ok {
+		s.focusManager, _ = s.focusManager.Set(id, focusable)
+	}
+	return s, cmd
+}
+
+// weigh changes the weight of the focused pane by delta and resizes.
+func (s SidebarCmp) weigh(delta int) (SidebarCmp, tea.Cmd) {
+	for i, id := range s.focusManager.IDs() {
+		if id != s.focusManager.FocusedID() {
+			continue
+		}
+		w := max(1, min(s.weights[i]+delta, maxPaneWeight))
+		if w == s.weights[i] {
+			return s, nil
+		}
+		s.weights = append([]int(nil), s.weights...)
+		s.weights[i] = w
+		weights := append([]int(nil), s.weights...)
+		var cmd tea.Cmd
+		s, cmd = s.resize()
+		return s, tea.Batch(cmd, func() tea.Msg { return sidebarWeightsMsg{Weights: weights} })
+	}
+	return s, nil
 }
 
 func (s SidebarCmp) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	for _, pane := range s.focusManager.GetAll() {
+		cmds = append(cmds, pane.Init())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (s SidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -46,26 +150,30 @@ func (s SidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case layout.ComponentSizeMsg:
-		s.width = msg.Width / 4
+		s.width = msg.Width
 		s.height = msg.Height
+		s, cmd = s.resize()
+		cmds = append(cmds, cmd)
 
-		// Distribute height among the three panes
-		paneHeight := s.height / 3
-		remainingHeight := s.height % 3
-
-		panes := s.focusManager.GetAll()
-		for i, pane := range panes {
-			h := paneHeight
-			if i < remainingHeight {
-				h++ // Distribute remainder to top panes
+	case tea.MouseClickMsg, tea.MouseWheelMsg:
+		// The pane under the pointer gets the event, relative to its corner,
+		// and a click focuses it
+		mouse := msg.(tea.MouseMsg)
+		ids := s.focusManager.IDs()
+		top := 0
+		for i, h := range paneHeights(s.height, s.weights) {
+			y := mouse.Mouse().Y
+			if y < top || y >= top+h {
+				top += h
+				continue
 			}
-			paneSize := layout.ComponentSizeMsg{Width: s.width, Height: h}
-
-			updatedPaneModel, updateCmd := pane.Update(paneSize)
-			if updatedPane, ok := updatedPaneModel.(layout.Focusable); ok {
-				s.focusManager, _ = s.focusManager.Set(i, updatedPane)
+			if _, click := msg.(tea.MouseClickMsg); click {
+				s.focusManager, cmd, _ = s.focusManager.Focus(ids[i])
+				cmds = append(cmds, cmd)
 			}
-			cmds = append(cmds, updateCmd)
+			s, cmd = s.updatePane(ids[i], layout.TranslateMouse(mouse, 0, -top))
+			cmds = append(cmds, cmd)
+			break
 		}
 
 	case tea.KeyPressMsg:
@@ -73,46 +181,27 @@ func (s SidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !s.focused {
 			return s, nil
 		}
+		// The focused pane goes first; the keys it leaves are the sidebar's
+		var handled bool
+		if s.focusManager, cmd, handled = s.focusManager.UpdateKey(msg); handled {
+			return s, cmd
+		}
 
 		switch {
 		case key.Matches(msg, s.keys.FocusDown):
 			s.focusManager, cmd = s.focusManager.FocusNext()
 		case key.Matches(msg, s.keys.FocusUp):
 			s.focusManager, cmd = s.focusManager.FocusPrev()
+		case key.Matches(msg, s.keys.Grow):
+			s, cmd = s.weigh(1)
+		case key.Matches(msg, s.keys.Shrink):
+			s, cmd = s.weigh(-1)
 		case key.Matches(msg, s.keys.Enter):
 			// Handle selection of current pane
-			focused, err := s.focusManager.GetFocused()
-			if err == nil {
-				if pane, ok := focused.(*SidebarPaneCmp); ok {
-					updatedPane := *pane
-					updatedPane.content = "Selected: " + updatedPane.title
-					// Find the focused index and update it
-					for i, comp := range s.focusManager.GetAll() {
-						if comp.IsFocused() {
-							s.focusManager, _ = s.focusManager.Set(i, &updatedPane)
-							break
-						}
-					}
-				}
-			}
+			s = s.setPlaceholder("Selected: ")
 		case key.Matches(msg, s.keys.Create):
 			// Handle create action in current pane
-			focused, err := s.focusManager.GetFocused()
-			if err == nil {
-				if pane, ok := focused.(*SidebarPaneCmp); ok {
-					updatedPane := *pane
-					updatedPane.content = "Creating new in " + updatedPane.title
-					// Find the focused index and update it
-					for i, comp := range s.focusManager.GetAll() {
-						if comp.IsFocused() {
-							s.focusManager, _ = s.focusManager.Set(i, &updatedPane)
-							break
-						}
-					}
-				}
-			}
-		default:
-			s.focusManager, cmd = s.focusManager.UpdateFocused(msg)
+			s = s.setPlaceholder("Creating new in ")
 		}
 		cmds = append(cmds, cmd)
 
@@ -125,6 +214,20 @@ func (s SidebarCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return s, tea.Batch(cmds...)
 }
 
+// setPlaceholder shows prefix and the title in the focused pane, when it is
+// a placeholder pane.
+func (s SidebarCmp) setPlaceholder(prefix string) SidebarCmp {
+	focused, err := s.focusManager.GetFocused()
+	if err != nil {
+		return s
+	}
+	if pane, ok := focused.(SidebarPaneCmp); ok {
+		pane.content = prefix + pane.title
+		s.focusManager, _ = s.focusManager.Set(s.focusManager.FocusedID(), pane)
+	}
+	return s
+}
+
 func (s SidebarCmp) View() string {
 	panes := s.focusManager.GetAll()
 	viewedPanes := make([]string, len(panes))
@@ -155,9 +258,48 @@ func (s SidebarCmp) IsFocused() bool {
 	return s.focused
 }
 
+// HandlesKey reports whether the focused pane or the sidebar itself takes
+// msg. Other keys bubble up to the root.
+func (s SidebarCmp) HandlesKey(msg tea.KeyPressMsg) bool {
+	if s.focusManager.HandlesKey(msg) {
+		return true
+	}
+	for _, b := range []key.Binding{s.keys.FocusUp, s.keys.FocusDown, s.keys.Enter, s.keys.Create, s.keys.Grow, s.keys.Shrink} {
+		if key.Matches(msg, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// FocusedPath returns the ID of the focused pane.
+func (s SidebarCmp) FocusedPath() string {
+	return s.focusManager.FocusedID()
+}
+
+// MoveFocus moves the focus to the pane above or below.
+func (s SidebarCmp) MoveFocus(dir layout.Direction) (layout.Focusable, tea.Cmd, bool) {
+	var cmd tea.Cmd
+	var moved bool
+	s.focusManager, cmd, moved = s.focusManager.Move(dir)
+	return s, cmd, moved
+}
+
+// CapturesKeys reports whether the focused pane takes every key, like a
+// list while its filter is typed.
+func (s SidebarCmp) CapturesKeys() bool {
+	focused, err := s.focusManager.GetFocused()
+	if err != nil {
+		return false
+	}
+	capture, ok := focused.(keyCapture)
+	return ok && capture.CapturesKeys()
+}
+
 func (m SidebarCmp) Clone() layout.Focusable {
 	return SidebarCmp{
 		focusManager: m.focusManager.Clone(),
+		weights:      append([]int(nil), m.weights...),
 		keys:         m.keys,
 
 		focused: m.focused,
@@ -168,5 +310,10 @@ func (m SidebarCmp) Clone() layout.Focusable {
 }
 
 func (s SidebarCmp) Bindings() []key.Binding {
-	return []key.Binding{s.keys.FocusUp, s.keys.FocusDown, s.keys.Enter, s.keys.Create}
+	if focused, err := s.focusManager.GetFocused(); err == nil {
+		if h, ok := focused.(layout.Help); ok {
+			return h.Bindings()
+		}
+	}
+	return []key.Binding{s.keys.FocusUp, s.keys.FocusDown, s.keys.Enter, s.keys.Create, s.keys.Grow, s.keys.Shrink}
 }
@@ -6,6 +6,22 @@ import (
 	"github.com/darling/mana/pkg/tui/core/layout"
 )
 
+// paneListSize is the room a list gets in a sidebar pane of width and
+// height: inside the frame, below the title.
+func paneListSize(width, height int) (int, int) {
+	frame := defaultStyles.FocusedBox
+	frameW := frame.GetHorizontalPadding() + frame.GetHorizontalFrameSize()
+	frameH := frame.GetVerticalPadding() + frame.GetVerticalFrameSize()
+	return max(0, width-frameW), max(0, height-frameH-1)
+}
+
+// paneListOffset is where the list of a sidebar pane is drawn inside it.
+func paneListOffset() (int, int) {
+	frame := defaultStyles.FocusedBox
+	return frame.GetBorderLeftSize() + frame.GetPaddingLeft(),
+		frame.GetBorderTopSize() + frame.GetPaddingTop() + 1
+}
+
 // SidebarPaneCmp represents one of the panes within the sidebar, like "Conversations".
 type SidebarPaneCmp struct {
 	focused bool
@@ -13,12 +29,14 @@ type SidebarPaneCmp struct {
 	width   int
 	height  int
 	content string
+	styles  styles
 }
 
 func NewSidebarPaneCmp(title string) SidebarPaneCmp {
 	return SidebarPaneCmp{
 		title:   title,
 		content: "...", // Placeholder content
+		styles:  defaultStyles,
 	}
 }
 
@@ -29,6 +47,8 @@ func (p SidebarPaneCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case layout.ComponentSizeMsg:
 		p.width = msg.Width
 		p.height = msg.Height
+	case ThemeChangedMsg:
+		p.styles = newStyles(msg.Theme)
 	}
 	return p, nil
 }
@@ -37,9 +57,9 @@ func (p SidebarPaneCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (p SidebarPaneCmp) View() string {
 	var boxStyle lipgloss.Style
 	if p.focused {
-		boxStyle = FocusedBox
+		boxStyle = p.styles.FocusedBox
 	} else {
-		boxStyle = BlurredBox
+		boxStyle = p.styles.BlurredBox
 	}
 
 	// Calculate size for internal content, accounting for border and padding.
@@ -60,6 +80,9 @@ func (p SidebarPaneCmp) View() string {
 	return boxStyle.Width(p.width).Height(p.height).Render(view)
 }
 
+// HandlesKey reports false: the placeholder leaves every key to the sidebar.
+func (p SidebarPaneCmp) HandlesKey(tea.KeyPressMsg) bool { return false }
+
 func (p SidebarPaneCmp) SetFocused(focused bool) (layout.Focusable, tea.Cmd) {
 	p.focused = focused
 	return p, nil
@@ -76,5 +99,6 @@ func (p SidebarPaneCmp) Clone() layout.Focusable {
 		width:   p.width,
 		height:  p.height,
 		content: p.content,
+		styles:  p.styles,
 	}
 }
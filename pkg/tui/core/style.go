@@ -1,30 +1,91 @@
 package core
 
-import "github.com/charmbracelet/lipgloss/v2"
+import (
+	"image/color"
 
-var (
-	// Colors using ANSI terminal colors
-	subtle    = lipgloss.Color("8") // Bright Black (Dark Gray)
-	highlight = lipgloss.Color("5") // Magenta
-	special   = lipgloss.Color("2") // Green
+	"github.com/charmbracelet/lipgloss/v2"
 
-	// Styles for components
-	FocusedBox = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(highlight).
-			Padding(0, 1)
+	"github.com/darling/mana/pkg/tui/core/layout"
+	"github.com/darling/mana/pkg/tui/core/theme"
+)
 
-	BlurredBox = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(subtle).
-			Padding(0, 1)
+// styles are what the components draw with. They are made from a theme by
+// newStyles; each component holds its own copy.
+type styles struct {
+	// Colors of the theme
+	Subtle    color.Color
+	Highlight color.Color
+	Special   color.Color
 
-	FocusedItem = lipgloss.NewStyle().Foreground(special)
+	// Styles for components
+	FocusedBox  lipgloss.Style
+	BlurredBox  lipgloss.Style
+	FocusedItem lipgloss.Style
 
 	// List header style
-	ListHeader = lipgloss.NewStyle().
+	ListHeader lipgloss.Style
+
+	// Header line above the conversation transcript
+	ConversationHeader lipgloss.Style
+
+	// Inline error text
+	ErrorText lipgloss.Style
+
+	// Role header of the message under the selection cursor
+	SelectedMessage lipgloss.Style
+
+	// Divider marking where compacted turns were replaced by a summary
+	CompactionDivider lipgloss.Style
+
+	// Search matches in the transcript, and the one jumped to
+	SearchMatch        lipgloss.Style
+	CurrentSearchMatch lipgloss.Style
+
+	// Glamour style messages are rendered with
+	Markdown string
+
+	// Dialogs, lists and toasts of the layout package
+	Layout layout.Styles
+}
+
+// defaultStyles draw with the dark theme. Frames are the same in every
+// theme, so sizes are measured with them.
+var defaultStyles = newStyles(theme.Dark)
+
+// ThemeChangedMsg is delivered to every pane and layer after the terminal
+// background picked Theme. Components replace their styles and rebuild
+// what caches rendered output, like markdown renderers.
+type ThemeChangedMsg struct {
+	Theme theme.Theme
+}
+
+// newStyles returns the styles drawing with the colors of t.
+func newStyles(t theme.Theme) styles {
+	return styles{
+		Subtle:    t.Subtle,
+		Highlight: t.Accent,
+		Special:   t.Special,
+		FocusedBox: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Accent).
+			Padding(0, 1),
+		BlurredBox: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.Subtle).
+			Padding(0, 1),
+		FocusedItem: lipgloss.NewStyle().Foreground(t.Special),
+		ListHeader: lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
 			BorderBottom(true).
-			BorderForeground(subtle).
-			MarginBottom(1)
-)
+			BorderForeground(t.Subtle).
+			MarginBottom(1),
+		ConversationHeader: lipgloss.NewStyle().Foreground(t.Subtle),
+		ErrorText:          lipgloss.NewStyle().Foreground(t.Error),
+		SelectedMessage:    lipgloss.NewStyle().Foreground(t.Accent).Bold(true),
+		CompactionDivider:  lipgloss.NewStyle().Foreground(t.Subtle).Italic(true),
+		SearchMatch:        lipgloss.NewStyle().Reverse(true),
+		CurrentSearchMatch: lipgloss.NewStyle().Background(t.Accent).Foreground(t.MatchText),
+		Markdown:           t.Markdown,
+		Layout:             layout.NewStyles(t),
+	}
+}
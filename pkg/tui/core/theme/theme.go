@@ -0,0 +1,198 @@
+// Package theme holds the colors the TUI is drawn with: the built-in
+// themes and user themes defined in the config file.
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/charmbracelet/glamour/v2/styles"
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/config"
+)
+
+// Auto picks the dark or light theme after the terminal background.
+const Auto = "auto"
+
+// Names of the built-in themes.
+const (
+	DarkName         = "dark"
+	LightName        = "light"
+	HighContrastName = "high-contrast"
+)
+
+// Theme is the set of colors every component is drawn with.
+type Theme struct {
+	Name string
+	// Markdown is the glamour style messages are rendered with
+	Markdown string
+
+	Text      color.Color // text of dialogs
+	Subtle    color.Color // secondary text and unfocused borders
+	Accent    color.Color // focused borders and the selected message
+	Border    color.Color // dialog borders
+	Success   color.Color // the confirming key of a dialog and chosen options
+	Error     color.Color // errors and the cancelling key of a dialog
+	Warning   color.Color // warnings, like toasts about retries
+	Info      color.Color // other keys hinted in dialogs
+	Special   color.Color // focused sidebar items
+	MatchText color.Color // text of the current search match, drawn on Accent
+}
+
+var (
+	Dark = Theme{
+		Name:      DarkName,
+		Markdown:  styles.DarkStyle,
+		Text:      lipgloss.Color("15"),
+		Subtle:    lipgloss.Color("8"),
+		Accent:    lipgloss.Color("5"),
+		Border:    lipgloss.Color("62"),
+		Success:   lipgloss.Color("10"),
+		Error:     lipgloss.Color("9"),
+		Warning:   lipgloss.Color("11"),
+		Info:      lipgloss.Color("12"),
+		Special:   lipgloss.Color("2"),
+		MatchText: lipgloss.Color("15"),
+	}
+
+	Light = Theme{
+		Name:      LightName,
+		Markdown:  styles.LightStyle,
+		Text:      lipgloss.Color("0"),
+		Subtle:    lipgloss.Color("244"),
+		Accent:    lipgloss.Color("5"),
+		Border:    lipgloss.Color("62"),
+		Success:   lipgloss.Color("2"),
+		Error:     lipgloss.Color("1"),
+		Warning:   lipgloss.Color("3"),
+		Info:      lipgloss.Color("4"),
+		Special:   lipgloss.Color("2"),
+		MatchText: lipgloss.Color("15"),
+	}
+
+	HighContrast = Theme{
+		Name:      HighContrastName,
+		Markdown:  styles.DarkStyle,
+		Text:      lipgloss.Color("15"),
+		Subtle:    lipgloss.Color("7"),
+		Accent:    lipgloss.Color("11"),
+		Border:    lipgloss.Color("15"),
+		Success:   lipgloss.Color("10"),
+		Error:     lipgloss.Color("9"),
+		Warning:   lipgloss.Color("11"),
+		Info:      lipgloss.Color("14"),
+		Special:   lipgloss.Color("10"),
+		MatchText: lipgloss.Color("0"),
+	}
+)
+
+var builtin = map[string]Theme{
+	DarkName:         Dark,
+	LightName:        Light,
+	HighContrastName: HighContrast,
+}
+
+// Load returns the theme selected in cfg. The auto theme, and user themes
+// based on it, follow dark: whether the terminal background is dark.
+func Load(cfg config.Config, dark bool) (Theme, error) {
+	name := cfg.Theme
+	if name == "" {
+		name = Auto
+	}
+	return load(cfg, name, dark, 0)
+}
+
+func load(cfg config.Config, name string, dark bool, depth int) (Theme, error) {
+	if name == Auto {
+		if dark {
+			return Dark, nil
+		}
+		return Light, nil
+	}
+	user, ok := cfg.Themes[name]
+	if !ok {
+		if t, ok := builtin[name]; ok {
+			return t, nil
+		}
+		return Theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+	if depth > len(cfg.Themes) {
+		return Theme{}, fmt.Errorf("theme %q is based on itself", name)
+	}
+
+	base := user.Base
+	if base == "" {
+		base = Auto
+	}
+	t, err := load(cfg, base, dark, depth+1)
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to load theme %q: %w", name, err)
+	}
+	t.Name = name
+	if user.Markdown != "" {
+		if _, ok := styles.DefaultStyles[user.Markdown]; !ok {
+			return Theme{}, fmt.Errorf("theme %q: unknown markdown style %q", name, user.Markdown)
+		}
+		t.Markdown = user.Markdown
+	}
+
+	colors := t.colors()
+	var errs []error
+	for _, key := range sortedKeys(user.Colors) {
+		c, ok := colors[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("theme %q: unknown color %q", name, key))
+			continue
+		}
+		value, err := parseColor(user.Colors[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme %q: %s: %w", name, key, err))
+			continue
+		}
+		*c = value
+	}
+	return t, errors.Join(errs...)
+}
+
+// colors returns the colors of t by their name in the config file.
+func (t *Theme) colors() map[string]*color.Color {
+	return map[string]*color.Color{
+		"text":       &t.Text,
+		"subtle":     &t.Subtle,
+		"accent":     &t.Accent,
+		"border":     &t.Border,
+		"success":    &t.Success,
+		"error":      &t.Error,
+		"warning":    &t.Warning,
+		"info":       &t.Info,
+		"special":    &t.Special,
+		"match_text": &t.MatchText,
+	}
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseColor reads an ANSI color number, like "5", or a hex color.
+func parseColor(s string) (color.Color, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), nil
+	}
+	if hexColor.MatchString(s) {
+		return lipgloss.Color(s), nil
+	}
+	return nil, fmt.Errorf("invalid color %q, want 0-255 or #rrggbb", s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss/v2"
+
+	"github.com/darling/mana/pkg/config"
+)
+
+func TestLoad(t *testing.T) {
+	themes := map[string]config.ThemeConfig{
+		"ocean":    {Base: "light", Markdown: "dracula", Colors: map[string]string{"accent": "#268bd2", "border": "33"}},
+		"follow":   {Colors: map[string]string{"accent": "4"}},
+		"loop":     {Base: "loop"},
+		"bad":      {Colors: map[string]string{"accent": "blue", "sparkle": "1"}},
+		"bad-md":   {Markdown: "neon"},
+		"bad-base": {Base: "missing"},
+	}
+	tests := []struct {
+		name     string
+		theme    string
+		dark     bool
+		want     string
+		markdown string
+		wantErr  string
+	}{
+		{name: "auto dark", dark: true, want: DarkName, markdown: "dark"},
+		{name: "auto light", theme: Auto, want: LightName, markdown: "light"},
+		{name: "built-in", theme: HighContrastName, want: HighContrastName, markdown: "dark"},
+		{name: "user", theme: "ocean", dark: true, want: "ocean", markdown: "dracula"},
+		{name: "user on auto", theme: "follow", want: "follow", markdown: "light"},
+		{name: "unknown", theme: "neon", wantErr: `unknown theme "neon"`},
+		{name: "cycle", theme: "loop", wantErr: "based on itself"},
+		{name: "bad colors", theme: "bad", wantErr: `invalid color "blue"`},
+		{name: "unknown color", theme: "bad", wantErr: `unknown color "sparkle"`},
+		{name: "unknown markdown", theme: "bad-md", wantErr: `unknown markdown style "neon"`},
+		{name: "unknown base", theme: "bad-base", wantErr: `unknown theme "missing"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(config.Config{Theme: tt.theme, Themes: themes}, tt.dark)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got.Name != tt.want || got.Markdown != tt.markdown {
+				t.Errorf("Load() = %s with %s markdown, want %s with %s", got.Name, got.Markdown, tt.want, tt.markdown)
+			}
+		})
+	}
+}
+
+func TestLoad_Colors(t *testing.T) {
+	cfg := config.Config{Theme: "ocean", Themes: map[string]config.ThemeConfig{
+		"ocean": {Base: LightName, Colors: map[string]string{"accent": "#268bd2"}},
+	}}
+	got, err := Load(cfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Accent != lipgloss.Color("#268bd2") {
+		t.Errorf("Accent = %v, want the configured color", got.Accent)
+	}
+	if got.Text != Light.Text {
+		t.Errorf("Text = %v, want the light base's %v", got.Text, Light.Text)
+	}
+}
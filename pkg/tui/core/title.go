@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/darling/mana/pkg/llm"
+	"github.com/darling/mana/pkg/store"
+)
+
+// ConversationTitledMsg carries the title and tags generated for the
+// conversation with ID.
+type ConversationTitledMsg struct {
+	ID    string
+	Title string
+	Tags  []string
+	Err   error
+}
+
+// titleTokens bounds the estimated size of the exchange sent to name a
+// conversation. The start of a long message says enough about it.
+const titleTokens = 1000
+
+// titleCmd names the conversation in the background once it has a first
+// exchange and no title yet. Only that exchange is sent, cut down to
+// titleTokens. It returns nil when there is nothing to do.
+func (m MainCmp) titleCmd() tea.Cmd {
+	if m.llmManager == nil || m.store == nil || m.titles.Disabled || m.titling || m.conversation.Title != "" || m.titleFailed == m.conversation.ID {
+		return nil
+	}
+	history := firstExchange(m.messages)
+	if history == nil {
+		return nil
+	}
+
+	manager := m.llmManager
+	id := m.conversation.ID
+	var opts []llm.GenerateOption
+	if m.titles.Model != "" {
+		opts = append(opts, llm.WithModel(m.titles.Model))
+	}
+	return func() tea.Msg {
+		title, tags, err := manager.Title(context.Background(), history, opts...)
+		return ConversationTitledMsg{ID: id, Title: title, Tags: tags, Err: err}
+	}
+}
+
+// firstExchange returns the first question and its answer, each cut to
+// half of titleTokens, or nil before the first answer.
+func firstExchange(messages []llm.Message) []llm.Message {
+	var exchange []llm.Message
+	for _, msg := range messages {
+		if len(exchange) == 0 && msg.Role == "user" || len(exchange) == 1 && msg.Role == "assistant" {
+			exchange = append(exchange, llm.Message{Role: msg.Role, Content: truncateTokens(msg.Content, titleTokens/2)})
+		}
+	}
+	if len(exchange) < 2 {
+		return nil
+	}
+	return exchange
+}
+
+// truncateTokens cuts content to about tokens, as llm.EstimateTokens
+// counts them.
+func truncateTokens(content string, tokens int) string {
+	if llm.EstimateTokens([]llm.Message{{Content: content}}) <= tokens {
+		return content
+	}
+	cut := tokens * 4
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + "…"
+}
+
+// setTitle applies a generated title. Names chosen in the meantime, by
+// renaming or tagging, win over generated ones.
+func (m MainCmp) setTitle(msg ConversationTitledMsg) (MainCmp, tea.Cmd) {
+	if msg.ID != m.conversation.ID {
+		// The conversation was switched while the title was written
+		return m, updateConversationCmd(m.store, msg.ID, func(c *store.Conversation) {
+			applyTitle(c, msg)
+		})
+	}
+	m.titling = false
+	if msg.Err != nil {
+		// Not worth interrupting for, nor retrying after every exchange
+		m.titleFailed = msg.ID
+		return m, nil
+	}
+	applyTitle(&m.conversation, msg)
+	return m, m.saveCmd()
+}
+
+// applyTitle sets the generated title and tags on c where it has none.
+func applyTitle(c *store.Conversation, msg ConversationTitledMsg) {
+	if msg.Err != nil {
+		return
+	}
+	if c.Title == "" {
+		c.Title = msg.Title
+	}
+	if len(c.Tags) == 0 {
+		c.Tags = msg.Tags
+	}
+}
+
+// syncConversation takes over what was changed about the open conversation
+// from the Conversations pane.
+func (m MainCmp) syncConversation(c store.Conversation) MainCmp {
+	if c.ID != m.conversation.ID {
+		return m
+	}
+	m.conversation.Title = c.Title
+	m.conversation.Tags = c.Tags
+	m.conversation.Pinned = c.Pinned
+	m.conversation.ArchivedAt = c.ArchivedAt
+	return m
+}
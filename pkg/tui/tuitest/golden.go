@@ -0,0 +1,60 @@
+// Package tuitest drives TUI components in tests and compares what they
+// render with golden files.
+//
+// Golden files live in the testdata directory of the package under test.
+// Run its tests with -update to write them from the current output:
+//
+//	go test ./pkg/tui/core -update
+package tuitest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// AssertGolden compares got with testdata/name.golden byte for byte,
+// escape sequences included.
+func AssertGolden(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// AssertSnapshot compares a view with testdata/name.golden as plain text:
+// without escape sequences and trailing spaces, so that colors and the
+// terminal's profile do not matter.
+func AssertSnapshot(t testing.TB, name, view string) {
+	t.Helper()
+	AssertGolden(t, name, Normalize(view))
+}
+
+// Normalize strips escape sequences from view and spaces from the end of
+// its lines.
+func Normalize(view string) string {
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,198 @@
+package tuitest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// Model is a component the harness can drive and render.
+type Model interface {
+	tea.Model
+	View() string
+}
+
+// CmdTimeout is how long a command may take before its message is dropped.
+// Timers, like the one hiding a toast, never fire within it.
+var CmdTimeout = 50 * time.Millisecond
+
+// maxMsgs bounds the messages one Send may cause, to stop loops of commands
+const maxMsgs = 1000
+
+// Harness sends scripted messages to a model at a fixed size, runs the
+// commands it returns and feeds their messages back, like a program would
+// without a terminal.
+type Harness struct {
+	t     testing.TB
+	model Model
+	msgs  []tea.Msg
+}
+
+// New starts driving m with a window of width by height. Like a program,
+// it runs the command from Init before sending the size.
+func New(t testing.TB, m Model, width, height int) *Harness {
+	t.Helper()
+	h := &Harness{t: t, model: m}
+	h.msgs = run(m.Init())
+	return h.Send(append(h.Msgs(), tea.WindowSizeMsg{Width: width, Height: height})...)
+}
+
+// Send gives the model each message in turn, along with every message
+// its commands produce.
+func (h *Harness) Send(msgs ...tea.Msg) *Harness {
+	h.t.Helper()
+	queue := append([]tea.Msg(nil), msgs...)
+	for n := 0; len(queue) > 0; n++ {
+		if n == maxMsgs {
+			h.t.Fatalf("more than %d messages from one Send, the last %T", maxMsgs, queue[0])
+		}
+		msg := queue[0]
+		queue = queue[1:]
+		if _, quit := msg.(tea.QuitMsg); quit {
+			continue
+		}
+
+		model, cmd := h.model.Update(msg)
+		m, ok := model.(Model)
+		if !ok {
+			h.t.Fatalf("Update returned %T, which cannot render", model)
+		}
+		h.model = m
+		produced := run(cmd)
+		h.msgs = append(h.msgs, produced...)
+		queue = append(queue, produced...)
+	}
+	return h
+}
+
+// Type sends text as key presses, one per character.
+func (h *Harness) Type(text string) *Harness {
+	h.t.Helper()
+	for _, r := range text {
+		h.Send(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	return h
+}
+
+// Press sends key presses named like key bindings, such as "tab" or
+// "ctrl+p".
+func (h *Harness) Press(keys ...string) *Harness {
+	h.t.Helper()
+	for _, k := range keys {
+		msg, ok := Key(k)
+		if !ok {
+			h.t.Fatalf("unknown key %q", k)
+		}
+		h.Send(msg)
+	}
+	return h
+}
+
+// Model returns the model as it is now.
+func (h *Harness) Model() Model {
+	return h.model
+}
+
+// Msgs returns every message the model's commands produced so far.
+func (h *Harness) Msgs() []tea.Msg {
+	return append([]tea.Msg(nil), h.msgs...)
+}
+
+// View renders the model.
+func (h *Harness) View() string {
+	return h.model.View()
+}
+
+// Snapshot compares the plain text of the view with testdata/name.golden.
+func (h *Harness) Snapshot(name string) *Harness {
+	h.t.Helper()
+	AssertSnapshot(h.t, name, h.View())
+	return h
+}
+
+var cmdType = reflect.TypeOf(tea.Cmd(nil))
+
+// run runs cmd and the commands it batches or sequences, in order, and
+// returns their messages. Commands running past CmdTimeout are dropped.
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(CmdTimeout):
+		return nil
+	}
+	if msg == nil {
+		return nil
+	}
+
+	// Batches and sequences are slices of commands; sequences have an
+	// unexported type
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || v.Type().Elem() != cmdType {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for i := range v.Len() {
+		msgs = append(msgs, run(v.Index(i).Interface().(tea.Cmd))...)
+	}
+	return msgs
+}
+
+// namedKeys maps key names used in bindings to their key codes.
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"tab":       tea.KeyTab,
+	"esc":       tea.KeyEscape,
+	"space":     tea.KeySpace,
+	"backspace": tea.KeyBackspace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"delete":    tea.KeyDelete,
+}
+
+// Key builds the key press named like a binding key, such as "ctrl+c".
+func Key(s string) (tea.KeyPressMsg, bool) {
+	var k tea.Key
+	parts := strings.Split(s, "+")
+	for _, mod := range parts[:len(parts)-1] {
+		switch mod {
+		case "ctrl":
+			k.Mod |= tea.ModCtrl
+		case "alt":
+			k.Mod |= tea.ModAlt
+		case "shift":
+			k.Mod |= tea.ModShift
+		default:
+			return tea.KeyPressMsg{}, false
+		}
+	}
+
+	name := parts[len(parts)-1]
+	if code, ok := namedKeys[name]; ok {
+		k.Code = code
+	} else if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError {
+		k.Code = r
+		if k.Mod == 0 {
+			k.Text = name
+		}
+	} else {
+		return tea.KeyPressMsg{}, false
+	}
+	return tea.KeyPressMsg(k), true
+}
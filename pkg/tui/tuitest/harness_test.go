@@ -0,0 +1,78 @@
+package tuitest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+type addMsg int
+
+// counter adds up addMsgs; a key press asks for the key's digit to be
+// added, twice, and "s" for a message that comes too late
+type counter struct {
+	total int
+	keys  []string
+}
+
+func (c counter) Init() tea.Cmd { return func() tea.Msg { return addMsg(100) } }
+
+func (c counter) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case addMsg:
+		c.total += int(msg)
+	case tea.KeyPressMsg:
+		c.keys = append(c.keys, msg.String())
+		if msg.String() == "s" {
+			return c, tea.Tick(time.Hour, func(time.Time) tea.Msg { return addMsg(1000) })
+		}
+		n := addMsg(msg.Code - '0')
+		add := func() tea.Msg { return n }
+		return c, tea.Sequence(add, tea.Batch(add, nil))
+	}
+	return c, nil
+}
+
+func (c counter) View() string {
+	return lipgloss.NewStyle().Bold(true).Render(fmt.Sprint(c.total)) + "   \n" + strings.Join(c.keys, " ")
+}
+
+func TestHarness(t *testing.T) {
+	h := New(t, counter{}, 10, 2).Type("12").Press("s")
+
+	c := h.Model().(counter)
+	if c.total != 106 {
+		t.Errorf("total = %d, want the init, sequenced and batched messages to add up to 106", c.total)
+	}
+	if got, want := Normalize(h.View()), "106\n1 2 s"; got != want {
+		t.Errorf("Normalize(View()) = %q, want %q", got, want)
+	}
+	if got := len(h.Msgs()); got != 5 {
+		t.Errorf("got %d messages from commands, want 5", got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"ctrl+p", "ctrl+p", true},
+		{"alt+enter", "alt+enter", true},
+		{"x", "x", true},
+		{"tab", "tab", true},
+		{"hyper+x", "", false},
+		{"nope", "", false},
+	}
+	for _, tt := range tests {
+		msg, ok := Key(tt.name)
+		if ok != tt.ok || ok && msg.String() != tt.want {
+			t.Errorf("Key(%q) = %q, %v; want %q, %v", tt.name, msg.String(), ok, tt.want, tt.ok)
+		}
+	}
+}